@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+)
+
+type EmailVerificationController struct {
+	EmailVerificationUsecase domain.EmailVerificationUsecase
+}
+
+// VerifyEmail godoc
+//
+//	@Summary		Verify an email address
+//	@Description	Confirm the email address of a user with the signed token of a verification link, a user pending verification is activated
+//	@Produce		json
+//	@Param			token	query		string					true	"Verification token"
+//	@Success		200		{object}	domain.Response			"Email Verified Successfully"
+//	@Failure		400		{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		500		{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user/email/verify [get]
+//	@Tags			user management service
+func (c *EmailVerificationController) VerifyEmail(ctx *gin.Context) {
+	var req domain.VerifyEmailRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		log.Println("[EmailVerificationController][VerifyEmail] Error in ShouldBindQuery: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	if err := c.EmailVerificationUsecase.VerifyEmail(ctx.Request.Context(), &req); err != nil {
+		log.Println("[EmailVerificationController][VerifyEmail] Error in VerifyEmail: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Email Verified Successfully", Success: true})
+}
+
+// ResendVerificationEmail godoc
+//
+//	@Summary		Resend a verification email
+//	@Description	Send a new verification link to an unconfirmed email address. The response is the same whether or not a user has the address, and no link is sent within the resend interval of the last one.
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		domain.ResendVerificationEmailRequest	true	"Email"
+//	@Success		200		{object}	domain.Response							"Verification Email Requested"
+//	@Failure		400		{object}	domain.ErrorResponse					"Invalid Request"
+//	@Failure		401		{object}	domain.ErrorResponse					"Unauthorized"
+//	@Failure		500		{object}	domain.ErrorResponse					"Internal Server Error"
+//	@Router			/user/email/verify/resend [post]
+//	@Tags			user management service
+func (c *EmailVerificationController) ResendVerificationEmail(ctx *gin.Context) {
+	var req domain.ResendVerificationEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[EmailVerificationController][ResendVerificationEmail] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	if err := c.EmailVerificationUsecase.ResendVerificationEmail(ctx.Request.Context(), &req); err != nil {
+		log.Println("[EmailVerificationController][ResendVerificationEmail] Error in ResendVerificationEmail: ", err)
+		if cerr.GetErrorCode(err) == cerr.InvalidRequestErrorCode {
+			ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, domain.Response{Message: "Internal Server Error", Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "If the email address awaits verification, a verification email has been sent", Success: true})
+}
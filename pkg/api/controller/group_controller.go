@@ -0,0 +1,382 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+)
+
+type GroupController struct {
+	GroupUsecase domain.GroupUsecase
+}
+
+// GetGroups godoc
+//
+//	@Summary		List groups
+//	@Description	List every group, requires the groups:read permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Success		200				{object}	domain.GetGroupsResp	"Groups Fetched Successfully"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user/groups [get]
+//	@Tags			groups
+func (c *GroupController) GetGroups(ctx *gin.Context) {
+	// Call the usecase
+	res, err := c.GroupUsecase.GetGroups(ctx.Request.Context())
+	if err != nil {
+		log.Println("[GroupController][GetGroups] Error in GetGroups: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Groups Fetched Successfully", Success: true, Data: *res})
+}
+
+// CreateGroup godoc
+//
+//	@Summary		Create a group
+//	@Description	Create a group, requires the groups:write permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string						true	"Bearer token"
+//	@Param			request			body		domain.CreateGroupRequest	true	"Group"
+//	@Success		200				{object}	domain.GroupResp			"Group Created Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse		"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse		"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse		"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse		"Internal Server Error"
+//	@Router			/user/groups [post]
+//	@Tags			groups
+func (c *GroupController) CreateGroup(ctx *gin.Context) {
+	var req domain.CreateGroupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[GroupController][CreateGroup] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	res, err := c.GroupUsecase.CreateGroup(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[GroupController][CreateGroup] Error in CreateGroup: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Group Created Successfully", Success: true, Data: *res})
+}
+
+// GetGroup godoc
+//
+//	@Summary		Get a group
+//	@Description	Get a group with its direct members and subgroups, requires the groups:read permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Param			group			path		string					true	"Group Name"
+//	@Success		200				{object}	domain.GetGroupResp		"Group Fetched Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user/groups/{group} [get]
+//	@Tags			groups
+func (c *GroupController) GetGroup(ctx *gin.Context) {
+	var req domain.GroupRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[GroupController][GetGroup] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	res, err := c.GroupUsecase.GetGroup(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[GroupController][GetGroup] Error in GetGroup: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Group Fetched Successfully", Success: true, Data: *res})
+}
+
+// UpdateGroup godoc
+//
+//	@Summary		Update a group
+//	@Description	Update the description of a group, requires the groups:write permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string						true	"Bearer token"
+//	@Param			group			path		string						true	"Group Name"
+//	@Param			request			body		domain.UpdateGroupRequest	true	"Group"
+//	@Success		200				{object}	domain.GroupResp			"Group Updated Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse		"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse		"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse		"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse		"Internal Server Error"
+//	@Router			/user/groups/{group} [patch]
+//	@Tags			groups
+func (c *GroupController) UpdateGroup(ctx *gin.Context) {
+	var req domain.UpdateGroupRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[GroupController][UpdateGroup] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[GroupController][UpdateGroup] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	res, err := c.GroupUsecase.UpdateGroup(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[GroupController][UpdateGroup] Error in UpdateGroup: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Group Updated Successfully", Success: true, Data: *res})
+}
+
+// DeleteGroup godoc
+//
+//	@Summary		Delete a group
+//	@Description	Delete a group with its memberships, requires the groups:write permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Param			group			path		string					true	"Group Name"
+//	@Success		200				{object}	domain.Response			"Group Deleted Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user/groups/{group} [delete]
+//	@Tags			groups
+func (c *GroupController) DeleteGroup(ctx *gin.Context) {
+	var req domain.GroupRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[GroupController][DeleteGroup] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	if err := c.GroupUsecase.DeleteGroup(ctx.Request.Context(), &req); err != nil {
+		log.Println("[GroupController][DeleteGroup] Error in DeleteGroup: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Group Deleted Successfully", Success: true})
+}
+
+// AddMember godoc
+//
+//	@Summary		Add a group member
+//	@Description	Add a user to a group, requires the groups:write permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Param			group			path		string					true	"Group Name"
+//	@Param			username		path		string					true	"User Name"
+//	@Success		200				{object}	domain.Response			"Member Added Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user/groups/{group}/members/{username} [post]
+//	@Tags			groups
+func (c *GroupController) AddMember(ctx *gin.Context) {
+	var req domain.GroupMemberRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[GroupController][AddMember] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	if err := c.GroupUsecase.AddMember(ctx.Request.Context(), &req); err != nil {
+		log.Println("[GroupController][AddMember] Error in AddMember: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Member Added Successfully", Success: true})
+}
+
+// RemoveMember godoc
+//
+//	@Summary		Remove a group member
+//	@Description	Remove a user from a group, requires the groups:write permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Param			group			path		string					true	"Group Name"
+//	@Param			username		path		string					true	"User Name"
+//	@Success		200				{object}	domain.Response			"Member Removed Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user/groups/{group}/members/{username} [delete]
+//	@Tags			groups
+func (c *GroupController) RemoveMember(ctx *gin.Context) {
+	var req domain.GroupMemberRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[GroupController][RemoveMember] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	if err := c.GroupUsecase.RemoveMember(ctx.Request.Context(), &req); err != nil {
+		log.Println("[GroupController][RemoveMember] Error in RemoveMember: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Member Removed Successfully", Success: true})
+}
+
+// AddSubgroup godoc
+//
+//	@Summary		Nest a group
+//	@Description	Nest a group inside another one, nesting that would create a cycle is rejected. Requires the groups:write permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Param			group			path		string					true	"Group Name"
+//	@Param			subgroup		path		string					true	"Subgroup Name"
+//	@Success		200				{object}	domain.Response			"Subgroup Added Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user/groups/{group}/subgroups/{subgroup} [post]
+//	@Tags			groups
+func (c *GroupController) AddSubgroup(ctx *gin.Context) {
+	var req domain.SubgroupRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[GroupController][AddSubgroup] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	if err := c.GroupUsecase.AddSubgroup(ctx.Request.Context(), &req); err != nil {
+		log.Println("[GroupController][AddSubgroup] Error in AddSubgroup: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Subgroup Added Successfully", Success: true})
+}
+
+// RemoveSubgroup godoc
+//
+//	@Summary		Unnest a group
+//	@Description	Remove a group from another one, requires the groups:write permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Param			group			path		string					true	"Group Name"
+//	@Param			subgroup		path		string					true	"Subgroup Name"
+//	@Success		200				{object}	domain.Response			"Subgroup Removed Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user/groups/{group}/subgroups/{subgroup} [delete]
+//	@Tags			groups
+func (c *GroupController) RemoveSubgroup(ctx *gin.Context) {
+	var req domain.SubgroupRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[GroupController][RemoveSubgroup] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	if err := c.GroupUsecase.RemoveSubgroup(ctx.Request.Context(), &req); err != nil {
+		log.Println("[GroupController][RemoveSubgroup] Error in RemoveSubgroup: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Subgroup Removed Successfully", Success: true})
+}
+
+// GetUserGroups godoc
+//
+//	@Summary		Get the groups of a user
+//	@Description	Get the groups a user is a member of, directly or through nested groups. Requires the groups:read permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Param			username		path		string					true	"User Name"
+//	@Success		200				{object}	domain.GetGroupsResp	"Groups Fetched Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user/{username}/groups [get]
+//	@Tags			groups
+func (c *GroupController) GetUserGroups(ctx *gin.Context) {
+	var req domain.EffectiveGroupsRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[GroupController][GetUserGroups] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	res, err := c.GroupUsecase.GetEffectiveGroups(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[GroupController][GetUserGroups] Error in GetEffectiveGroups: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Groups Fetched Successfully", Success: true, Data: *res})
+}
+
+// GetMyGroups godoc
+//
+//	@Summary		Get my groups
+//	@Description	Get the groups the caller is a member of, directly or through nested groups
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Success		200				{object}	domain.GetGroupsResp	"Groups Fetched Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user/me/groups [get]
+//	@Tags			groups
+func (c *GroupController) GetMyGroups(ctx *gin.Context) {
+	principal, ok := userPrincipal(ctx)
+	if !ok {
+		return
+	}
+	req := domain.EffectiveGroupsRequest{UserID: principal.UserID}
+
+	// Call the usecase
+	res, err := c.GroupUsecase.GetEffectiveGroups(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[GroupController][GetMyGroups] Error in GetEffectiveGroups: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Groups Fetched Successfully", Success: true, Data: *res})
+}
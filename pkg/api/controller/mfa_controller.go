@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+)
+
+type MFAController struct {
+	MFAUsecase domain.MFAUsecase
+}
+
+// EnrollTOTP godoc
+//
+//	@Summary		Start enabling MFA
+//	@Description	Create a TOTP secret for the user identified by the bearer token. MFA is only enabled once a code of the authenticator app is confirmed. Personal access tokens cannot manage MFA, tokens of OAuth clients need the profile:write scope.
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Success		200				{object}	domain.EnrollTOTPResp	"TOTP Enrolment Started"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user/me/mfa/totp [post]
+//	@Tags			user management service
+func (c *MFAController) EnrollTOTP(ctx *gin.Context) {
+	principal, ok := userPrincipal(ctx)
+	if !ok {
+		return
+	}
+
+	// Call the usecase
+	res, err := c.MFAUsecase.EnrollTOTP(ctx.Request.Context(), &domain.EnrollTOTPRequest{UserID: principal.UserID})
+	if err != nil {
+		log.Println("[MFAController][EnrollTOTP] Error in EnrollTOTP: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "TOTP Enrolment Started", Success: true, Data: *res})
+}
+
+// ConfirmTOTP godoc
+//
+//	@Summary		Enable MFA
+//	@Description	Confirm the TOTP secret with a code of the authenticator app. The recovery codes in the response are only shown once. Personal access tokens cannot manage MFA, tokens of OAuth clients need the profile:write scope.
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string						true	"Bearer token"
+//	@Param			request			body		domain.ConfirmTOTPRequest	true	"Code"
+//	@Success		200				{object}	domain.ConfirmTOTPResp		"MFA Enabled Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse		"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse		"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse		"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse		"Internal Server Error"
+//	@Router			/user/me/mfa/totp/confirm [post]
+//	@Tags			user management service
+func (c *MFAController) ConfirmTOTP(ctx *gin.Context) {
+	var req domain.ConfirmTOTPRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[MFAController][ConfirmTOTP] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+	principal, ok := userPrincipal(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = principal.UserID
+
+	// Call the usecase
+	res, err := c.MFAUsecase.ConfirmTOTP(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[MFAController][ConfirmTOTP] Error in ConfirmTOTP: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "MFA Enabled Successfully", Success: true, Data: *res})
+}
+
+// DisableTOTP godoc
+//
+//	@Summary		Disable MFA
+//	@Description	Remove the TOTP secret and the recovery codes of the user identified by the bearer token, a code of the authenticator app or a recovery code is required. Wrong codes count towards the login lockout. Personal access tokens cannot manage MFA, tokens of OAuth clients need the profile:write scope.
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string						true	"Bearer token"
+//	@Param			request			body		domain.DisableTOTPRequest	true	"Code"
+//	@Success		200				{object}	domain.Response				"MFA Disabled Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse		"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse		"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse		"Forbidden"
+//	@Failure		429				{object}	domain.ErrorResponse		"Too Many Failed Login Attempts"
+//	@Failure		500				{object}	domain.ErrorResponse		"Internal Server Error"
+//	@Router			/user/me/mfa/totp [delete]
+//	@Tags			user management service
+func (c *MFAController) DisableTOTP(ctx *gin.Context) {
+	var req domain.DisableTOTPRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[MFAController][DisableTOTP] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+	principal, ok := userPrincipal(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = principal.UserID
+	req.ClientIP = ctx.ClientIP()
+
+	// Call the usecase
+	if err := c.MFAUsecase.DisableTOTP(ctx.Request.Context(), &req); err != nil {
+		log.Println("[MFAController][DisableTOTP] Error in DisableTOTP: ", err)
+		if cerr.GetErrorCode(err) == cerr.TooManyRequestsErrorCode {
+			tooManyRequests(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "MFA Disabled Successfully", Success: true})
+}
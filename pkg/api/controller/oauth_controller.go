@@ -0,0 +1,300 @@
+package controller
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+)
+
+type OAuthController struct {
+	OAuthUsecase domain.OAuthUsecase
+}
+
+// authorizePage is the login form of the authorization endpoint, the authorization request travels in hidden fields
+var authorizePage = template.Must(template.New("authorize").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Sign in</title></head>
+<body>
+{{if .Message}}<p>{{.Message}}</p>{{else}}
+<h1>Sign in to {{.Prompt.ClientName}}</h1>
+{{if .Prompt.Error}}<p>{{.Prompt.Error}}</p>{{end}}
+<form method="post">
+{{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{$value}}">
+{{end}}<p><label>Username <input name="username" autocomplete="username" required></label></p>
+<p><label>Password <input name="password" type="password" autocomplete="current-password" required></label></p>
+<p><label>Authentication code, if MFA is enabled <input name="mfa_code" inputmode="numeric" autocomplete="one-time-code"></label></p>
+{{if .Prompt.FirstParty}}<p><button type="submit">Sign in</button></p>
+{{else}}<p>{{.Prompt.ClientName}} is requesting access to:{{range .Prompt.Scopes}} {{.}}{{end}}</p>
+<p><button type="submit" name="consent" value="approve">Allow</button> <button type="submit" name="consent" value="deny" formnovalidate>Deny</button></p>
+{{end}}</form>{{end}}
+</body>
+</html>
+`))
+
+// Authorize godoc
+//
+//	@Summary		OAuth 2.0 authorization endpoint
+//	@Description	Starts the authorization code flow, PKCE with S256 is required. Shows the login form, or redirects back to the client when the request is rejected.
+//	@Produce		html
+//	@Param			response_type			query	string	true	"Must be code"
+//	@Param			client_id				query	string	true	"Client ID"
+//	@Param			redirect_uri			query	string	true	"Registered redirect URI"
+//	@Param			scope					query	string	false	"Space separated scopes"
+//	@Param			state					query	string	false	"Opaque value returned to the client"
+//	@Param			nonce					query	string	false	"Nonce echoed in the ID token"
+//	@Param			code_challenge			query	string	true	"PKCE code challenge"
+//	@Param			code_challenge_method	query	string	true	"Must be S256"
+//	@Success		200						"Login form"
+//	@Success		302						"Redirect to the client with an error"
+//	@Failure		400						"Invalid client or redirect URI"
+//	@Router			/oauth/authorize [get]
+//	@Tags			oauth
+func (c *OAuthController) Authorize(ctx *gin.Context) {
+	var req domain.AuthorizeRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		log.Println("[OAuthController][Authorize] Error in ShouldBindQuery: ", err)
+		renderAuthorizePage(ctx, http.StatusBadRequest, "Invalid Request", nil, nil)
+		return
+	}
+
+	// Call the usecase
+	res, err := c.OAuthUsecase.ValidateAuthorizeRequest(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[OAuthController][Authorize] Error in ValidateAuthorizeRequest: ", err)
+		renderAuthorizePage(ctx, http.StatusBadRequest, cerr.GetErrorMessage(err), nil, nil)
+		return
+	}
+
+	respondAuthorize(ctx, &req, res)
+}
+
+// AuthorizeLogin godoc
+//
+//	@Summary		OAuth 2.0 authorization endpoint login
+//	@Description	Authenticates the user with the login form and redirects back to the client with an authorization code
+//	@Accept			x-www-form-urlencoded
+//	@Produce		html
+//	@Param			username	formData	string	true	"Username"
+//	@Param			password	formData	string	true	"Password"
+//	@Param			mfa_code	formData	string	false	"Code of the authenticator app or a recovery code, required for users with MFA enabled"
+//	@Param			consent		formData	string	false	"approve or deny, required for clients that are not first party"
+//	@Success		200			"Login form with an error"
+//	@Success		302			"Redirect to the client"
+//	@Failure		400			"Invalid client or redirect URI"
+//	@Router			/oauth/authorize [post]
+//	@Tags			oauth
+func (c *OAuthController) AuthorizeLogin(ctx *gin.Context) {
+	var req domain.AuthorizeLoginRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		log.Println("[OAuthController][AuthorizeLogin] Error in ShouldBind: ", err)
+		renderAuthorizePage(ctx, http.StatusBadRequest, "Invalid Request", nil, nil)
+		return
+	}
+	req.UserAgent = ctx.Request.UserAgent()
+	req.ClientIP = ctx.ClientIP()
+
+	// Call the usecase
+	res, err := c.OAuthUsecase.Authorize(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[OAuthController][AuthorizeLogin] Error in Authorize: ", err)
+		renderAuthorizePage(ctx, http.StatusBadRequest, cerr.GetErrorMessage(err), nil, nil)
+		return
+	}
+
+	respondAuthorize(ctx, &req.AuthorizeRequest, res)
+}
+
+// Token godoc
+//
+//	@Summary		OAuth 2.0 token endpoint
+//	@Description	Exchanges an authorization code or a refresh token for tokens, or issues a token to a machine client with the client credentials grant. Confidential clients authenticate with HTTP Basic or client_secret.
+//	@Accept			x-www-form-urlencoded
+//	@Produce		json
+//	@Param			grant_type		formData	string					true	"authorization_code, refresh_token or client_credentials"
+//	@Param			code			formData	string					false	"Authorization code"
+//	@Param			redirect_uri	formData	string					false	"Redirect URI of the authorization request"
+//	@Param			code_verifier	formData	string					false	"PKCE code verifier"
+//	@Param			refresh_token	formData	string					false	"Refresh token"
+//	@Param			scope			formData	string					false	"Space separated scopes for the client credentials grant, defaults to every allowed scope"
+//	@Param			client_id		formData	string					false	"Client ID"
+//	@Param			client_secret	formData	string					false	"Client secret"
+//	@Success		200				{object}	domain.TokenResponse	"Tokens"
+//	@Failure		400				{object}	domain.OAuthError		"Invalid Request"
+//	@Failure		401				{object}	domain.OAuthError		"Invalid Client"
+//	@Failure		500				{object}	domain.OAuthError		"Internal Server Error"
+//	@Router			/oauth/token [post]
+//	@Tags			oauth
+func (c *OAuthController) Token(ctx *gin.Context) {
+	// Token responses must never be cached
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Pragma", "no-cache")
+
+	var req domain.TokenRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		log.Println("[OAuthController][Token] Error in ShouldBind: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.NewOAuthError(domain.OAuthErrorInvalidRequest, "grant_type is required"))
+		return
+	}
+
+	basicAuth := clientBasicAuth(ctx, &req.ClientID, &req.ClientSecret)
+
+	// Call the usecase
+	res, err := c.OAuthUsecase.Token(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[OAuthController][Token] Error in Token: ", err)
+		respondOAuthError(ctx, err, basicAuth)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Introspect godoc
+//
+//	@Summary		OAuth 2.0 token introspection
+//	@Description	Describes an access or refresh token as specified by RFC 7662, revoked and expired tokens are reported as inactive. The caller authenticates as a confidential client with HTTP Basic or client_secret.
+//	@Accept			x-www-form-urlencoded
+//	@Produce		json
+//	@Param			token			formData	string						true	"Token to introspect"
+//	@Param			token_type_hint	formData	string						false	"access_token or refresh_token"
+//	@Param			client_id		formData	string						false	"Client ID"
+//	@Param			client_secret	formData	string						false	"Client secret"
+//	@Success		200				{object}	domain.IntrospectResponse	"Token Introspection"
+//	@Failure		400				{object}	domain.OAuthError			"Invalid Request"
+//	@Failure		401				{object}	domain.OAuthError			"Invalid Client"
+//	@Failure		500				{object}	domain.OAuthError			"Internal Server Error"
+//	@Router			/oauth/introspect [post]
+//	@Tags			oauth
+func (c *OAuthController) Introspect(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Pragma", "no-cache")
+
+	var req domain.IntrospectRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		log.Println("[OAuthController][Introspect] Error in ShouldBind: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.NewOAuthError(domain.OAuthErrorInvalidRequest, "token is required"))
+		return
+	}
+
+	basicAuth := clientBasicAuth(ctx, &req.ClientID, &req.ClientSecret)
+
+	// Call the usecase
+	res, err := c.OAuthUsecase.Introspect(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[OAuthController][Introspect] Error in Introspect: ", err)
+		respondOAuthError(ctx, err, basicAuth)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// RegisterClient godoc
+//
+//	@Summary		Register an OAuth client
+//	@Description	Register an application allowed to use the OAuth endpoints, the client secret is only returned once. Scopes must be OpenID Connect scopes or permissions of the service. Requires the clients:write permission, which only users of the default tenant hold.
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string							true	"Bearer token"
+//	@Param			request			body		domain.RegisterClientRequest	true	"Client Details"
+//	@Success		200				{object}	domain.RegisterClientResp		"Client Registered Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse			"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse			"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse			"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse			"Internal Server Error"
+//	@Router			/admin/oauth/clients [post]
+//	@Tags			admin
+func (c *OAuthController) RegisterClient(ctx *gin.Context) {
+	var req domain.RegisterClientRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[OAuthController][RegisterClient] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	res, err := c.OAuthUsecase.RegisterClient(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[OAuthController][RegisterClient] Error in RegisterClient: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Client Registered Successfully", Success: true, Data: *res})
+}
+
+// clientBasicAuth reads client credentials sent with HTTP Basic, they are form encoded as described by
+// RFC 6749 section 2.3.1 and take precedence over the form parameters
+func clientBasicAuth(ctx *gin.Context, clientID *string, clientSecret *string) bool {
+	username, password, ok := ctx.Request.BasicAuth()
+	if !ok {
+		return false
+	}
+
+	*clientID, _ = url.QueryUnescape(username)
+	*clientSecret, _ = url.QueryUnescape(password)
+	return true
+}
+
+// respondOAuthError writes the OAuth error response, failed client authentication is answered with 401
+func respondOAuthError(ctx *gin.Context, err error, basicAuth bool) {
+	var oauthErr *domain.OAuthError
+	if !errors.As(err, &oauthErr) {
+		ctx.JSON(http.StatusInternalServerError, domain.NewOAuthError(domain.OAuthErrorServerError, "Internal Server Error"))
+		return
+	}
+
+	if oauthErr.Code == domain.OAuthErrorInvalidClient {
+		if basicAuth {
+			ctx.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		ctx.JSON(http.StatusUnauthorized, oauthErr)
+		return
+	}
+
+	ctx.JSON(http.StatusBadRequest, oauthErr)
+}
+
+// respondAuthorize redirects back to the client or shows the login form
+func respondAuthorize(ctx *gin.Context, req *domain.AuthorizeRequest, res *domain.AuthorizeResponse) {
+	if res.RedirectURL != "" {
+		ctx.Redirect(http.StatusFound, res.RedirectURL)
+		return
+	}
+
+	renderAuthorizePage(ctx, http.StatusOK, "", res.Prompt, map[string]string{
+		"response_type":         req.ResponseType,
+		"client_id":             req.ClientID,
+		"redirect_uri":          req.RedirectURI,
+		"scope":                 req.Scope,
+		"state":                 req.State,
+		"nonce":                 req.Nonce,
+		"code_challenge":        req.CodeChallenge,
+		"code_challenge_method": req.CodeChallengeMethod,
+	})
+}
+
+// renderAuthorizePage renders the login form, or only the message when the request cannot be authorized
+func renderAuthorizePage(ctx *gin.Context, status int, message string, prompt *domain.AuthorizePrompt, params map[string]string) {
+	// The login form must not be framed by other sites
+	ctx.Header("X-Frame-Options", "DENY")
+	ctx.Header("Content-Security-Policy", "frame-ancestors 'none'")
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Status(status)
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+
+	err := authorizePage.Execute(ctx.Writer, map[string]interface{}{
+		"Message": message,
+		"Prompt":  prompt,
+		"Params":  params,
+	})
+	if err != nil {
+		log.Println("[OAuthController][renderAuthorizePage] Error in Execute: ", err)
+	}
+}
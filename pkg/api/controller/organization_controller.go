@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+)
+
+type OrganizationController struct {
+	OrganizationUsecase domain.OrganizationUsecase
+}
+
+// GetOrganizations godoc
+//
+//	@Summary		List organizations
+//	@Description	List the organizations users are registered in, requires the organizations:read permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string						true	"Bearer token"
+//	@Success		200				{object}	domain.GetOrganizationsResp	"Organizations Fetched Successfully"
+//	@Failure		401				{object}	domain.ErrorResponse		"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse		"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse		"Internal Server Error"
+//	@Router			/admin/organizations [get]
+//	@Tags			admin
+func (c *OrganizationController) GetOrganizations(ctx *gin.Context) {
+	// Call the usecase
+	res, err := c.OrganizationUsecase.GetOrganizations(ctx.Request.Context())
+	if err != nil {
+		log.Println("[OrganizationController][GetOrganizations] Error in GetOrganizations: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Organizations Fetched Successfully", Success: true, Data: *res})
+}
+
+// CreateOrganization godoc
+//
+//	@Summary		Create an organization
+//	@Description	Create an organization, its tenant id is used in the X-Tenant-ID header, as subdomain or as /t/{tenant} path prefix. Requires the organizations:write permission.
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string								true	"Bearer token"
+//	@Param			request			body		domain.CreateOrganizationRequest	true	"Organization"
+//	@Success		200				{object}	domain.OrganizationResp				"Organization Created Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse				"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse				"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse				"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse				"Internal Server Error"
+//	@Router			/admin/organizations [post]
+//	@Tags			admin
+func (c *OrganizationController) CreateOrganization(ctx *gin.Context) {
+	var req domain.CreateOrganizationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[OrganizationController][CreateOrganization] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	res, err := c.OrganizationUsecase.CreateOrganization(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[OrganizationController][CreateOrganization] Error in CreateOrganization: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Organization Created Successfully", Success: true, Data: *res})
+}
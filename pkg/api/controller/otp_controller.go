@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+)
+
+type OTPController struct {
+	OTPUsecase domain.OTPUsecase
+}
+
+// StartOTPLogin godoc
+//
+//	@Summary		Start a passwordless login
+//	@Description	Send a one time login code by email or SMS to the user with the email address or phone number. The response is the same whether or not the user exists.
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		domain.StartOTPLoginRequest	true	"Email or phone number"
+//	@Success		200		{object}	domain.Response				"Login Code Requested"
+//	@Failure		400		{object}	domain.ErrorResponse		"Invalid Request"
+//	@Failure		401		{object}	domain.ErrorResponse		"Unauthorized"
+//	@Failure		500		{object}	domain.ErrorResponse		"Internal Server Error"
+//	@Router			/user/otp/start [post]
+//	@Tags			user management service
+func (c *OTPController) StartOTPLogin(ctx *gin.Context) {
+	var req domain.StartOTPLoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[OTPController][StartOTPLogin] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	if err := c.OTPUsecase.StartOTPLogin(ctx.Request.Context(), &req); err != nil {
+		log.Println("[OTPController][StartOTPLogin] Error in StartOTPLogin: ", err)
+		if cerr.GetErrorCode(err) == cerr.InvalidRequestErrorCode {
+			ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, domain.Response{Message: "Internal Server Error", Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "If the user exists, a login code has been sent", Success: true})
+}
+
+// VerifyOTPLogin godoc
+//
+//	@Summary		Complete a passwordless login
+//	@Description	Exchange the login code sent by /user/otp/start for tokens. Wrong codes count towards the login lockout, users with MFA enabled also send a code of their authenticator app.
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		domain.VerifyOTPLoginRequest	true	"Email or phone number and code"
+//	@Success		200		{object}	domain.LoginSuccessResp			"User Logged In Successfully"
+//	@Failure		400		{object}	domain.ErrorResponse			"Invalid Request"
+//	@Failure		401		{object}	domain.ErrorResponse			"Unauthorized"
+//	@Failure		403		{object}	domain.ErrorResponse			"Email Address Not Verified"
+//	@Failure		429		{object}	domain.ErrorResponse			"Too Many Failed Login Attempts"
+//	@Failure		500		{object}	domain.ErrorResponse			"Internal Server Error"
+//	@Router			/user/otp/verify [post]
+//	@Tags			user management service
+func (c *OTPController) VerifyOTPLogin(ctx *gin.Context) {
+	var req domain.VerifyOTPLoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[OTPController][VerifyOTPLogin] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+	req.UserAgent = ctx.Request.UserAgent()
+	req.ClientIP = ctx.ClientIP()
+
+	// Call the usecase
+	res, err := c.OTPUsecase.VerifyOTPLogin(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[OTPController][VerifyOTPLogin] Error in VerifyOTPLogin: ", err)
+		loginFailed(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "User Logged In Successfully", Success: true, Data: *res})
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+)
+
+type PasswordController struct {
+	PasswordUsecase domain.PasswordUsecase
+}
+
+// ChangePassword godoc
+//
+//	@Summary		Change my password
+//	@Description	Change the password of the user identified by the bearer token, the current password is required. Wrong current passwords count towards the login lockout. Every other session of the user is ended. Tokens of OAuth clients and personal access tokens need the profile:write scope.
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string								true	"Bearer token"
+//	@Param			request			body		domain.ChangePasswordRequest		true	"Passwords"
+//	@Success		200				{object}	domain.Response						"Password Changed Successfully"
+//	@Failure		400				{object}	domain.PasswordPolicyErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse				"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse				"Forbidden"
+//	@Failure		429				{object}	domain.ErrorResponse				"Too Many Failed Login Attempts"
+//	@Failure		500				{object}	domain.ErrorResponse				"Internal Server Error"
+//	@Router			/user/me/password [post]
+//	@Tags			user management service
+func (c *PasswordController) ChangePassword(ctx *gin.Context) {
+	var req domain.ChangePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[PasswordController][ChangePassword] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+	principal, ok := userPrincipal(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = principal.UserID
+	req.SessionID = principal.SessionID
+	req.ClientIP = ctx.ClientIP()
+
+	// Call the usecase
+	if err := c.PasswordUsecase.ChangePassword(ctx.Request.Context(), &req); err != nil {
+		log.Println("[PasswordController][ChangePassword] Error in ChangePassword: ", err)
+		if cerr.GetErrorCode(err) == cerr.TooManyRequestsErrorCode {
+			tooManyRequests(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false, Errors: cerr.GetErrorDetails(err)})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Password Changed Successfully", Success: true})
+}
+
+// RequestPasswordReset godoc
+//
+//	@Summary		Request a password reset
+//	@Description	Send a single use reset token to the email address or phone number of the user, at most one per PASSWORD_RESET_RESEND_INTERVAL_SECONDS. The token is sent in the background, so the response is the same whether or not the user exists.
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		domain.PasswordResetRequest	true	"User"
+//	@Success		200		{object}	domain.Response				"Password Reset Requested"
+//	@Failure		400		{object}	domain.ErrorResponse		"Invalid Request"
+//	@Failure		401		{object}	domain.ErrorResponse		"Unauthorized"
+//	@Failure		500		{object}	domain.ErrorResponse		"Internal Server Error"
+//	@Router			/user/password/reset [post]
+//	@Tags			user management service
+func (c *PasswordController) RequestPasswordReset(ctx *gin.Context) {
+	var req domain.PasswordResetRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[PasswordController][RequestPasswordReset] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	if err := c.PasswordUsecase.RequestPasswordReset(ctx.Request.Context(), &req); err != nil {
+		log.Println("[PasswordController][RequestPasswordReset] Error in RequestPasswordReset: ", err)
+		ctx.JSON(http.StatusInternalServerError, domain.Response{Message: "Internal Server Error", Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "If the user exists, a reset token has been sent", Success: true})
+}
+
+// ResetPassword godoc
+//
+//	@Summary		Reset a password
+//	@Description	Set a new password with a reset token, every session of the user is ended
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		domain.ConfirmPasswordResetRequest	true	"Reset token and new password"
+//	@Success		200		{object}	domain.Response						"Password Reset Successfully"
+//	@Failure		400		{object}	domain.PasswordPolicyErrorResponse	"Invalid Request"
+//	@Failure		401		{object}	domain.ErrorResponse				"Unauthorized"
+//	@Failure		500		{object}	domain.ErrorResponse				"Internal Server Error"
+//	@Router			/user/password/reset/confirm [post]
+//	@Tags			user management service
+func (c *PasswordController) ResetPassword(ctx *gin.Context) {
+	var req domain.ConfirmPasswordResetRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[PasswordController][ResetPassword] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	if err := c.PasswordUsecase.ResetPassword(ctx.Request.Context(), &req); err != nil {
+		log.Println("[PasswordController][ResetPassword] Error in ResetPassword: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false, Errors: cerr.GetErrorDetails(err)})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Password Reset Successfully", Success: true})
+}
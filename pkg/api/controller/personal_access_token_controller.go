@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+)
+
+type PersonalAccessTokenController struct {
+	PersonalAccessTokenUsecase domain.PersonalAccessTokenUsecase
+}
+
+// CreatePersonalAccessToken godoc
+//
+//	@Summary		Create a personal access token
+//	@Description	Create a long lived token for the user identified by the bearer token, limited to scopes the user holds as permissions and to profile:write. The token in the response is only shown once. Tokens of OAuth clients need the profile:write scope.
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string									true	"Bearer token"
+//	@Param			request			body		domain.CreatePersonalAccessTokenRequest	true	"Token Details"
+//	@Success		200				{object}	domain.CreatePersonalAccessTokenResp	"Token Created Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse					"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse					"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse					"Personal Access Tokens Cannot Create Tokens"
+//	@Failure		500				{object}	domain.ErrorResponse					"Internal Server Error"
+//	@Router			/user/me/tokens [post]
+//	@Tags			user management service
+func (c *PersonalAccessTokenController) CreatePersonalAccessToken(ctx *gin.Context) {
+	var req domain.CreatePersonalAccessTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[PersonalAccessTokenController][CreatePersonalAccessToken] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+	principal, ok := userPrincipal(ctx)
+	if !ok {
+		return
+	}
+	req.Requester = principal
+
+	// Call the usecase
+	res, err := c.PersonalAccessTokenUsecase.CreatePersonalAccessToken(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[PersonalAccessTokenController][CreatePersonalAccessToken] Error in CreatePersonalAccessToken: ", err)
+		if cerr.GetErrorCode(err) == cerr.ForbiddenErrorCode {
+			ctx.JSON(http.StatusForbidden, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Token Created Successfully", Success: true, Data: *res})
+}
+
+// GetPersonalAccessTokens godoc
+//
+//	@Summary		List my personal access tokens
+//	@Description	List the personal access tokens of the user identified by the bearer token that have not been revoked. Personal access tokens cannot list tokens.
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string								true	"Bearer token"
+//	@Success		200				{object}	domain.GetPersonalAccessTokensResp	"Tokens Fetched Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse				"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse				"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse				"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse				"Internal Server Error"
+//	@Router			/user/me/tokens [get]
+//	@Tags			user management service
+func (c *PersonalAccessTokenController) GetPersonalAccessTokens(ctx *gin.Context) {
+	principal, ok := userPrincipal(ctx)
+	if !ok {
+		return
+	}
+
+	// Call the usecase
+	res, err := c.PersonalAccessTokenUsecase.GetPersonalAccessTokens(ctx.Request.Context(), &domain.GetPersonalAccessTokensRequest{UserID: principal.UserID})
+	if err != nil {
+		log.Println("[PersonalAccessTokenController][GetPersonalAccessTokens] Error in GetPersonalAccessTokens: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Tokens Fetched Successfully", Success: true, Data: *res})
+}
+
+// RevokePersonalAccessToken godoc
+//
+//	@Summary		Revoke one of my personal access tokens
+//	@Description	Revoke a personal access token of the user identified by the bearer token, it stops working immediately. Personal access tokens cannot revoke tokens, tokens of OAuth clients need the profile:write scope.
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Param			id				path		string					true	"Token ID"
+//	@Success		200				{object}	domain.Response			"Token Revoked Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user/me/tokens/{id} [delete]
+//	@Tags			user management service
+func (c *PersonalAccessTokenController) RevokePersonalAccessToken(ctx *gin.Context) {
+	var req domain.RevokePersonalAccessTokenRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[PersonalAccessTokenController][RevokePersonalAccessToken] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+	principal, ok := userPrincipal(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = principal.UserID
+
+	// Call the usecase
+	if err := c.PersonalAccessTokenUsecase.RevokePersonalAccessToken(ctx.Request.Context(), &req); err != nil {
+		log.Println("[PersonalAccessTokenController][RevokePersonalAccessToken] Error in RevokePersonalAccessToken: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Token Revoked Successfully", Success: true})
+}
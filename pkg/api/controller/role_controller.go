@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+)
+
+type RoleController struct {
+	RoleUsecase domain.RoleUsecase
+}
+
+// GetRoles godoc
+//
+//	@Summary		List roles
+//	@Description	List the roles and the permissions they grant, requires the roles:read permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Success		200				{object}	domain.GetRolesResp		"Roles Fetched Successfully"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/admin/roles [get]
+//	@Tags			admin
+func (c *RoleController) GetRoles(ctx *gin.Context) {
+	// Call the usecase
+	res, err := c.RoleUsecase.GetRoles(ctx.Request.Context())
+	if err != nil {
+		log.Println("[RoleController][GetRoles] Error in GetRoles: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Roles Fetched Successfully", Success: true, Data: *res})
+}
+
+// CreateRole godoc
+//
+//	@Summary		Create a role
+//	@Description	Create a role granting existing permissions, requires the roles:write permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string						true	"Bearer token"
+//	@Param			request			body		domain.CreateRoleRequest	true	"Role"
+//	@Success		200				{object}	domain.RoleResp				"Role Created Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse		"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse		"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse		"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse		"Internal Server Error"
+//	@Router			/admin/roles [post]
+//	@Tags			admin
+func (c *RoleController) CreateRole(ctx *gin.Context) {
+	var req domain.CreateRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[RoleController][CreateRole] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	res, err := c.RoleUsecase.CreateRole(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[RoleController][CreateRole] Error in CreateRole: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Role Created Successfully", Success: true, Data: *res})
+}
+
+// GetRoleMembers godoc
+//
+//	@Summary		List role members
+//	@Description	List the users holding a role, requires the roles:read permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string						true	"Bearer token"
+//	@Param			role			path		string						true	"Role Name"
+//	@Success		200				{object}	domain.GetRoleMembersResp	"Role Members Fetched Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse		"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse		"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse		"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse		"Internal Server Error"
+//	@Router			/admin/roles/{role}/members [get]
+//	@Tags			admin
+func (c *RoleController) GetRoleMembers(ctx *gin.Context) {
+	var req domain.GetRoleMembersRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[RoleController][GetRoleMembers] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	res, err := c.RoleUsecase.GetRoleMembers(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[RoleController][GetRoleMembers] Error in GetRoleMembers: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Role Members Fetched Successfully", Success: true, Data: *res})
+}
+
+// GrantRole godoc
+//
+//	@Summary		Grant a role
+//	@Description	Grant a role to a user, it is embedded in tokens issued from then on. Requires the roles:write permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Param			role			path		string					true	"Role Name"
+//	@Param			username		path		string					true	"User Name"
+//	@Success		200				{object}	domain.Response			"Role Granted Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/admin/roles/{role}/members/{username} [post]
+//	@Tags			admin
+func (c *RoleController) GrantRole(ctx *gin.Context) {
+	var req domain.RoleMemberRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[RoleController][GrantRole] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	if err := c.RoleUsecase.GrantRole(ctx.Request.Context(), &req); err != nil {
+		log.Println("[RoleController][GrantRole] Error in GrantRole: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Role Granted Successfully", Success: true})
+}
+
+// RevokeRole godoc
+//
+//	@Summary		Revoke a role
+//	@Description	Revoke a role from a user, tokens already issued keep it until they expire unless PERMISSION_SOURCE is repository. Requires the roles:write permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Param			role			path		string					true	"Role Name"
+//	@Param			username		path		string					true	"User Name"
+//	@Success		200				{object}	domain.Response			"Role Revoked Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/admin/roles/{role}/members/{username} [delete]
+//	@Tags			admin
+func (c *RoleController) RevokeRole(ctx *gin.Context) {
+	var req domain.RoleMemberRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[RoleController][RevokeRole] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	if err := c.RoleUsecase.RevokeRole(ctx.Request.Context(), &req); err != nil {
+		log.Println("[RoleController][RevokeRole] Error in RevokeRole: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Role Revoked Successfully", Success: true})
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+)
+
+type SigningKeyController struct {
+	SigningKeyUsecase domain.SigningKeyUsecase
+}
+
+// GetSigningKeys godoc
+//
+//	@Summary		List signing keys
+//	@Description	List the active and retired JWT signing keys, requires the keys:read permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string						true	"Bearer token"
+//	@Success		200				{object}	domain.GetSigningKeysResp	"Signing Keys Fetched Successfully"
+//	@Failure		401				{object}	domain.ErrorResponse		"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse		"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse		"Internal Server Error"
+//	@Router			/admin/keys [get]
+//	@Tags			admin
+func (c *SigningKeyController) GetSigningKeys(ctx *gin.Context) {
+	// Call the usecase
+	res, err := c.SigningKeyUsecase.GetSigningKeys(ctx.Request.Context())
+	if err != nil {
+		log.Println("[SigningKeyController][GetSigningKeys] Error in GetSigningKeys: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Signing Keys Fetched Successfully", Success: true, Data: *res})
+}
+
+// RotateSigningKey godoc
+//
+//	@Summary		Rotate the signing key
+//	@Description	Generate a new active signing key, the previous key keeps verifying tokens for the grace period. Requires the keys:write permission.
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string							true	"Bearer token"
+//	@Param			request			body		domain.RotateSigningKeyRequest	false	"Grace Period"
+//	@Success		200				{object}	domain.SigningKeyResp			"Signing Key Rotated Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse			"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse			"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse			"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse			"Internal Server Error"
+//	@Router			/admin/keys/rotate [post]
+//	@Tags			admin
+func (c *SigningKeyController) RotateSigningKey(ctx *gin.Context) {
+	var req domain.RotateSigningKeyRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			log.Println("[SigningKeyController][RotateSigningKey] Error in ShouldBindJSON: ", err)
+			ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+			return
+		}
+	}
+
+	// Call the usecase
+	res, err := c.SigningKeyUsecase.RotateSigningKey(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[SigningKeyController][RotateSigningKey] Error in RotateSigningKey: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Signing Key Rotated Successfully", Success: true, Data: *res})
+}
+
+// RetireSigningKey godoc
+//
+//	@Summary		Retire a signing key
+//	@Description	Retire an inactive signing key, it keeps verifying tokens for the grace period. Requires the keys:write permission.
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string							true	"Bearer token"
+//	@Param			kid				path		string							true	"Key ID"
+//	@Param			request			body		domain.RetireSigningKeyRequest	false	"Grace Period"
+//	@Success		200				{object}	domain.SigningKeyResp			"Signing Key Retired Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse			"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse			"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse			"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse			"Internal Server Error"
+//	@Router			/admin/keys/{kid}/retire [post]
+//	@Tags			admin
+func (c *SigningKeyController) RetireSigningKey(ctx *gin.Context) {
+	var req domain.RetireSigningKeyRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[SigningKeyController][RetireSigningKey] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			log.Println("[SigningKeyController][RetireSigningKey] Error in ShouldBindJSON: ", err)
+			ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+			return
+		}
+	}
+
+	// Call the usecase
+	res, err := c.SigningKeyUsecase.RetireSigningKey(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[SigningKeyController][RetireSigningKey] Error in RetireSigningKey: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Signing Key Retired Successfully", Success: true, Data: *res})
+}
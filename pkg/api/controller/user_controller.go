@@ -2,10 +2,14 @@ package controller
 
 import (
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/common/jwt"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/common/logger"
@@ -13,6 +17,7 @@ import (
 
 type UserController struct {
 	UserUsecase domain.UserUsecase
+	Authorizer  domain.Authorizer
 	Logger      *logger.MtnLogger
 }
 
@@ -36,11 +41,11 @@ func (c *UserController) HealthCheck(ctx *gin.Context) {
 //	@Description	Register a new user
 //	@Accept			json
 //	@Produce		json
-//	@Param			user	body		domain.RegisterUserRequest	true	"User Details"
-//	@Success		200		{object}	domain.RegisterUserResp		"User Registered Successfully"
-//	@Failure		400		{object}	domain.ErrorResponse		"Invalid Request"
-//	@Failure		401		{object}	domain.ErrorResponse		"Unauthorized"
-//	@Failure		500		{object}	domain.ErrorResponse		"Internal Server Error"
+//	@Param			user	body		domain.RegisterUserRequest			true	"User Details"
+//	@Success		200		{object}	domain.RegisterUserResp				"User Registered Successfully"
+//	@Failure		400		{object}	domain.PasswordPolicyErrorResponse	"Invalid Request"
+//	@Failure		401		{object}	domain.ErrorResponse				"Unauthorized"
+//	@Failure		500		{object}	domain.ErrorResponse				"Internal Server Error"
 //	@Router			/user/register [post]
 //	@Tags			user management service
 func (c *UserController) RegisterUser(ctx *gin.Context) {
@@ -55,7 +60,7 @@ func (c *UserController) RegisterUser(ctx *gin.Context) {
 	res, err := c.UserUsecase.RegisterUser(ctx.Request.Context(), &req)
 	if err != nil {
 		log.Println("[UserController][RegisterUser] Error in RegisterUser: ", err)
-		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false, Errors: cerr.GetErrorDetails(err)})
 		return
 	}
 
@@ -65,13 +70,15 @@ func (c *UserController) RegisterUser(ctx *gin.Context) {
 // LoginUser godoc
 //
 //	@Summary		Login a user
-//	@Description	Login a user
+//	@Description	Login a user, repeated failed logins of a username or from a client IP are slowed down and then locked out for a while. For a user with MFA enabled the response only holds an MFA token, the login is completed at /user/login/mfa.
 //	@Accept			json
 //	@Produce		json
 //	@Param			user	body		domain.LoginUserRequest	true	"User Details"
 //	@Success		200		{object}	domain.LoginSuccessResp	"User Logged In Successfully"
 //	@Failure		400		{object}	domain.ErrorResponse	"Invalid Request"
 //	@Failure		401		{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403		{object}	domain.ErrorResponse	"Email Address Not Verified"
+//	@Failure		429		{object}	domain.ErrorResponse	"Too Many Failed Login Attempts"
 //	@Failure		500		{object}	domain.ErrorResponse	"Internal Server Error"
 //	@Router			/user/login [post]
 //	@Tags			user management service
@@ -82,29 +89,296 @@ func (c *UserController) LoginUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
 		return
 	}
+	req.UserAgent = ctx.Request.UserAgent()
+	req.ClientIP = ctx.ClientIP()
 
 	// Call the usecase
 	res, err := c.UserUsecase.LoginUser(ctx.Request.Context(), &req)
 	if err != nil {
 		log.Println("[UserController][LoginUser] Error in LoginUser: ", err)
-		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		loginFailed(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, domain.Response{Message: "User Logged In Successfully", Success: true, Data: *res})
 }
 
+// LoginMFA godoc
+//
+//	@Summary		Complete a login with MFA
+//	@Description	Complete the login of a user with MFA enabled with the MFA token of /user/login and a code of the authenticator app or a recovery code. Wrong codes count towards the lockout like wrong passwords.
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		domain.LoginMFARequest	true	"MFA token and code"
+//	@Success		200		{object}	domain.LoginSuccessResp	"User Logged In Successfully"
+//	@Failure		400		{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401		{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403		{object}	domain.ErrorResponse	"Email Address Not Verified"
+//	@Failure		429		{object}	domain.ErrorResponse	"Too Many Failed Login Attempts"
+//	@Failure		500		{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user/login/mfa [post]
+//	@Tags			user management service
+func (c *UserController) LoginMFA(ctx *gin.Context) {
+	var req domain.LoginMFARequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[UserController][LoginMFA] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+	req.UserAgent = ctx.Request.UserAgent()
+	req.ClientIP = ctx.ClientIP()
+
+	// Call the usecase
+	res, err := c.UserUsecase.LoginMFA(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[UserController][LoginMFA] Error in LoginMFA: ", err)
+		loginFailed(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "User Logged In Successfully", Success: true, Data: *res})
+}
+
+// RefreshToken godoc
+//
+//	@Summary		Refresh an access token
+//	@Description	Exchange a refresh token for a new access token, the refresh token is rotated on every use
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		domain.RefreshTokenRequest	true	"Refresh Token"
+//	@Success		200		{object}	domain.LoginSuccessResp		"Token Refreshed Successfully"
+//	@Failure		400		{object}	domain.ErrorResponse		"Invalid Request"
+//	@Failure		401		{object}	domain.ErrorResponse		"Unauthorized"
+//	@Failure		500		{object}	domain.ErrorResponse		"Internal Server Error"
+//	@Router			/user/token/refresh [post]
+//	@Tags			user management service
+func (c *UserController) RefreshToken(ctx *gin.Context) {
+	var req domain.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[UserController][RefreshToken] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	res, err := c.UserUsecase.RefreshToken(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[UserController][RefreshToken] Error in RefreshToken: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Token Refreshed Successfully", Success: true, Data: *res})
+}
+
+// Logout godoc
+//
+//	@Summary		Logout a user
+//	@Description	Revokes the given access token so it is rejected before it expires
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		domain.LogoutRequest	true	"Token Payload"
+//	@Success		200		{object}	domain.Response			"User Logged Out Successfully"
+//	@Failure		400		{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401		{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		500		{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user/logout [post]
+//	@Tags			user management service
+func (c *UserController) Logout(ctx *gin.Context) {
+	var req domain.LogoutRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[UserController][Logout] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	if err := c.UserUsecase.Logout(ctx.Request.Context(), &req); err != nil {
+		log.Println("[UserController][Logout] Error in Logout: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "User Logged Out Successfully", Success: true})
+}
+
+// RevokeSession godoc
+//
+//	@Summary		Revoke a session
+//	@Description	Revokes every access token issued for a session of the user, requires the users:write permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Param			username		path		string					true	"User Name"
+//	@Param			sid				path		string					true	"Session ID"
+//	@Success		200				{object}	domain.Response			"Session Revoked Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/admin/users/{username}/sessions/{sid}/revoke [post]
+//	@Tags			admin
+func (c *UserController) RevokeSession(ctx *gin.Context) {
+	var req domain.RevokeSessionRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[UserController][RevokeSession] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	if err := c.UserUsecase.RevokeSession(ctx.Request.Context(), &req); err != nil {
+		log.Println("[UserController][RevokeSession] Error in RevokeSession: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Session Revoked Successfully", Success: true})
+}
+
+// GetSessions godoc
+//
+//	@Summary		List my sessions
+//	@Description	List the active sessions of the user identified by the bearer token
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Success		200				{object}	domain.GetSessionsResp	"Sessions Fetched Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user/me/sessions [get]
+//	@Tags			user management service
+func (c *UserController) GetSessions(ctx *gin.Context) {
+	principal, ok := userPrincipal(ctx)
+	if !ok {
+		return
+	}
+	req := domain.GetSessionsRequest{UserID: principal.UserID, CurrentSessionID: principal.SessionID}
+
+	// Call the usecase
+	res, err := c.UserUsecase.GetSessions(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[UserController][GetSessions] Error in GetSessions: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Sessions Fetched Successfully", Success: true, Data: *res})
+}
+
+// EndSession godoc
+//
+//	@Summary		End one of my sessions
+//	@Description	End a session of the user identified by the bearer token, its tokens stop working immediately. Tokens of OAuth clients and personal access tokens need the profile:write scope.
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Param			sid				path		string					true	"Session ID"
+//	@Success		200				{object}	domain.Response			"Session Ended Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user/me/sessions/{sid} [delete]
+//	@Tags			user management service
+func (c *UserController) EndSession(ctx *gin.Context) {
+	var req domain.EndSessionRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[UserController][EndSession] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+	principal, ok := userPrincipal(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = principal.UserID
+
+	// Call the usecase
+	if err := c.UserUsecase.EndSession(ctx.Request.Context(), &req); err != nil {
+		log.Println("[UserController][EndSession] Error in EndSession: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Session Ended Successfully", Success: true})
+}
+
+// UserInfo godoc
+//
+//	@Summary		OpenID Connect userinfo
+//	@Description	Returns the claims of the user identified by the bearer token
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Success		200				{object}	map[string]interface{}	"User Claims"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/userinfo [get]
+//	@Tags			discovery
+func (c *UserController) UserInfo(ctx *gin.Context) {
+	principal, ok := userPrincipal(ctx)
+	if !ok {
+		return
+	}
+	req := domain.UserInfoRequest{UserID: principal.UserID}
+
+	// Call the usecase
+	res, err := c.UserUsecase.GetUserInfo(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[UserController][UserInfo] Error in GetUserInfo: ", err)
+		ctx.JSON(http.StatusUnauthorized, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	// The userinfo response is a plain claims object as required by OpenID Connect
+	ctx.JSON(http.StatusOK, res)
+}
+
+// GetMe godoc
+//
+//	@Summary		Get my user
+//	@Description	Get the user identified by the bearer token
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string							true	"Bearer token"
+//	@Success		200				{object}	domain.GetUserByUserNameResp	"User Fetched Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse			"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse			"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse			"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse			"Internal Server Error"
+//	@Router			/user/me [get]
+//	@Tags			user management service
+func (c *UserController) GetMe(ctx *gin.Context) {
+	principal, ok := userPrincipal(ctx)
+	if !ok {
+		return
+	}
+	req := domain.GetUserRequest{UserID: principal.UserID}
+
+	// Call the usecase
+	res, err := c.UserUsecase.GetUser(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[UserController][GetMe] Error in GetUser: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "User Fetched Successfully", Success: true, Data: *res})
+}
+
 // GetUserByUserName godoc
 //
 //	@Summary		Get user by username
-//	@Description	Get user by username
+//	@Description	Get user by username, users can only read their own record unless they hold the users:read permission
 //	@Accept			json
 //	@Produce		json
-//	@Param			username	path		string							true	"User Name"
-//	@Success		200			{object}	domain.GetUserByUserNameResp	"User Fetched Successfully"
-//	@Failure		400			{object}	domain.ErrorResponse			"Invalid Request"
-//	@Failure		401			{object}	domain.ErrorResponse			"Unauthorized"
-//	@Failure		500			{object}	domain.ErrorResponse			"Internal Server Error"
+//	@Param			Authorization	header		string							true	"Bearer token"
+//	@Param			username		path		string							true	"User Name"
+//	@Success		200				{object}	domain.GetUserByUserNameResp	"User Fetched Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse			"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse			"Unauthorized"
+//	@Failure		500				{object}	domain.ErrorResponse			"Internal Server Error"
 //	@Router			/user/{username} [get]
 //	@Tags			user management service
 func (c *UserController) GetUserByUserName(ctx *gin.Context) {
@@ -114,6 +388,12 @@ func (c *UserController) GetUserByUserName(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
 		return
 	}
+	principal, canReadAll, ok := c.requester(ctx, models.PermissionUsersRead)
+	if !ok {
+		return
+	}
+	req.RequesterID = principal.UserID
+	req.RequesterCanReadAll = canReadAll
 
 	// Call the usecase
 	res, err := c.UserUsecase.GetUserByUserName(ctx.Request.Context(), &req)
@@ -133,10 +413,10 @@ func (c *UserController) GetUserByUserName(ctx *gin.Context) {
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		domain.TokenValidationRequest	true	"Token Payload"
-//	@Success		200		{object}	domain.Response	"Token is valid"
-//	@Failure		400		{object}	domain.ErrorResponse	"Invalid Request"
-//	@Failure		401		{object}	domain.ErrorResponse	"Invalid or missing token"
-//	@Failure		500		{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Success		200		{object}	domain.Response					"Token is valid"
+//	@Failure		400		{object}	domain.ErrorResponse			"Invalid Request"
+//	@Failure		401		{object}	domain.ErrorResponse			"Invalid or missing token"
+//	@Failure		500		{object}	domain.ErrorResponse			"Internal Server Error"
 //	@Router			/user/validate-token [post]
 //	@Tags			user management service
 func (c *UserController) ValidateToken(ctx *gin.Context) {
@@ -146,10 +426,423 @@ func (c *UserController) ValidateToken(ctx *gin.Context) {
 		return
 	}
 
-	if err := jwt.ValidateToken(req.Token); err != nil {
+	if err := jwt.ValidateToken(ctx.Request.Context(), req.Token); err != nil {
 		ctx.JSON(http.StatusUnauthorized, domain.Response{Message: err.Error(), Success: false})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, domain.Response{Message: "Token is valid", Success: true})
 }
+
+// ListUsers godoc
+//
+//	@Summary		List users
+//	@Description	List the users of the tenant a page at a time, requires the users:read permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Param			cursor			query		string					false	"Next cursor of the previous page"
+//	@Param			limit			query		int						false	"Page size, 50 by default and at most 200"
+//	@Param			created_after	query		string					false	"Created at or after, RFC 3339"
+//	@Param			created_before	query		string					false	"Created before, RFC 3339"
+//	@Param			status			query		string					false	"active, pending_verification, suspended, locked, deactivated, deleted or all, every user not deleted by default"
+//	@Param			role			query		string					false	"Role Name"
+//	@Param			username_prefix	query		string					false	"User Name Prefix"
+//	@Param			sort			query		string					false	"created_at (default) or user_name"
+//	@Param			order			query		string					false	"asc (default) or desc"
+//	@Success		200				{object}	domain.ListUsersResp	"Users Fetched Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user [get]
+//	@Tags			user management service
+func (c *UserController) ListUsers(ctx *gin.Context) {
+	var req domain.ListUsersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		log.Println("[UserController][ListUsers] Error in ShouldBindQuery: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	res, err := c.UserUsecase.ListUsers(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[UserController][ListUsers] Error in ListUsers: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Users Fetched Successfully", Success: true, Data: res.Users, Meta: res.Meta})
+}
+
+// UpdateUser godoc
+//
+//	@Summary		Update a user
+//	@Description	Update the profile of a user, users can only update themselves unless they hold the users:write permission. Tokens of OAuth clients and personal access tokens need the profile:write scope to update their own user.
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string							true	"Bearer token"
+//	@Param			username		path		string							true	"User Name"
+//	@Param			request			body		domain.UpdateUserRequest		true	"Profile"
+//	@Success		200				{object}	domain.GetUserByUserNameResp	"User Updated Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse			"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse			"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse			"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse			"Internal Server Error"
+//	@Router			/user/{username} [patch]
+//	@Tags			user management service
+func (c *UserController) UpdateUser(ctx *gin.Context) {
+	var req domain.UpdateUserRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[UserController][UpdateUser] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[UserController][UpdateUser] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+	principal, canWriteAll, ok := c.requester(ctx, models.PermissionUsersWrite)
+	if !ok {
+		return
+	}
+	req.RequesterID = principal.UserID
+	req.RequesterCanWriteAll = canWriteAll
+	req.RequesterCanWriteSelf = principal.CanManageSelf()
+
+	// Call the usecase
+	res, err := c.UserUsecase.UpdateUser(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[UserController][UpdateUser] Error in UpdateUser: ", err)
+		if cerr.GetErrorCode(err) == cerr.ForbiddenErrorCode {
+			ctx.JSON(http.StatusForbidden, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "User Updated Successfully", Success: true, Data: *res})
+}
+
+// ChangeUserName godoc
+//
+//	@Summary		Change a username
+//	@Description	Change the username of a user, users can only rename themselves unless they hold the users:write permission. Tokens of OAuth clients and personal access tokens need the profile:write scope to rename their own user.
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string							true	"Bearer token"
+//	@Param			username		path		string							true	"User Name"
+//	@Param			request			body		domain.ChangeUserNameRequest	true	"New User Name"
+//	@Success		200				{object}	domain.GetUserByUserNameResp	"Username Changed Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse			"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse			"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse			"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse			"Internal Server Error"
+//	@Router			/user/{username}/username [put]
+//	@Tags			user management service
+func (c *UserController) ChangeUserName(ctx *gin.Context) {
+	var req domain.ChangeUserNameRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[UserController][ChangeUserName] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		log.Println("[UserController][ChangeUserName] Error in ShouldBindJSON: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+	principal, canWriteAll, ok := c.requester(ctx, models.PermissionUsersWrite)
+	if !ok {
+		return
+	}
+	req.RequesterID = principal.UserID
+	req.RequesterCanWriteAll = canWriteAll
+	req.RequesterCanWriteSelf = principal.CanManageSelf()
+
+	// Call the usecase
+	res, err := c.UserUsecase.ChangeUserName(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[UserController][ChangeUserName] Error in ChangeUserName: ", err)
+		if cerr.GetErrorCode(err) == cerr.ForbiddenErrorCode {
+			ctx.JSON(http.StatusForbidden, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "Username Changed Successfully", Success: true, Data: *res})
+}
+
+// DeleteUser godoc
+//
+//	@Summary		Delete a user
+//	@Description	Soft delete a user and end their sessions, users can only delete themselves unless they hold the users:write permission. Tokens of OAuth clients need the profile:write scope to delete their own user, personal access tokens cannot.
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Param			username		path		string					true	"User Name"
+//	@Success		200				{object}	domain.Response			"User Deleted Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/user/{username} [delete]
+//	@Tags			user management service
+func (c *UserController) DeleteUser(ctx *gin.Context) {
+	var req domain.DeleteUserRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[UserController][DeleteUser] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+	principal, canWriteAll, ok := c.requester(ctx, models.PermissionUsersWrite)
+	if !ok {
+		return
+	}
+	req.RequesterID = principal.UserID
+	req.RequesterCanWriteAll = canWriteAll
+	req.RequesterCanWriteSelf = principal.PersonalAccessTokenID == "" && principal.CanManageSelf()
+
+	// Call the usecase
+	if err := c.UserUsecase.DeleteUser(ctx.Request.Context(), &req); err != nil {
+		log.Println("[UserController][DeleteUser] Error in DeleteUser: ", err)
+		if cerr.GetErrorCode(err) == cerr.ForbiddenErrorCode {
+			ctx.JSON(http.StatusForbidden, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "User Deleted Successfully", Success: true})
+}
+
+// RestoreUser godoc
+//
+//	@Summary		Restore a deleted user
+//	@Description	Restore a soft deleted user, requires the users:write permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string							true	"Bearer token"
+//	@Param			username		path		string							true	"User Name"
+//	@Success		200				{object}	domain.GetUserByUserNameResp	"User Restored Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse			"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse			"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse			"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse			"Internal Server Error"
+//	@Router			/admin/users/{username}/restore [post]
+//	@Tags			admin
+func (c *UserController) RestoreUser(ctx *gin.Context) {
+	var req domain.RestoreUserRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[UserController][RestoreUser] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+
+	// Call the usecase
+	res, err := c.UserUsecase.RestoreUser(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[UserController][RestoreUser] Error in RestoreUser: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "User Restored Successfully", Success: true, Data: *res})
+}
+
+// UnlockUser godoc
+//
+//	@Summary		Unlock a user
+//	@Description	Lift the lockout of a username caused by failed logins and move a user in the locked status back to active, requires the users:write permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer token"
+//	@Param			username		path		string					true	"User Name"
+//	@Success		200				{object}	domain.Response			"User Unlocked Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse	"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse	"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse	"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse	"Internal Server Error"
+//	@Router			/admin/users/{username}/unlock [post]
+//	@Tags			admin
+func (c *UserController) UnlockUser(ctx *gin.Context) {
+	var req domain.UnlockUserRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[UserController][UnlockUser] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+	if principal := domain.PrincipalFromContext(ctx.Request.Context()); principal != nil {
+		req.RequesterID = principal.UserID
+	}
+
+	// Call the usecase
+	if err := c.UserUsecase.UnlockUser(ctx.Request.Context(), &req); err != nil {
+		log.Println("[UserController][UnlockUser] Error in UnlockUser: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: "User Unlocked Successfully", Success: true})
+}
+
+// SuspendUser godoc
+//
+//	@Summary		Suspend a user
+//	@Description	Suspend a user, they cannot log in and their tokens stop working until they are reactivated. Requires the users:write permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string							true	"Bearer token"
+//	@Param			username		path		string							true	"User Name"
+//	@Param			request			body		domain.ChangeUserStatusRequest	false	"Reason"
+//	@Success		200				{object}	domain.GetUserByUserNameResp	"User Suspended Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse			"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse			"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse			"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse			"Internal Server Error"
+//	@Router			/admin/users/{username}/suspend [post]
+//	@Tags			admin
+func (c *UserController) SuspendUser(ctx *gin.Context) {
+	c.changeUserStatus(ctx, "SuspendUser", models.UserStatusSuspended, "User Suspended Successfully")
+}
+
+// LockUser godoc
+//
+//	@Summary		Lock a user
+//	@Description	Lock a user, they cannot log in and their tokens stop working until they are reactivated. Requires the users:write permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string							true	"Bearer token"
+//	@Param			username		path		string							true	"User Name"
+//	@Param			request			body		domain.ChangeUserStatusRequest	false	"Reason"
+//	@Success		200				{object}	domain.GetUserByUserNameResp	"User Locked Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse			"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse			"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse			"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse			"Internal Server Error"
+//	@Router			/admin/users/{username}/lock [post]
+//	@Tags			admin
+func (c *UserController) LockUser(ctx *gin.Context) {
+	c.changeUserStatus(ctx, "LockUser", models.UserStatusLocked, "User Locked Successfully")
+}
+
+// DeactivateUser godoc
+//
+//	@Summary		Deactivate a user
+//	@Description	Deactivate a user, they cannot log in and their tokens stop working until they are reactivated. Requires the users:write permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string							true	"Bearer token"
+//	@Param			username		path		string							true	"User Name"
+//	@Param			request			body		domain.ChangeUserStatusRequest	false	"Reason"
+//	@Success		200				{object}	domain.GetUserByUserNameResp	"User Deactivated Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse			"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse			"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse			"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse			"Internal Server Error"
+//	@Router			/admin/users/{username}/deactivate [post]
+//	@Tags			admin
+func (c *UserController) DeactivateUser(ctx *gin.Context) {
+	c.changeUserStatus(ctx, "DeactivateUser", models.UserStatusDeactivated, "User Deactivated Successfully")
+}
+
+// ReactivateUser godoc
+//
+//	@Summary		Reactivate a user
+//	@Description	Reactivate a suspended, locked or deactivated user, requires the users:write permission
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string							true	"Bearer token"
+//	@Param			username		path		string							true	"User Name"
+//	@Param			request			body		domain.ChangeUserStatusRequest	false	"Reason"
+//	@Success		200				{object}	domain.GetUserByUserNameResp	"User Reactivated Successfully"
+//	@Failure		400				{object}	domain.ErrorResponse			"Invalid Request"
+//	@Failure		401				{object}	domain.ErrorResponse			"Unauthorized"
+//	@Failure		403				{object}	domain.ErrorResponse			"Forbidden"
+//	@Failure		500				{object}	domain.ErrorResponse			"Internal Server Error"
+//	@Router			/admin/users/{username}/reactivate [post]
+//	@Tags			admin
+func (c *UserController) ReactivateUser(ctx *gin.Context) {
+	c.changeUserStatus(ctx, "ReactivateUser", models.UserStatusActive, "User Reactivated Successfully")
+}
+
+// changeUserStatus moves the user of the request to the status, method names the handler in the log
+func (c *UserController) changeUserStatus(ctx *gin.Context, method string, status string, message string) {
+	var req domain.ChangeUserStatusRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		log.Println("[UserController]["+method+"] Error in ShouldBindUri: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+		return
+	}
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			log.Println("[UserController]["+method+"] Error in ShouldBindJSON: ", err)
+			ctx.JSON(http.StatusBadRequest, domain.Response{Message: "Invalid Request", Success: false})
+			return
+		}
+	}
+	req.Status = status
+	if principal := domain.PrincipalFromContext(ctx.Request.Context()); principal != nil {
+		req.RequesterID = principal.UserID
+	}
+
+	// Call the usecase
+	res, err := c.UserUsecase.ChangeUserStatus(ctx.Request.Context(), &req)
+	if err != nil {
+		log.Println("[UserController]["+method+"] Error in ChangeUserStatus: ", err)
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, domain.Response{Message: message, Success: true, Data: *res})
+}
+
+// requester returns the caller identified by the bearer token and whether it holds the permission to act on
+// every user, it responds with an error itself when the check fails
+func (c *UserController) requester(ctx *gin.Context, permission string) (*domain.Principal, bool, bool) {
+	principal := domain.PrincipalFromContext(ctx.Request.Context())
+	allowed, err := c.Authorizer.HasPermission(ctx.Request.Context(), principal, permission)
+	if err != nil {
+		log.Println("[UserController][requester] Error in HasPermission: ", err)
+		ctx.JSON(http.StatusInternalServerError, domain.Response{Message: "Internal Server Error", Success: false})
+		return nil, false, false
+	}
+	return principal, allowed, true
+}
+
+// userPrincipal returns the caller identified by the bearer token, tokens issued to a client itself are
+// rejected as they do not identify a user
+func userPrincipal(ctx *gin.Context) (*domain.Principal, bool) {
+	principal := domain.PrincipalFromContext(ctx.Request.Context())
+	if principal == nil || principal.UserID == "" {
+		ctx.JSON(http.StatusForbidden, domain.Response{Message: "A user token is required", Success: false})
+		return nil, false
+	}
+	return principal, true
+}
+
+// loginFailed responds to a failed login, a user who may not log in gets 403 and a locked out login 429
+func loginFailed(ctx *gin.Context, err error) {
+	switch cerr.GetErrorCode(err) {
+	case cerr.TooManyRequestsErrorCode:
+		tooManyRequests(ctx, err)
+	case cerr.ForbiddenErrorCode:
+		ctx.JSON(http.StatusForbidden, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+	default:
+		ctx.JSON(http.StatusBadRequest, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+	}
+}
+
+// tooManyRequests responds to a login rejected by the lockout, Retry-After tells when to try again
+func tooManyRequests(ctx *gin.Context, err error) {
+	if retryAt, ok := cerr.GetErrorDetails(err).(time.Time); ok {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(time.Until(retryAt).Seconds()))))
+	}
+	ctx.JSON(http.StatusTooManyRequests, domain.Response{Message: cerr.GetErrorMessage(err), Success: false})
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/jwt"
+)
+
+type WellKnownController struct{}
+
+// JWKS godoc
+//
+//	@Summary		JSON Web Key Set
+//	@Description	Returns the public keys access tokens can be verified with, as described by RFC 7517
+//	@Produce		json
+//	@Success		200	{object}	jwt.JWKS	"JSON Web Key Set"
+//	@Router			/.well-known/jwks.json [get]
+//	@Tags			discovery
+func (c *WellKnownController) JWKS(ctx *gin.Context) {
+	// Verifiers may cache the key set for a short while
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, jwt.GetJWKS())
+}
+
+// OpenIDConfiguration godoc
+//
+//	@Summary		OpenID Connect discovery
+//	@Description	Returns the OpenID Connect provider metadata of this service
+//	@Produce		json
+//	@Success		200	{object}	domain.OpenIDConfiguration	"OpenID Provider Metadata"
+//	@Router			/.well-known/openid-configuration [get]
+//	@Tags			discovery
+func (c *WellKnownController) OpenIDConfiguration(ctx *gin.Context) {
+	issuer := strings.TrimSuffix(env.EnvConfig.JWTIssuer, "/")
+
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, domain.OpenIDConfiguration{
+		Issuer:                            env.EnvConfig.JWTIssuer,
+		AuthorizationEndpoint:             issuer + "/oauth/authorize",
+		TokenEndpoint:                     issuer + "/oauth/token",
+		IntrospectionEndpoint:             issuer + "/oauth/introspect",
+		UserinfoEndpoint:                  issuer + "/userinfo",
+		JwksURI:                           issuer + "/.well-known/jwks.json",
+		ScopesSupported:                   append(append([]string{}, domain.OAuthScopes...), domain.ScopeProfileWrite),
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code", "refresh_token", "client_credentials"},
+		SubjectTypesSupported:             []string{"public"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		IDTokenSigningAlgValuesSupported:  jwt.SigningAlgorithms(),
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "sid", "preferred_username", "name", "given_name", "family_name", "email", "email_verified", "phone_number", "locale", "zoneinfo", "picture", "updated_at", "amr", "tenant_id"},
+	})
+}
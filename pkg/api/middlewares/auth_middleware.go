@@ -1,14 +1,28 @@
 package middlewares
 
 import (
+	"context"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/common/jwt"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
 )
 
-// Function to ValidateToken takes the jwt token from the request header and checks the validity of the token
+var personalAccessTokenAuthenticator domain.PersonalAccessTokenAuthenticator
+
+// SetPersonalAccessTokenAuthenticator sets the authenticator ValidateToken checks personal access tokens with
+func SetPersonalAccessTokenAuthenticator(a domain.PersonalAccessTokenAuthenticator) {
+	personalAccessTokenAuthenticator = a
+}
+
+// Function to ValidateToken takes the jwt token or the personal access token from the request header, checks
+// the validity of the token and stores the caller it identifies in the request context, handlers read it with
+// domain.PrincipalFromContext
 func ValidateToken() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		// Get the jwt token from the request header
@@ -19,21 +33,39 @@ func ValidateToken() gin.HandlerFunc {
 			return
 		}
 
-		// Get the jwt secret from the environment variable
-		jwtSecret := env.EnvConfig.JWTSecretKey
-		if jwtSecret == "" {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"message": "jwt secret not set"})
+		// Validate the token and keep the caller it identifies for the handlers
+		principal, err := authenticate(ctx.Request.Context(), token)
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
 			ctx.Abort()
 			return
 		}
 
-		// Validate the token
-		err := jwt.ValidateToken(token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		// Tokens are only accepted in the tenant they were issued in, older tokens belong to the default tenant
+		tokenTenant := principal.TenantID
+		if tokenTenant == "" {
+			tokenTenant = tenant.Default()
+		}
+		if tokenTenant != tenant.ID(ctx.Request.Context()) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"message": "Token was issued for another tenant"})
 			ctx.Abort()
 			return
 		}
+		ctx.Request = ctx.Request.WithContext(domain.ContextWithPrincipal(ctx.Request.Context(), principal))
 		ctx.Next()
 	}
 }
+
+// authenticate identifies the caller of the Authorization header, personal access tokens are told apart from
+// JWTs by their prefix
+func authenticate(ctx context.Context, header string) (*domain.Principal, error) {
+	if token := jwt.BearerToken(header); strings.HasPrefix(token, models.PersonalAccessTokenPrefix) && personalAccessTokenAuthenticator != nil {
+		return personalAccessTokenAuthenticator.AuthenticatePersonalAccessToken(ctx, token)
+	}
+
+	claims, err := jwt.GetClaims(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPrincipal(claims, env.EnvConfig.JWTClaimNamespace), nil
+}
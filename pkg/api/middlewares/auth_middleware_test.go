@@ -0,0 +1,81 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/jwt"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
+)
+
+// tenantTokens authenticates every personal access token as a token of the tenant it names after the prefix
+type tenantTokens struct{}
+
+func (tenantTokens) AuthenticatePersonalAccessToken(ctx context.Context, token string) (*domain.Principal, error) {
+	return &domain.Principal{UserID: "user-1", TenantID: token[len(models.PersonalAccessTokenPrefix):], PersonalAccessTokenID: "pat-1"}, nil
+}
+
+func TestValidateTokenRejectsOtherTenants(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	env.EnvConfig.JWTSigningAlgorithm = "HS256"
+	env.EnvConfig.JWTSecretKey = "test-secret"
+	env.EnvConfig.JWTExpirationTime = "15"
+	if err := jwt.LoadSigningKey(); err != nil {
+		t.Fatalf("LoadSigningKey() error = %v", err)
+	}
+	SetPersonalAccessTokenAuthenticator(tenantTokens{})
+	defer SetPersonalAccessTokenAuthenticator(nil)
+
+	generate := func(claims map[string]interface{}) string {
+		token, err := jwt.GenerateToken("user-1", "session-1", claims)
+		if err != nil {
+			t.Fatalf("GenerateToken() error = %v", err)
+		}
+		return token
+	}
+	tenantAToken := generate(map[string]interface{}{"tenant_id": "tenant-a"})
+	legacyToken := generate(nil)
+
+	tests := []struct {
+		name   string
+		token  string
+		tenant string
+		want   int
+	}{
+		{name: "token of the tenant", token: tenantAToken, tenant: "tenant-a", want: http.StatusOK},
+		{name: "token of another tenant", token: tenantAToken, tenant: "tenant-b", want: http.StatusUnauthorized},
+		{name: "token of another tenant in the default tenant", token: tenantAToken, tenant: tenant.Default(), want: http.StatusUnauthorized},
+		{name: "token without tenant in the default tenant", token: legacyToken, tenant: tenant.Default(), want: http.StatusOK},
+		{name: "token without tenant in another tenant", token: legacyToken, tenant: "tenant-b", want: http.StatusUnauthorized},
+		{name: "personal access token of the tenant", token: models.PersonalAccessTokenPrefix + "tenant-a", tenant: "tenant-a", want: http.StatusOK},
+		{name: "personal access token of another tenant", token: models.PersonalAccessTokenPrefix + "tenant-a", tenant: "tenant-b", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/me", func(ctx *gin.Context) {
+				// Stands in for ResolveTenant
+				ctx.Request = ctx.Request.WithContext(tenant.NewContext(ctx.Request.Context(), tt.tenant))
+				ctx.Next()
+			}, ValidateToken(), func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			})
+
+			request := httptest.NewRequest(http.MethodGet, "/me", nil)
+			request.Header.Set("Authorization", "Bearer "+tt.token)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.want {
+				t.Errorf("ValidateToken() status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
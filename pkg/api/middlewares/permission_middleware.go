@@ -0,0 +1,82 @@
+package middlewares
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+)
+
+var authorizer domain.Authorizer
+
+// SetAuthorizer sets the authorizer RequirePermission checks permissions with
+func SetAuthorizer(a domain.Authorizer) {
+	authorizer = a
+}
+
+// Function to RequirePermission lets the request through only when the caller identified by ValidateToken holds
+// the permission, it must run after ValidateToken
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal := domain.PrincipalFromContext(ctx.Request.Context())
+		if principal == nil {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+			ctx.Abort()
+			return
+		}
+
+		allowed, err := authorizer.HasPermission(ctx.Request.Context(), principal, permission)
+		if err != nil {
+			log.Println("[RequirePermission] Error in HasPermission: ", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"message": "Internal Server Error"})
+			ctx.Abort()
+			return
+		}
+		if !allowed {
+			ctx.JSON(http.StatusForbidden, gin.H{"message": "Forbidden"})
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// Function to RequireSelfScope lets tokens issued to an OAuth client and personal access tokens change the account
+// of their user only when they were granted the profile:write scope, tokens issued to the user at login always
+// pass. It must run after ValidateToken.
+func RequireSelfScope() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal := domain.PrincipalFromContext(ctx.Request.Context())
+		if principal == nil {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+			ctx.Abort()
+			return
+		}
+		if !principal.CanManageSelf() {
+			ctx.JSON(http.StatusForbidden, gin.H{"message": "The token needs the " + domain.ScopeProfileWrite + " scope"})
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// Function to RejectPersonalAccessTokens keeps personal access tokens away from the routes guarding the account,
+// a leaked token must not be able to lock the user out. It must run after ValidateToken.
+func RejectPersonalAccessTokens() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal := domain.PrincipalFromContext(ctx.Request.Context())
+		if principal == nil {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+			ctx.Abort()
+			return
+		}
+		if principal.PersonalAccessTokenID != "" {
+			ctx.JSON(http.StatusForbidden, gin.H{"message": "Personal access tokens cannot be used here"})
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
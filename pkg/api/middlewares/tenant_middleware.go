@@ -0,0 +1,83 @@
+package middlewares
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
+)
+
+// tenantPathPrefix is the path prefix naming the tenant, /t/{tenant}/user/login is routed as /user/login
+const tenantPathPrefix = "/t/"
+
+// Function to StripTenantPath removes a /t/{tenant} prefix from the request path before it is routed and keeps
+// the tenant it names for ResolveTenant. It wraps the router as gin matches routes before any middleware runs.
+func StripTenantPath(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, tenantPathPrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		tenantID, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, tenantPathPrefix), "/")
+		if !tenant.Valid(tenantID) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		r = r.WithContext(tenant.NewContext(r.Context(), tenantID))
+		r.URL.Path = "/" + rest
+		r.URL.RawPath = ""
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Function to ResolveTenant stores the tenant of the request in the request context, it is taken from the
+// path, the X-Tenant-ID header or the subdomain of baseDomain in that order and defaults to DEFAULT_TENANT_ID
+func ResolveTenant(organizationUsecase domain.OrganizationUsecase, baseDomain string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tenantID, ok := tenant.FromContext(ctx.Request.Context())
+		if !ok {
+			tenantID = strings.ToLower(strings.TrimSpace(ctx.GetHeader(tenant.Header)))
+		}
+		if tenantID == "" {
+			tenantID = subdomainTenant(ctx.Request.Host, baseDomain)
+		}
+		if tenantID == "" {
+			tenantID = tenant.Default()
+		}
+
+		if err := organizationUsecase.ResolveTenant(ctx.Request.Context(), tenantID); err != nil {
+			if cerr.GetErrorCode(err) == cerr.NotFoundErrorCode {
+				ctx.JSON(http.StatusNotFound, domain.Response{Message: "Unknown tenant", Success: false})
+			} else {
+				ctx.JSON(http.StatusInternalServerError, domain.Response{Message: "Internal Server Error", Success: false})
+			}
+			ctx.Abort()
+			return
+		}
+
+		ctx.Request = ctx.Request.WithContext(tenant.NewContext(ctx.Request.Context(), tenantID))
+		ctx.Next()
+	}
+}
+
+// subdomainTenant returns the tenant named by the first label of host when host is a direct subdomain of baseDomain
+func subdomainTenant(host string, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	label, found := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(baseDomain))
+	if !found || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
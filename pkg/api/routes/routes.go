@@ -1,7 +1,12 @@
 package routes
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"time"
@@ -11,14 +16,22 @@ import (
 	"github.com/satyamvatstyagi/UserManagementService/pkg/api/controller"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/api/middlewares"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/app/config"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/app/repository"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/app/usecase"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/common/consts"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/jwt"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/logger"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/notifier"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/password"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/common/restclient"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.elastic.co/apm/module/apmgin/v2"
+	"golang.org/x/crypto/hkdf"
+	"gorm.io/gorm"
 )
 
 func Setup() {
@@ -27,6 +40,11 @@ func Setup() {
 		log.Fatalf("Loadconfig failed, err=%s", cerr.Error())
 	}
 
+	// Load the key tokens are signed with
+	if err := jwt.LoadSigningKey(); err != nil {
+		log.Fatalf("Loading signing key failed, err=%s", err.Error())
+	}
+
 	ginMode := env.EnvConfig.GinMode
 	gin.SetMode(ginMode)
 	router := gin.Default()
@@ -34,9 +52,9 @@ func Setup() {
 	// Set up routes
 	setupRoutes(router)
 
-	// Start the server
+	// Start the server, a /t/{tenant} path prefix is stripped before the router sees the request
 	port := env.EnvConfig.ServicePort
-	err := router.Run(":" + port)
+	err := http.ListenAndServe(":"+port, middlewares.StripTenantPath(router))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -72,21 +90,314 @@ func setupRoutes(router *gin.Engine) {
 
 	// Initialize the repository
 	userRepository := repository.NewUserRepository(db)
+	refreshTokenRepository := repository.NewRefreshTokenRepository(db)
+	revocationRepository := newRevocationRepository(db)
+	sessionRepository := repository.NewSessionRepository(db)
+	oauthClientRepository := repository.NewOAuthClientRepository(db)
+	authorizationCodeRepository := repository.NewAuthorizationCodeRepository(db)
+	roleRepository := repository.NewRoleRepository(db)
+	groupRepository := repository.NewGroupRepository(db)
+	organizationRepository := repository.NewOrganizationRepository(db)
+	oneTimeTokenRepository := repository.NewOneTimeTokenRepository(db)
+	loginAttemptRepository := repository.NewLoginAttemptRepository(db)
+	mfaRepository := repository.NewMFARepository(db)
+	otpCodeRepository := repository.NewOTPCodeRepository(db)
+	personalAccessTokenRepository := repository.NewPersonalAccessTokenRepository(db)
+
+	// Seed the organization of requests naming no tenant
+	tenant.SetDefault(env.EnvConfig.DefaultTenantID)
+	seedDefaultOrganization(organizationRepository, logger)
+
+	// Load the password policy
+	loadPasswordPolicy()
+
+	// Seed the permissions and the admin role
+	seedRoles(roleRepository, userRepository, logger)
+
+	// Load the key ring rotated keys are kept in
+	jwt.SetKeyStore(repository.NewSigningKeyRepository(db, loadSigningKeyEncryptionKey(logger)))
+	if err := jwt.ReloadSigningKeys(context.Background()); err != nil {
+		logger.Error(fmt.Sprintf("Loading signing keys failed, err=%s", err.Error()))
+	}
+	go reloadSigningKeys(logger)
+	jwt.SetRevocationStore(revocationRepository)
+	go pruneRevocations(revocationRepository, logger)
 
 	// Initialize the usecases
-	userUsecase := usecase.NewUserUsecase(userRepository, restHTTPClient)
+	claimsBuilder := usecase.NewClaimsBuilder(env.EnvConfig.JWTClaimNamespace, roleRepository, groupRepository, env.EnvConfig.JWTGroupsClaim)
+	authorizer := usecase.NewAuthorizer(roleRepository, env.EnvConfig.PermissionSource)
+	middlewares.SetAuthorizer(authorizer)
+	signingKeyUsecase := usecase.NewSigningKeyUsecase()
+	messageNotifier := newNotifier()
+	emailVerificationKey := loadEmailVerificationKey(logger)
+	userUsecase := usecase.NewUserUsecase(userRepository, refreshTokenRepository, revocationRepository, sessionRepository, loginAttemptRepository, mfaRepository, oneTimeTokenRepository, claimsBuilder, messageNotifier, emailVerificationKey, restHTTPClient)
+	oauthUsecase := usecase.NewOAuthUsecase(userRepository, refreshTokenRepository, revocationRepository, sessionRepository, oauthClientRepository, authorizationCodeRepository, loginAttemptRepository, mfaRepository, claimsBuilder)
+	roleUsecase := usecase.NewRoleUsecase(roleRepository, userRepository)
+	groupUsecase := usecase.NewGroupUsecase(groupRepository, userRepository)
+	organizationUsecase := usecase.NewOrganizationUsecase(organizationRepository)
+	passwordUsecase := usecase.NewPasswordUsecase(userRepository, refreshTokenRepository, revocationRepository, sessionRepository, loginAttemptRepository, oneTimeTokenRepository, messageNotifier)
+	mfaUsecase := usecase.NewMFAUsecase(userRepository, mfaRepository, loginAttemptRepository)
+	emailVerificationUsecase := usecase.NewEmailVerificationUsecase(userRepository, messageNotifier, emailVerificationKey)
+	personalAccessTokenUsecase := usecase.NewPersonalAccessTokenUsecase(userRepository, personalAccessTokenRepository, authorizer)
+	middlewares.SetPersonalAccessTokenAuthenticator(personalAccessTokenUsecase)
+	otpUsecase := usecase.NewOTPUsecase(userRepository, refreshTokenRepository, revocationRepository, sessionRepository, loginAttemptRepository, mfaRepository, otpCodeRepository, claimsBuilder, messageNotifier)
 
 	// Initialize the controller
-	userController := &controller.UserController{UserUsecase: userUsecase}
+	userController := &controller.UserController{UserUsecase: userUsecase, Authorizer: authorizer}
+	wellKnownController := &controller.WellKnownController{}
+	signingKeyController := &controller.SigningKeyController{SigningKeyUsecase: signingKeyUsecase}
+	oauthController := &controller.OAuthController{OAuthUsecase: oauthUsecase}
+	roleController := &controller.RoleController{RoleUsecase: roleUsecase}
+	groupController := &controller.GroupController{GroupUsecase: groupUsecase}
+	organizationController := &controller.OrganizationController{OrganizationUsecase: organizationUsecase}
+	passwordController := &controller.PasswordController{PasswordUsecase: passwordUsecase}
+	mfaController := &controller.MFAController{MFAUsecase: mfaUsecase}
+	otpController := &controller.OTPController{OTPUsecase: otpUsecase}
+	emailVerificationController := &controller.EmailVerificationController{EmailVerificationUsecase: emailVerificationUsecase}
+	personalAccessTokenController := &controller.PersonalAccessTokenController{PersonalAccessTokenUsecase: personalAccessTokenUsecase}
+
+	// Every route below is scoped to the tenant of the request
+	router.Use(middlewares.ResolveTenant(organizationUsecase, env.EnvConfig.TenantBaseDomain))
 
 	username := env.EnvConfig.BasicAuthUser
 	password := env.EnvConfig.BasicAuthPassword
 	router.GET("/user/health", middlewares.LoggingMiddleware(logger), userController.HealthCheck)
+	router.GET("/user/email/verify", middlewares.LoggingMiddleware(logger), emailVerificationController.VerifyEmail)
+	router.GET("/.well-known/jwks.json", middlewares.LoggingMiddleware(logger), wellKnownController.JWKS)
+	router.GET("/.well-known/openid-configuration", middlewares.LoggingMiddleware(logger), wellKnownController.OpenIDConfiguration)
+	router.GET("/userinfo", middlewares.LoggingMiddleware(logger), middlewares.ValidateToken(), userController.UserInfo)
+	router.POST("/userinfo", middlewares.LoggingMiddleware(logger), middlewares.ValidateToken(), userController.UserInfo)
 	userService := router.Group("/user", gin.BasicAuth(gin.Accounts{username: password}))
 	{
 		userService.POST("/register", middlewares.LoggingMiddleware(logger), userController.RegisterUser)
 		userService.POST("/login", middlewares.LoggingMiddleware(logger), userController.LoginUser)
-		userService.GET("/:username", middlewares.LoggingMiddleware(logger), userController.GetUserByUserName)
+		userService.POST("/login/mfa", middlewares.LoggingMiddleware(logger), userController.LoginMFA)
+		userService.POST("/otp/start", middlewares.LoggingMiddleware(logger), otpController.StartOTPLogin)
+		userService.POST("/otp/verify", middlewares.LoggingMiddleware(logger), otpController.VerifyOTPLogin)
+		userService.POST("/email/verify/resend", middlewares.LoggingMiddleware(logger), emailVerificationController.ResendVerificationEmail)
+		userService.POST("/token/refresh", middlewares.LoggingMiddleware(logger), userController.RefreshToken)
+		userService.POST("/logout", middlewares.LoggingMiddleware(logger), userController.Logout)
 		userService.POST("/validate-token", middlewares.LoggingMiddleware(logger), userController.ValidateToken)
+		userService.POST("/password/reset", middlewares.LoggingMiddleware(logger), passwordController.RequestPasswordReset)
+		userService.POST("/password/reset/confirm", middlewares.LoggingMiddleware(logger), passwordController.ResetPassword)
+	}
+
+	// Organization administration, restricted to the platform admins of the default tenant
+	organizationService := router.Group("/admin/organizations", middlewares.ValidateToken())
+	{
+		organizationService.GET("", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionOrganizationsRead), organizationController.GetOrganizations)
+		organizationService.POST("", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionOrganizationsWrite), organizationController.CreateOrganization)
+	}
+
+	// Signing key administration, restricted to the platform admins of the default tenant
+	keyService := router.Group("/admin/keys", middlewares.ValidateToken())
+	{
+		keyService.GET("", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionKeysRead), signingKeyController.GetSigningKeys)
+		keyService.POST("/rotate", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionKeysWrite), signingKeyController.RotateSigningKey)
+		keyService.POST("/:kid/retire", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionKeysWrite), signingKeyController.RetireSigningKey)
+	}
+
+	// OAuth client administration, restricted to the platform admins of the default tenant
+	clientService := router.Group("/admin/oauth/clients", middlewares.ValidateToken())
+	{
+		clientService.POST("", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionClientsWrite), oauthController.RegisterClient)
+	}
+
+	// Role administration, restricted by the permissions of the bearer token
+	roleService := router.Group("/admin/roles", middlewares.ValidateToken())
+	{
+		roleService.GET("", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionRolesRead), roleController.GetRoles)
+		roleService.POST("", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionRolesWrite), roleController.CreateRole)
+		roleService.GET("/:role/members", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionRolesRead), roleController.GetRoleMembers)
+		roleService.POST("/:role/members/:username", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionRolesWrite), roleController.GrantRole)
+		roleService.DELETE("/:role/members/:username", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionRolesWrite), roleController.RevokeRole)
+	}
+
+	// User administration, restricted by the permissions of the bearer token
+	adminUserService := router.Group("/admin/users", middlewares.ValidateToken())
+	{
+		adminUserService.POST("/:username/restore", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionUsersWrite), userController.RestoreUser)
+		adminUserService.POST("/:username/unlock", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionUsersWrite), userController.UnlockUser)
+		adminUserService.POST("/:username/sessions/:sid/revoke", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionUsersWrite), userController.RevokeSession)
+		adminUserService.POST("/:username/suspend", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionUsersWrite), userController.SuspendUser)
+		adminUserService.POST("/:username/lock", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionUsersWrite), userController.LockUser)
+		adminUserService.POST("/:username/deactivate", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionUsersWrite), userController.DeactivateUser)
+		adminUserService.POST("/:username/reactivate", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionUsersWrite), userController.ReactivateUser)
+	}
+
+	// OAuth 2.0 endpoints, clients authenticate themselves instead of using the basic auth account
+	oauthService := router.Group("/oauth")
+	{
+		oauthService.GET("/authorize", middlewares.LoggingMiddleware(logger), oauthController.Authorize)
+		oauthService.POST("/authorize", middlewares.LoggingMiddleware(logger), oauthController.AuthorizeLogin)
+		oauthService.POST("/token", middlewares.LoggingMiddleware(logger), oauthController.Token)
+		oauthService.POST("/introspect", middlewares.LoggingMiddleware(logger), oauthController.Introspect)
+	}
+
+	// Routes for the caller identified by the bearer token
+	bearerService := router.Group("/user", middlewares.ValidateToken())
+	{
+		bearerService.GET("", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionUsersRead), userController.ListUsers)
+		bearerService.GET("/me", middlewares.LoggingMiddleware(logger), userController.GetMe)
+		bearerService.GET("/me/sessions", middlewares.LoggingMiddleware(logger), userController.GetSessions)
+		bearerService.DELETE("/me/sessions/:sid", middlewares.LoggingMiddleware(logger), middlewares.RequireSelfScope(), userController.EndSession)
+		bearerService.GET("/me/groups", middlewares.LoggingMiddleware(logger), groupController.GetMyGroups)
+		bearerService.POST("/me/password", middlewares.LoggingMiddleware(logger), middlewares.RequireSelfScope(), passwordController.ChangePassword)
+		bearerService.POST("/me/mfa/totp", middlewares.LoggingMiddleware(logger), middlewares.RejectPersonalAccessTokens(), middlewares.RequireSelfScope(), mfaController.EnrollTOTP)
+		bearerService.POST("/me/mfa/totp/confirm", middlewares.LoggingMiddleware(logger), middlewares.RejectPersonalAccessTokens(), middlewares.RequireSelfScope(), mfaController.ConfirmTOTP)
+		bearerService.DELETE("/me/mfa/totp", middlewares.LoggingMiddleware(logger), middlewares.RejectPersonalAccessTokens(), middlewares.RequireSelfScope(), mfaController.DisableTOTP)
+		bearerService.GET("/me/tokens", middlewares.LoggingMiddleware(logger), middlewares.RejectPersonalAccessTokens(), personalAccessTokenController.GetPersonalAccessTokens)
+		bearerService.POST("/me/tokens", middlewares.LoggingMiddleware(logger), middlewares.RejectPersonalAccessTokens(), middlewares.RequireSelfScope(), personalAccessTokenController.CreatePersonalAccessToken)
+		bearerService.DELETE("/me/tokens/:id", middlewares.LoggingMiddleware(logger), middlewares.RejectPersonalAccessTokens(), middlewares.RequireSelfScope(), personalAccessTokenController.RevokePersonalAccessToken)
+		bearerService.GET("/:username", middlewares.LoggingMiddleware(logger), userController.GetUserByUserName)
+		bearerService.PATCH("/:username", middlewares.LoggingMiddleware(logger), userController.UpdateUser)
+		bearerService.PUT("/:username/username", middlewares.LoggingMiddleware(logger), userController.ChangeUserName)
+		bearerService.DELETE("/:username", middlewares.LoggingMiddleware(logger), userController.DeleteUser)
+		bearerService.GET("/:username/groups", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionGroupsRead), groupController.GetUserGroups)
+		bearerService.GET("/groups", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionGroupsRead), groupController.GetGroups)
+		bearerService.POST("/groups", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionGroupsWrite), groupController.CreateGroup)
+		bearerService.GET("/groups/:group", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionGroupsRead), groupController.GetGroup)
+		bearerService.PATCH("/groups/:group", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionGroupsWrite), groupController.UpdateGroup)
+		bearerService.DELETE("/groups/:group", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionGroupsWrite), groupController.DeleteGroup)
+		bearerService.POST("/groups/:group/members/:username", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionGroupsWrite), groupController.AddMember)
+		bearerService.DELETE("/groups/:group/members/:username", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionGroupsWrite), groupController.RemoveMember)
+		bearerService.POST("/groups/:group/subgroups/:subgroup", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionGroupsWrite), groupController.AddSubgroup)
+		bearerService.DELETE("/groups/:group/subgroups/:subgroup", middlewares.LoggingMiddleware(logger), middlewares.RequirePermission(models.PermissionGroupsWrite), groupController.RemoveSubgroup)
+	}
+}
+
+// newRevocationRepository picks the revocation store configured by REVOCATION_STORE
+func newRevocationRepository(db *gorm.DB) models.RevocationRepository {
+	if env.EnvConfig.RevocationStore == "memory" {
+		return repository.NewMemoryRevocationRepository()
+	}
+	return repository.NewRevocationRepository(db)
+}
+
+// newNotifier picks the notifier configured by NOTIFIER
+func newNotifier() notifier.Notifier {
+	switch env.EnvConfig.Notifier {
+	case "file":
+		return notifier.NewFileNotifier(env.EnvConfig.NotifierFilePath)
+	case "gateway":
+		email := notifier.NewSMTPNotifier(env.EnvConfig.SMTPAddr, env.EnvConfig.SMTPUserName, env.EnvConfig.SMTPPassword, env.EnvConfig.SMTPFrom)
+		sms := notifier.NewSMSGatewayNotifier(env.EnvConfig.SMSGatewayURL, env.EnvConfig.SMSGatewayToken)
+		return notifier.NewChannelNotifier(email, sms)
+	}
+	return notifier.NewLogNotifier()
+}
+
+// loadPasswordPolicy sets the password policy configured by the PASSWORD_ settings
+func loadPasswordPolicy() {
+	policy, err := password.NewPolicy(env.EnvConfig.PasswordMinLength, env.EnvConfig.PasswordMaxLength, env.EnvConfig.PasswordCharacterClasses, env.EnvConfig.PasswordRejectUserName, env.EnvConfig.PasswordDenyListPath)
+	if err != nil {
+		log.Fatalf("Loading password policy failed, err=%s", err.Error())
+	}
+	password.SetPolicy(policy)
+}
+
+// loadSigningKeyEncryptionKey returns the AES key rotated signing keys are encrypted with in the database, the
+// base64 encoded SIGNING_KEY_ENCRYPTION_KEY. Without it keys cannot be rotated.
+func loadSigningKeyEncryptionKey(appLogger logger.Logger) []byte {
+	if env.EnvConfig.SigningKeyEncryptionKey == "" {
+		appLogger.Warning("SIGNING_KEY_ENCRYPTION_KEY is not set, signing keys cannot be rotated")
+		return nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(env.EnvConfig.SigningKeyEncryptionKey)
+	if err != nil || len(key) != 32 {
+		log.Fatalf("SIGNING_KEY_ENCRYPTION_KEY must be 32 base64 encoded bytes")
+	}
+	return key
+}
+
+// emailVerificationKeyLabel separates the email verification key derived from JWT_SECRET_KEY from the JWT key itself
+const emailVerificationKeyLabel = "UserManagementService email-verification"
+
+// loadEmailVerificationKey returns the key verification links are signed with, EMAIL_VERIFICATION_SECRET or else
+// a key derived from JWT_SECRET_KEY with HKDF. Without either a random key is used, the links then stop working on a restart.
+func loadEmailVerificationKey(appLogger logger.Logger) []byte {
+	if env.EnvConfig.EmailVerificationSecret != "" {
+		return []byte(env.EnvConfig.EmailVerificationSecret)
+	}
+
+	key := make([]byte, 32)
+	if env.EnvConfig.JWTSecretKey != "" {
+		if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(env.EnvConfig.JWTSecretKey), nil, []byte(emailVerificationKeyLabel)), key); err != nil {
+			log.Fatalf("Deriving email verification key failed, err=%s", err.Error())
+		}
+		appLogger.Warning("EMAIL_VERIFICATION_SECRET is not set, email verification links are signed with a key derived from JWT_SECRET_KEY")
+		return key
+	}
+
+	if _, err := rand.Read(key); err != nil {
+		log.Fatalf("Generating email verification key failed, err=%s", err.Error())
+	}
+	appLogger.Warning("Neither EMAIL_VERIFICATION_SECRET nor JWT_SECRET_KEY is set, email verification links will not survive a restart")
+	return key
+}
+
+// seedDefaultOrganization makes sure the organization of DEFAULT_TENANT_ID exists
+func seedDefaultOrganization(organizationRepository models.OrganizationRepository, appLogger logger.Logger) {
+	if !tenant.Valid(env.EnvConfig.DefaultTenantID) {
+		log.Fatalf("Invalid DEFAULT_TENANT_ID %q", env.EnvConfig.DefaultTenantID)
+	}
+
+	organization := &models.Organization{TenantID: env.EnvConfig.DefaultTenantID, Name: env.EnvConfig.DefaultTenantID}
+	if err := organizationRepository.EnsureOrganization(context.Background(), organization); err != nil {
+		appLogger.Error(fmt.Sprintf("Seeding default organization failed, err=%s", err.Error()))
+	}
+}
+
+// seedRoles seeds the permissions and the admin role, and grants the admin role to ADMIN_USER_NAME when that user exists
+func seedRoles(roleRepository models.RoleRepository, userRepository models.UserRepository, appLogger logger.Logger) {
+	ctx := context.Background()
+	if err := roleRepository.SeedRoles(ctx, models.DefaultPermissions, models.RoleAdmin); err != nil {
+		appLogger.Error(fmt.Sprintf("Seeding roles failed, err=%s", err.Error()))
+		return
+	}
+
+	if env.EnvConfig.AdminUserName == "" {
+		return
+	}
+	user, err := userRepository.GetUserByUserName(ctx, env.EnvConfig.AdminUserName)
+	if err != nil {
+		appLogger.Error(fmt.Sprintf("Admin user %s not found, err=%s", env.EnvConfig.AdminUserName, err.Error()))
+		return
+	}
+	role, err := roleRepository.GetRoleByName(ctx, models.RoleAdmin)
+	if err != nil {
+		appLogger.Error(fmt.Sprintf("Fetching admin role failed, err=%s", err.Error()))
+		return
+	}
+	if err := roleRepository.GrantRole(ctx, user.UUID, role); err != nil {
+		appLogger.Error(fmt.Sprintf("Granting admin role failed, err=%s", err.Error()))
+	}
+}
+
+// pruneRevocations periodically drops revocation entries for tokens that have already expired
+func pruneRevocations(revocationRepository models.RevocationRepository, appLogger logger.Logger) {
+	ticker := time.NewTicker(consts.PurgeTime)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		pruned, err := revocationRepository.PruneExpired(context.Background())
+		if err != nil {
+			appLogger.Error(fmt.Sprintf("Pruning revoked tokens failed, err=%s", err.Error()))
+			continue
+		}
+		appLogger.Info(fmt.Sprintf("Pruned %d expired revoked tokens", pruned))
+	}
+}
+
+// reloadSigningKeys periodically reloads the key ring so keys rotated by other instances are picked up
+func reloadSigningKeys(appLogger logger.Logger) {
+	ticker := time.NewTicker(consts.PurgeTime)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := jwt.ReloadSigningKeys(context.Background()); err != nil {
+			appLogger.Error(fmt.Sprintf("Reloading signing keys failed, err=%s", err.Error()))
+		}
 	}
 }
@@ -26,10 +26,19 @@ func (c *Config) InitDb() *gorm.DB {
 		log.Println("Error connecting to database: ", err)
 	}
 
-	err = db.AutoMigrate(&models.User{})
+	err = db.AutoMigrate(&models.User{}, &models.RefreshToken{}, &models.RevokedToken{}, &models.Session{}, &models.SigningKey{}, &models.OAuthClient{}, &models.AuthorizationCode{}, &models.Permission{}, &models.Role{}, &models.UserRole{}, &models.Group{}, &models.GroupMember{}, &models.GroupNesting{}, &models.Organization{}, &models.OneTimeToken{}, &models.LoginAttempt{}, &models.MFAFactor{}, &models.RecoveryCode{}, &models.OTPCode{}, &models.PersonalAccessToken{})
 	if err != nil {
 		connect = false
 		log.Println("Error migrating database: ", err)
+	} else if err := migrateUserTenants(db); err != nil {
+		connect = false
+		log.Println("Error migrating users to tenants: ", err)
+	} else if err := migrateGroupTenants(db); err != nil {
+		connect = false
+		log.Println("Error migrating groups to tenants: ", err)
+	} else if err := migrateRoleTenants(db); err != nil {
+		connect = false
+		log.Println("Error migrating roles to tenants: ", err)
 	}
 
 	if connect {
@@ -41,6 +50,45 @@ func (c *Config) InitDb() *gorm.DB {
 	return db
 }
 
+// migrateUserTenants moves users created before organizations existed into the default tenant and drops the
+// index that kept usernames globally unique, they are unique per tenant now
+func migrateUserTenants(db *gorm.DB) error {
+	if err := db.Unscoped().Model(&models.User{}).Where("tenant_id = ''").Update("tenant_id", env.EnvConfig.DefaultTenantID).Error; err != nil {
+		return err
+	}
+
+	if db.Migrator().HasIndex(&models.User{}, "idx_user_uuid") {
+		return db.Migrator().DropIndex(&models.User{}, "idx_user_uuid")
+	}
+	return nil
+}
+
+// migrateGroupTenants moves groups created before they were scoped to tenants into the default tenant and drops
+// the index that kept group names globally unique
+func migrateGroupTenants(db *gorm.DB) error {
+	if err := db.Unscoped().Model(&models.Group{}).Where("tenant_id = ''").Update("tenant_id", env.EnvConfig.DefaultTenantID).Error; err != nil {
+		return err
+	}
+
+	if db.Migrator().HasIndex(&models.Group{}, "idx_groups_name") {
+		return db.Migrator().DropIndex(&models.Group{}, "idx_groups_name")
+	}
+	return nil
+}
+
+// migrateRoleTenants moves custom roles created before they were scoped to tenants into the default tenant and
+// drops the index that kept role names globally unique, the seeded admin role stays shared by every tenant
+func migrateRoleTenants(db *gorm.DB) error {
+	if err := db.Unscoped().Model(&models.Role{}).Where("tenant_id = '' AND name <> ?", models.RoleAdmin).Update("tenant_id", env.EnvConfig.DefaultTenantID).Error; err != nil {
+		return err
+	}
+
+	if db.Migrator().HasIndex(&models.Role{}, "idx_roles_name") {
+		return db.Migrator().DropIndex(&models.Role{}, "idx_roles_name")
+	}
+	return nil
+}
+
 // Function to initialize the logger
 func (c *Config) InitLogger() logger.Logger {
 
@@ -0,0 +1,12 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+)
+
+// ClaimsBuilder builds the claims describing a user that are embedded in the tokens issued for them
+type ClaimsBuilder interface {
+	Build(ctx context.Context, user *models.User, scopes []string) (claims map[string]interface{}, err error)
+}
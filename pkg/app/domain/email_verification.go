@@ -0,0 +1,17 @@
+package domain
+
+import "context"
+
+type EmailVerificationUsecase interface {
+	VerifyEmail(ctx context.Context, verifyEmailRequest *VerifyEmailRequest) (err error)
+	ResendVerificationEmail(ctx context.Context, resendVerificationEmailRequest *ResendVerificationEmailRequest) (err error)
+}
+
+type VerifyEmailRequest struct {
+	// Token is the signed token of the verification link
+	Token string `form:"token" binding:"required"`
+}
+
+type ResendVerificationEmailRequest struct {
+	Email string `json:"email" binding:"required"`
+}
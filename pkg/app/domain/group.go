@@ -0,0 +1,70 @@
+package domain
+
+import "context"
+
+type GroupUsecase interface {
+	CreateGroup(ctx context.Context, createGroupRequest *CreateGroupRequest) (groupResponse *GroupResponse, err error)
+	GetGroups(ctx context.Context) (getGroupsResponse *GetGroupsResponse, err error)
+	GetGroup(ctx context.Context, groupRequest *GroupRequest) (getGroupResponse *GetGroupResponse, err error)
+	UpdateGroup(ctx context.Context, updateGroupRequest *UpdateGroupRequest) (groupResponse *GroupResponse, err error)
+	DeleteGroup(ctx context.Context, groupRequest *GroupRequest) (err error)
+	AddMember(ctx context.Context, groupMemberRequest *GroupMemberRequest) (err error)
+	RemoveMember(ctx context.Context, groupMemberRequest *GroupMemberRequest) (err error)
+	AddSubgroup(ctx context.Context, subgroupRequest *SubgroupRequest) (err error)
+	RemoveSubgroup(ctx context.Context, subgroupRequest *SubgroupRequest) (err error)
+	GetEffectiveGroups(ctx context.Context, effectiveGroupsRequest *EffectiveGroupsRequest) (getGroupsResponse *GetGroupsResponse, err error)
+}
+
+type CreateGroupRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type GroupRequest struct {
+	GroupName string `uri:"group" binding:"required"`
+}
+
+type UpdateGroupRequest struct {
+	GroupName   string `uri:"group" json:"-" binding:"required"`
+	Description string `json:"description"`
+}
+
+type GroupMemberRequest struct {
+	GroupName string `uri:"group" binding:"required"`
+	UserName  string `uri:"username" binding:"required"`
+}
+
+type SubgroupRequest struct {
+	GroupName    string `uri:"group" binding:"required"`
+	SubgroupName string `uri:"subgroup" binding:"required"`
+}
+
+// EffectiveGroupsRequest names the user either by id, for the caller, or by username
+type EffectiveGroupsRequest struct {
+	UserID   string
+	UserName string `uri:"username"`
+}
+
+type GroupResponse struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type GetGroupsResponse struct {
+	Groups []GroupResponse `json:"groups"`
+}
+
+// GetGroupResponse describes a group with its direct members and subgroups
+type GetGroupResponse struct {
+	GroupResponse
+	Members   []GroupMemberResponse `json:"members"`
+	Subgroups []GroupResponse       `json:"subgroups"`
+}
+
+type GroupMemberResponse struct {
+	ID       string `json:"id"`
+	UserName string `json:"user_name"`
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "context"
+
+type MFAUsecase interface {
+	EnrollTOTP(ctx context.Context, enrollTOTPRequest *EnrollTOTPRequest) (enrollTOTPResponse *EnrollTOTPResponse, err error)
+	ConfirmTOTP(ctx context.Context, confirmTOTPRequest *ConfirmTOTPRequest) (confirmTOTPResponse *ConfirmTOTPResponse, err error)
+	DisableTOTP(ctx context.Context, disableTOTPRequest *DisableTOTPRequest) (err error)
+}
+
+type EnrollTOTPRequest struct {
+	UserID string
+}
+
+// EnrollTOTPResponse holds the new secret, the otpauth URI carries it as well and is shown as a QR code
+type EnrollTOTPResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type ConfirmTOTPRequest struct {
+	// Code is the first code generated by the authenticator app
+	Code   string `json:"code" binding:"required"`
+	UserID string `json:"-"`
+}
+
+// ConfirmTOTPResponse holds the recovery codes, they are only shown once
+type ConfirmTOTPResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type DisableTOTPRequest struct {
+	// Code is a code of the authenticator app or a recovery code
+	Code     string `json:"code" binding:"required"`
+	UserID   string `json:"-"`
+	ClientIP string `json:"-"`
+}
+
+// LoginMFARequest is the second step of a login of a user with MFA enabled
+type LoginMFARequest struct {
+	// MFAToken is the challenge returned by the first step
+	MFAToken string `json:"mfa_token" binding:"required"`
+	// Code is a code of the authenticator app or a recovery code
+	Code      string `json:"code" binding:"required"`
+	UserAgent string `json:"-"`
+	ClientIP  string `json:"-"`
+}
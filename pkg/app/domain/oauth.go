@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/restclient"
+)
+
+// OAuthScopes are the scopes clients can be allowed to request
+var OAuthScopes = []string{"openid", "profile"}
+
+// ScopeProfileWrite lets tokens issued to an OAuth client and personal access tokens change the account of their
+// user, such as its profile, sessions and personal access tokens. User facing clients are not allowed it by default.
+const ScopeProfileWrite = "profile:write"
+
+// Error codes of the OAuth endpoints, as described by RFC 6749
+const (
+	OAuthErrorInvalidRequest          = "invalid_request"
+	OAuthErrorInvalidClient           = "invalid_client"
+	OAuthErrorInvalidGrant            = "invalid_grant"
+	OAuthErrorUnauthorizedClient      = "unauthorized_client"
+	OAuthErrorUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthErrorUnsupportedResponseType = "unsupported_response_type"
+	OAuthErrorInvalidScope            = "invalid_scope"
+	OAuthErrorAccessDenied            = "access_denied"
+	OAuthErrorServerError             = "server_error"
+)
+
+type OAuthUsecase interface {
+	RegisterClient(ctx context.Context, registerClientRequest *RegisterClientRequest) (registerClientResponse *RegisterClientResponse, err error)
+	ValidateAuthorizeRequest(ctx context.Context, authorizeRequest *AuthorizeRequest) (authorizeResponse *AuthorizeResponse, err error)
+	Authorize(ctx context.Context, authorizeLoginRequest *AuthorizeLoginRequest) (authorizeResponse *AuthorizeResponse, err error)
+	Token(ctx context.Context, tokenRequest *TokenRequest) (tokenResponse *TokenResponse, err error)
+	Introspect(ctx context.Context, introspectRequest *IntrospectRequest) (introspectResponse *IntrospectResponse, err error)
+}
+
+// OAuthError is the error response of the OAuth endpoints
+type OAuthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func NewOAuthError(code string, description string) *OAuthError {
+	return &OAuthError{
+		Code:        code,
+		Description: description,
+	}
+}
+
+type RegisterClientRequest struct {
+	Name string `json:"name" binding:"required"`
+	// RedirectURIs are required unless the client only uses the client credentials grant
+	RedirectURIs []string `json:"redirect_uris"`
+	// Scopes is the allow-list of scopes the client may request, OAuthScopes or permissions of the service. User
+	// facing clients default to OAuthScopes
+	Scopes []string `json:"scopes"`
+	// GrantTypes defaults to authorization_code and refresh_token
+	GrantTypes []string `json:"grant_types"`
+	// FirstParty clients are not asked for consent
+	FirstParty bool `json:"first_party"`
+	// Public clients such as SPAs and mobile apps get no secret and rely on PKCE alone
+	Public bool `json:"public"`
+}
+
+type RegisterClientResponse struct {
+	ClientID string `json:"client_id"`
+	// ClientSecret is only returned once, when the client is registered
+	ClientSecret string   `json:"client_secret,omitempty"`
+	Name         string   `json:"name"`
+	RedirectURIs []string `json:"redirect_uris"`
+	Scopes       []string `json:"scopes"`
+	GrantTypes   []string `json:"grant_types"`
+	FirstParty   bool     `json:"first_party"`
+	Public       bool     `json:"public"`
+}
+
+// AuthorizeRequest holds the parameters of an authorization request, PKCE with S256 is mandatory
+type AuthorizeRequest struct {
+	ResponseType        string `form:"response_type"`
+	ClientID            string `form:"client_id" binding:"required"`
+	RedirectURI         string `form:"redirect_uri" binding:"required"`
+	Scope               string `form:"scope"`
+	State               string `form:"state"`
+	Nonce               string `form:"nonce"`
+	CodeChallenge       string `form:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method"`
+}
+
+// AuthorizeLoginRequest is the login form posted back to the authorization endpoint
+type AuthorizeLoginRequest struct {
+	AuthorizeRequest
+	UserName string `form:"username"`
+	Password string `form:"password"`
+	// MFACode is a code of the authenticator app or a recovery code, it is only needed when MFA is enabled
+	MFACode string `form:"mfa_code"`
+	// Consent is approve or deny, it is only asked for from clients that are not first party
+	Consent   string `form:"consent"`
+	UserAgent string `form:"-"`
+	ClientIP  string `form:"-"`
+}
+
+// AuthorizeResponse either redirects back to the client or asks the user to log in
+type AuthorizeResponse struct {
+	RedirectURL string
+	Prompt      *AuthorizePrompt
+}
+
+// AuthorizePrompt describes the login form shown to the user
+type AuthorizePrompt struct {
+	ClientName string
+	FirstParty bool
+	Scopes     []string
+	Error      string
+}
+
+type TokenRequest struct {
+	GrantType    string `form:"grant_type" binding:"required"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+// TokenResponse is the response of the token endpoint, it extends the bearer token response we consume
+// from other systems with the refresh token and the ID token. Client credentials tokens carry neither.
+type TokenResponse struct {
+	restclient.BearerTokenResponse
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+}
+
+// IntrospectRequest asks about an access or refresh token, the caller authenticates as a confidential client
+type IntrospectRequest struct {
+	Token         string `form:"token" binding:"required"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+// IntrospectResponse is the introspection response of RFC 7662, only active is set for inactive tokens
+type IntrospectResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Aud       string `json:"aud,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+	Sid       string `json:"sid,omitempty"`
+	TenantID  string `json:"tenant_id,omitempty"`
+}
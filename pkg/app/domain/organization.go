@@ -0,0 +1,25 @@
+package domain
+
+import "context"
+
+type OrganizationUsecase interface {
+	CreateOrganization(ctx context.Context, createOrganizationRequest *CreateOrganizationRequest) (organizationResponse *OrganizationResponse, err error)
+	GetOrganizations(ctx context.Context) (getOrganizationsResponse *GetOrganizationsResponse, err error)
+	ResolveTenant(ctx context.Context, tenantID string) (err error)
+}
+
+type CreateOrganizationRequest struct {
+	// TenantID is used as subdomain and path segment, lowercase letters, digits and dashes only
+	TenantID string `json:"tenant_id" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+}
+
+type OrganizationResponse struct {
+	TenantID  string `json:"tenant_id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+}
+
+type GetOrganizationsResponse struct {
+	Organizations []OrganizationResponse `json:"organizations"`
+}
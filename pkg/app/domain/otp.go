@@ -0,0 +1,25 @@
+package domain
+
+import "context"
+
+type OTPUsecase interface {
+	StartOTPLogin(ctx context.Context, startOTPLoginRequest *StartOTPLoginRequest) (err error)
+	VerifyOTPLogin(ctx context.Context, verifyOTPLoginRequest *VerifyOTPLoginRequest) (loginUserResponse *LoginUserResponse, err error)
+}
+
+// StartOTPLoginRequest names the user by email address or phone number, the code is sent there
+type StartOTPLoginRequest struct {
+	Email       string `json:"email"`
+	PhoneNumber string `json:"phone_number"`
+}
+
+type VerifyOTPLoginRequest struct {
+	// Email or PhoneNumber is the one the code was sent to
+	Email       string `json:"email"`
+	PhoneNumber string `json:"phone_number"`
+	Code        string `json:"code" binding:"required"`
+	// MFACode is a code of the authenticator app or a recovery code, it is only needed when MFA is enabled
+	MFACode   string `json:"mfa_code"`
+	UserAgent string `json:"-"`
+	ClientIP  string `json:"-"`
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "context"
+
+type PasswordUsecase interface {
+	ChangePassword(ctx context.Context, changePasswordRequest *ChangePasswordRequest) (err error)
+	RequestPasswordReset(ctx context.Context, passwordResetRequest *PasswordResetRequest) (err error)
+	ResetPassword(ctx context.Context, confirmPasswordResetRequest *ConfirmPasswordResetRequest) (err error)
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+	UserID          string `json:"-"`
+	ClientIP        string `json:"-"`
+	// SessionID is the session the password is changed from, it is the only session kept
+	SessionID string `json:"-"`
+}
+
+type PasswordResetRequest struct {
+	UserName string `json:"user_name" binding:"required"`
+}
+
+type ConfirmPasswordResetRequest struct {
+	// Token is the reset token sent to the user
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
@@ -0,0 +1,57 @@
+package domain
+
+import "context"
+
+type PersonalAccessTokenUsecase interface {
+	PersonalAccessTokenAuthenticator
+	CreatePersonalAccessToken(ctx context.Context, createPersonalAccessTokenRequest *CreatePersonalAccessTokenRequest) (createPersonalAccessTokenResponse *CreatePersonalAccessTokenResponse, err error)
+	GetPersonalAccessTokens(ctx context.Context, getPersonalAccessTokensRequest *GetPersonalAccessTokensRequest) (getPersonalAccessTokensResponse *GetPersonalAccessTokensResponse, err error)
+	RevokePersonalAccessToken(ctx context.Context, revokePersonalAccessTokenRequest *RevokePersonalAccessTokenRequest) (err error)
+}
+
+// PersonalAccessTokenAuthenticator identifies the caller of a personal access token, the bearer auth middleware
+// uses it for Authorization headers holding one instead of a JWT
+type PersonalAccessTokenAuthenticator interface {
+	AuthenticatePersonalAccessToken(ctx context.Context, token string) (principal *Principal, err error)
+}
+
+type CreatePersonalAccessTokenRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	// Scopes are the permissions the token is limited to, the user must hold each of them. profile:write lets the
+	// token change the profile of the user and end their sessions.
+	Scopes []string `json:"scopes" binding:"max=20"`
+	// ExpiresInDays is left out for a token that does not expire
+	ExpiresInDays int `json:"expires_in_days" binding:"omitempty,min=1,max=3650"`
+	// Requester is the caller creating the token
+	Requester *Principal `json:"-"`
+}
+
+// CreatePersonalAccessTokenResponse holds the token, it is only shown once
+type CreatePersonalAccessTokenResponse struct {
+	Token string `json:"token"`
+	PersonalAccessTokenResponse
+}
+
+type GetPersonalAccessTokensRequest struct {
+	UserID string
+}
+
+type GetPersonalAccessTokensResponse struct {
+	Tokens []PersonalAccessTokenResponse `json:"tokens"`
+}
+
+// PersonalAccessTokenResponse describes a token, Prefix is the start of the token
+type PersonalAccessTokenResponse struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  string   `json:"expires_at,omitempty"`
+	LastUsedAt string   `json:"last_used_at,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+type RevokePersonalAccessTokenRequest struct {
+	TokenID string `uri:"id" binding:"required"`
+	UserID  string
+}
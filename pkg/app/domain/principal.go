@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"context"
+	"strings"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/consts"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/utils"
+)
+
+// Principal is the caller identified by a bearer token
+type Principal struct {
+	// UserID is empty for tokens issued to a client itself with the client credentials grant
+	UserID    string
+	SessionID string
+	ClientID  string
+	// TenantID is empty for tokens issued before organizations existed
+	TenantID string
+	Scopes   []string
+	Roles    []string
+	// Permissions is nil when the token carries no permissions claim
+	Permissions []string
+	Claims      map[string]interface{}
+	// PersonalAccessTokenID is set when the caller presented a personal access token, its Scopes limit the
+	// permissions of the user then
+	PersonalAccessTokenID string
+}
+
+// NewPrincipal builds the principal from validated token claims, roles and permissions are read from the namespaced claims
+func NewPrincipal(claims map[string]interface{}, namespace string) *Principal {
+	principal := &Principal{Claims: claims}
+
+	principal.ClientID, _ = claims["client_id"].(string)
+	principal.SessionID, _ = claims["sid"].(string)
+	principal.TenantID, _ = claims["tenant_id"].(string)
+	if gty, _ := claims["gty"].(string); gty != "client_credentials" {
+		principal.UserID, _ = claims["sub"].(string)
+	}
+	if scope, ok := claims["scope"].(string); ok {
+		principal.Scopes = strings.Fields(scope)
+	}
+	principal.Roles = stringsClaim(claims[namespace+"roles"])
+	principal.Permissions = stringsClaim(claims[namespace+"permissions"])
+
+	return principal
+}
+
+// HasScope reports whether the token was granted the scope
+func (p *Principal) HasScope(scope string) bool {
+	return utils.Contains(p.Scopes, scope)
+}
+
+// IsDelegated reports whether the token was issued to an OAuth client or is a personal access token, rather than
+// issued to the user at login
+func (p *Principal) IsDelegated() bool {
+	return p.ClientID != "" || p.PersonalAccessTokenID != ""
+}
+
+// CanManageSelf reports whether the token may change the account of its user, delegated tokens need the
+// profile:write scope
+func (p *Principal) CanManageSelf() bool {
+	return !p.IsDelegated() || p.HasScope(ScopeProfileWrite)
+}
+
+// HasRole reports whether the caller holds the role
+func (p *Principal) HasRole(role string) bool {
+	return utils.Contains(p.Roles, role)
+}
+
+// HasPermission reports whether the token carries the permission, see Authorizer for the full check
+func (p *Principal) HasPermission(permission string) bool {
+	return utils.Contains(p.Permissions, permission)
+}
+
+// ContextWithPrincipal returns a copy of ctx carrying the principal
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, consts.PrincipalContext, principal)
+}
+
+// PrincipalFromContext returns the principal stored by the bearer auth middleware, or nil
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(consts.PrincipalContext).(*Principal)
+	return principal
+}
+
+// stringsClaim reads a claim holding a list of strings, decoded JSON arrays come back as []interface{}
+func stringsClaim(value interface{}) []string {
+	switch values := value.(type) {
+	case []string:
+		return values
+	case []interface{}:
+		result := make([]string, 0, len(values))
+		for _, v := range values {
+			if s, ok := v.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
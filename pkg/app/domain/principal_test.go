@@ -0,0 +1,25 @@
+package domain
+
+import "testing"
+
+func TestPrincipalCanManageSelf(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal Principal
+		want      bool
+	}{
+		{name: "token issued at login", principal: Principal{UserID: "user-1"}, want: true},
+		{name: "client token without scope", principal: Principal{UserID: "user-1", ClientID: "client-1", Scopes: []string{"openid", "profile"}}, want: false},
+		{name: "client token with scope", principal: Principal{UserID: "user-1", ClientID: "client-1", Scopes: []string{ScopeProfileWrite}}, want: true},
+		{name: "personal access token without scope", principal: Principal{UserID: "user-1", PersonalAccessTokenID: "pat-1", Scopes: []string{"users:read"}}, want: false},
+		{name: "personal access token with scope", principal: Principal{UserID: "user-1", PersonalAccessTokenID: "pat-1", Scopes: []string{ScopeProfileWrite}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.principal.CanManageSelf(); got != tt.want {
+				t.Errorf("CanManageSelf = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
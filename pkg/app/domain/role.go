@@ -0,0 +1,53 @@
+package domain
+
+import "context"
+
+type RoleUsecase interface {
+	CreateRole(ctx context.Context, createRoleRequest *CreateRoleRequest) (roleResponse *RoleResponse, err error)
+	GetRoles(ctx context.Context) (getRolesResponse *GetRolesResponse, err error)
+	GrantRole(ctx context.Context, roleMemberRequest *RoleMemberRequest) (err error)
+	RevokeRole(ctx context.Context, roleMemberRequest *RoleMemberRequest) (err error)
+	GetRoleMembers(ctx context.Context, getRoleMembersRequest *GetRoleMembersRequest) (getRoleMembersResponse *GetRoleMembersResponse, err error)
+}
+
+// Authorizer decides whether the caller holds a permission
+type Authorizer interface {
+	HasPermission(ctx context.Context, principal *Principal, permission string) (allowed bool, err error)
+}
+
+type CreateRoleRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	// Permissions must already exist, see GET /admin/roles for the permissions of the seeded admin role
+	Permissions []string `json:"permissions"`
+}
+
+type RoleResponse struct {
+	ID          uint     `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	CreatedAt   string   `json:"created_at"`
+}
+
+type GetRolesResponse struct {
+	Roles []RoleResponse `json:"roles"`
+}
+
+type RoleMemberRequest struct {
+	RoleName string `uri:"role" binding:"required"`
+	UserName string `uri:"username" binding:"required"`
+}
+
+type GetRoleMembersRequest struct {
+	RoleName string `uri:"role" binding:"required"`
+}
+
+type RoleMemberResponse struct {
+	ID       string `json:"id"`
+	UserName string `json:"user_name"`
+}
+
+type GetRoleMembersResponse struct {
+	Members []RoleMemberResponse `json:"members"`
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "context"
+
+type SigningKeyUsecase interface {
+	GetSigningKeys(ctx context.Context) (getSigningKeysResponse *GetSigningKeysResponse, err error)
+	RotateSigningKey(ctx context.Context, rotateSigningKeyRequest *RotateSigningKeyRequest) (signingKeyResponse *SigningKeyResponse, err error)
+	RetireSigningKey(ctx context.Context, retireSigningKeyRequest *RetireSigningKeyRequest) (signingKeyResponse *SigningKeyResponse, err error)
+}
+
+type RotateSigningKeyRequest struct {
+	// GracePeriod is how many minutes the previous key keeps verifying tokens, 0 uses JWT_KEY_GRACE_PERIOD
+	GracePeriod int `json:"grace_period" binding:"min=0"`
+}
+
+type RetireSigningKeyRequest struct {
+	KeyID string `uri:"kid" binding:"required"`
+	// GracePeriod is how many minutes the key keeps verifying tokens, 0 uses JWT_KEY_GRACE_PERIOD
+	GracePeriod int `json:"grace_period" binding:"min=0"`
+}
+
+type SigningKeyResponse struct {
+	ID        string `json:"id"`
+	Algorithm string `json:"algorithm"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+	RetiredAt string `json:"retired_at,omitempty"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+}
+
+type GetSigningKeysResponse struct {
+	ActiveKeyID string               `json:"active_key_id"`
+	Keys        []SigningKeyResponse `json:"keys"`
+}
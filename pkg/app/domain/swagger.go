@@ -1,5 +1,7 @@
 package domain
 
+import "github.com/satyamvatstyagi/UserManagementService/pkg/common/password"
+
 // This file is only for swagger documentation purposes. THis contains all the models used as a request and responses for swagger doc.
 
 // Success response structure for regiser user, intended only for Swagger documentation.
@@ -14,6 +16,30 @@ type LoginSuccessResp struct {
 	Data LoginUserResponse `json:"data"`
 }
 
+// Success response structure for enrol TOTP, intended only for Swagger documentation.
+type EnrollTOTPResp struct {
+	SuccessResponse
+	Data EnrollTOTPResponse `json:"data"`
+}
+
+// Success response structure for confirm TOTP, intended only for Swagger documentation.
+type ConfirmTOTPResp struct {
+	SuccessResponse
+	Data ConfirmTOTPResponse `json:"data"`
+}
+
+// Success response structure for create personal access token, intended only for Swagger documentation.
+type CreatePersonalAccessTokenResp struct {
+	SuccessResponse
+	Data CreatePersonalAccessTokenResponse `json:"data"`
+}
+
+// Success response structure for list personal access tokens, intended only for Swagger documentation.
+type GetPersonalAccessTokensResp struct {
+	SuccessResponse
+	Data GetPersonalAccessTokensResponse `json:"data"`
+}
+
 // Success response structure for responses with no data, intended only for Swagger documentation.
 type SuccessResponse struct {
 	Message string `json:"message"`
@@ -26,6 +52,85 @@ type GetUserByUserNameResp struct {
 	Data GetUserByUserNameResponse `json:"data"`
 }
 
+// Success response structure for list users, intended only for Swagger documentation.
+type ListUsersResp struct {
+	SuccessResponse
+	Data []GetUserByUserNameResponse `json:"data"`
+	Meta PageMeta                    `json:"meta"`
+}
+
+// Success response structure for get sessions, intended only for Swagger documentation.
+type GetSessionsResp struct {
+	SuccessResponse
+	Data GetSessionsResponse `json:"data"`
+}
+
+// Success response structure for list signing keys, intended only for Swagger documentation.
+type GetSigningKeysResp struct {
+	SuccessResponse
+	Data GetSigningKeysResponse `json:"data"`
+}
+
+// Success response structure for rotate and retire signing key, intended only for Swagger documentation.
+type SigningKeyResp struct {
+	SuccessResponse
+	Data SigningKeyResponse `json:"data"`
+}
+
+// Success response structure for register OAuth client, intended only for Swagger documentation.
+type RegisterClientResp struct {
+	SuccessResponse
+	Data RegisterClientResponse `json:"data"`
+}
+
+// Success response structure for create role, intended only for Swagger documentation.
+type RoleResp struct {
+	SuccessResponse
+	Data RoleResponse `json:"data"`
+}
+
+// Success response structure for list roles, intended only for Swagger documentation.
+type GetRolesResp struct {
+	SuccessResponse
+	Data GetRolesResponse `json:"data"`
+}
+
+// Success response structure for list role members, intended only for Swagger documentation.
+type GetRoleMembersResp struct {
+	SuccessResponse
+	Data GetRoleMembersResponse `json:"data"`
+}
+
+// Success response structure for create and update group, intended only for Swagger documentation.
+type GroupResp struct {
+	SuccessResponse
+	Data GroupResponse `json:"data"`
+}
+
+// Success response structure for list groups, intended only for Swagger documentation.
+type GetGroupsResp struct {
+	SuccessResponse
+	Data GetGroupsResponse `json:"data"`
+}
+
+// Success response structure for get group, intended only for Swagger documentation.
+type GetGroupResp struct {
+	SuccessResponse
+	Data GetGroupResponse `json:"data"`
+}
+
+// Success response structure for create organization, intended only for Swagger documentation.
+type OrganizationResp struct {
+	SuccessResponse
+	Data OrganizationResponse `json:"data"`
+}
+
+// Success response structure for list organizations, intended only for Swagger documentation.
+type GetOrganizationsResp struct {
+	SuccessResponse
+	Data GetOrganizationsResponse `json:"data"`
+}
+
 // Success response structure for get order by order username, intended only for Swagger documentation.
 type GetOrderByOrderUserNameResp struct {
 	SuccessResponse
@@ -38,6 +143,12 @@ type ErrorResponse struct {
 	Success bool   `json:"success" example:"false"`
 }
 
+// Failure response structure of the requests setting a password, intended only for Swagger documentation.
+type PasswordPolicyErrorResponse struct {
+	ErrorResponse
+	Errors []password.Violation `json:"errors"`
+}
+
 // Fibonacci response structure, intended only for Swagger documentation.
 type FibonacciResp struct {
 	SuccessResponse
@@ -5,7 +5,22 @@ import "context"
 type UserUsecase interface {
 	RegisterUser(ctx context.Context, registerUserRequest *RegisterUserRequest) (registerUserResponse *RegisterUserResponse, err error)
 	LoginUser(ctx context.Context, loginUserRequest *LoginUserRequest) (loginUserResponse *LoginUserResponse, err error)
+	LoginMFA(ctx context.Context, loginMFARequest *LoginMFARequest) (loginUserResponse *LoginUserResponse, err error)
+	RefreshToken(ctx context.Context, refreshTokenRequest *RefreshTokenRequest) (loginUserResponse *LoginUserResponse, err error)
+	Logout(ctx context.Context, logoutRequest *LogoutRequest) (err error)
+	RevokeSession(ctx context.Context, revokeSessionRequest *RevokeSessionRequest) (err error)
+	GetSessions(ctx context.Context, getSessionsRequest *GetSessionsRequest) (getSessionsResponse *GetSessionsResponse, err error)
+	EndSession(ctx context.Context, endSessionRequest *EndSessionRequest) (err error)
+	GetUserInfo(ctx context.Context, userInfoRequest *UserInfoRequest) (userInfo map[string]interface{}, err error)
+	GetUser(ctx context.Context, getUserRequest *GetUserRequest) (getUserByUserNameResponse *GetUserByUserNameResponse, err error)
 	GetUserByUserName(ctx context.Context, getUserByUserNameRequest *GetUserByUserNameRequest) (getUserByUserNameResponse *GetUserByUserNameResponse, err error)
+	UpdateUser(ctx context.Context, updateUserRequest *UpdateUserRequest) (getUserByUserNameResponse *GetUserByUserNameResponse, err error)
+	ChangeUserName(ctx context.Context, changeUserNameRequest *ChangeUserNameRequest) (getUserByUserNameResponse *GetUserByUserNameResponse, err error)
+	DeleteUser(ctx context.Context, deleteUserRequest *DeleteUserRequest) (err error)
+	RestoreUser(ctx context.Context, restoreUserRequest *RestoreUserRequest) (getUserByUserNameResponse *GetUserByUserNameResponse, err error)
+	UnlockUser(ctx context.Context, unlockUserRequest *UnlockUserRequest) (err error)
+	ChangeUserStatus(ctx context.Context, changeUserStatusRequest *ChangeUserStatusRequest) (getUserByUserNameResponse *GetUserByUserNameResponse, err error)
+	ListUsers(ctx context.Context, listUsersRequest *ListUsersRequest) (listUsersResponse *ListUsersResponse, err error)
 	Fibonacci(ctx context.Context, n int) (int, error)
 	SendRequestToServer(ctx context.Context, url string, requestJson []byte) (response []byte, err error)
 	GetOrderByOrderUserName(ctx context.Context, getOrderByOrderUserNameRequest *GetOrderByOrderUserNameRequest) (getOrderByOrderUserNameResponse *GetOrderByOrderUserNameResponse, err error)
@@ -14,6 +29,23 @@ type UserUsecase interface {
 type RegisterUserRequest struct {
 	UserName string `json:"user_name" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	UserProfile
+}
+
+// UserProfile holds the profile fields of a user. Fields left out of an update are kept, empty strings clear them.
+type UserProfile struct {
+	// Email is unique per tenant regardless of case
+	Email *string `json:"email" binding:"omitempty,max=320"`
+	// PhoneNumber is an E.164 number such as +27831234567, it is unique per tenant
+	PhoneNumber *string `json:"phone_number" binding:"omitempty,max=32"`
+	FirstName   *string `json:"first_name" binding:"omitempty,max=100"`
+	LastName    *string `json:"last_name" binding:"omitempty,max=100"`
+	DisplayName *string `json:"display_name" binding:"omitempty,max=255"`
+	// Locale is a BCP 47 language tag such as en-ZA
+	Locale *string `json:"locale" binding:"omitempty,max=35"`
+	// Timezone is an IANA time zone such as Africa/Johannesburg
+	Timezone  *string `json:"timezone" binding:"omitempty,max=64"`
+	AvatarURL *string `json:"avatar_url" binding:"omitempty,max=2048"`
 }
 
 type RegisterUserResponse struct {
@@ -21,23 +53,163 @@ type RegisterUserResponse struct {
 }
 
 type LoginUserRequest struct {
-	UserName string `json:"user_name" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	UserName  string `json:"user_name" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+	UserAgent string `json:"-"`
+	ClientIP  string `json:"-"`
 }
 
+// LoginUserResponse holds the tokens, or only the MFA challenge when the user has MFA enabled
 type LoginUserResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	MFARequired  bool   `json:"mfa_required,omitempty"`
+	MFAToken     string `json:"mfa_token,omitempty"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type RevokeSessionRequest struct {
+	UserName  string `uri:"username" binding:"required"`
+	SessionID string `uri:"sid" binding:"required"`
+}
+
+type GetSessionsRequest struct {
+	UserID           string
+	CurrentSessionID string
+}
+
+type GetSessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+type SessionResponse struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	ClientIP   string `json:"client_ip"`
+	Current    bool   `json:"current"`
+	CreatedAt  string `json:"created_at"`
+	LastSeenAt string `json:"last_seen_at"`
+}
+
+type EndSessionRequest struct {
+	UserID    string
+	SessionID string `uri:"sid" binding:"required"`
+}
+
+type GetUserRequest struct {
+	UserID string
 }
 
 type GetUserByUserNameRequest struct {
 	UserName string `uri:"username" binding:"required"`
+	// RequesterID is the user asking, other users can only be read with the users:read permission
+	RequesterID         string
+	RequesterCanReadAll bool
 }
 
 type GetUserByUserNameResponse struct {
-	ID        string `json:"id"`
-	UserName  string `json:"user_name"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID              string `json:"id"`
+	UserName        string `json:"user_name"`
+	Email           string `json:"email,omitempty"`
+	PhoneNumber     string `json:"phone_number,omitempty"`
+	FirstName       string `json:"first_name,omitempty"`
+	LastName        string `json:"last_name,omitempty"`
+	DisplayName     string `json:"display_name"`
+	Locale          string `json:"locale,omitempty"`
+	Timezone        string `json:"timezone,omitempty"`
+	AvatarURL       string `json:"avatar_url,omitempty"`
+	Status          string `json:"status"`
+	StatusReason    string `json:"status_reason,omitempty"`
+	StatusChangedAt string `json:"status_changed_at,omitempty"`
+	EmailVerified   bool   `json:"email_verified"`
+	CreatedAt       string `json:"created_at"`
+	UpdatedAt       string `json:"updated_at"`
+}
+
+// UpdateUserRequest changes the profile of a user, fields left out are kept
+type UpdateUserRequest struct {
+	UserName string `uri:"username" json:"-" binding:"required"`
+	UserProfile
+	// RequesterID is the user asking, other users can only be changed with the users:write permission
+	RequesterID          string `json:"-"`
+	RequesterCanWriteAll bool   `json:"-"`
+	// RequesterCanWriteSelf is false for delegated tokens without the profile:write scope
+	RequesterCanWriteSelf bool `json:"-"`
+}
+
+type ChangeUserNameRequest struct {
+	UserName    string `uri:"username" json:"-" binding:"required"`
+	NewUserName string `json:"user_name" binding:"required"`
+	// RequesterID is the user asking, other users can only be changed with the users:write permission
+	RequesterID          string `json:"-"`
+	RequesterCanWriteAll bool   `json:"-"`
+	// RequesterCanWriteSelf is false for delegated tokens without the profile:write scope
+	RequesterCanWriteSelf bool `json:"-"`
+}
+
+type DeleteUserRequest struct {
+	UserName string `uri:"username" binding:"required"`
+	// RequesterID is the user asking, other users can only be deleted with the users:write permission
+	RequesterID          string
+	RequesterCanWriteAll bool
+	// RequesterCanWriteSelf is false for personal access tokens and for tokens of OAuth clients without the
+	// profile:write scope
+	RequesterCanWriteSelf bool
+}
+
+type RestoreUserRequest struct {
+	UserName string `uri:"username" binding:"required"`
+}
+
+type UnlockUserRequest struct {
+	UserName string `uri:"username" binding:"required"`
+	// RequesterID is the admin unlocking the user
+	RequesterID string `json:"-"`
+}
+
+// ChangeUserStatusRequest moves a user to another status, the route decides the status
+type ChangeUserStatusRequest struct {
+	UserName string `uri:"username" json:"-" binding:"required"`
+	// Reason is recorded with the status and shown with the user
+	Reason string `json:"reason" binding:"max=255"`
+	Status string `json:"-"`
+	// RequesterID is the admin changing the status
+	RequesterID string `json:"-"`
+}
+
+// ListUsersRequest filters and orders a user listing, dates are RFC 3339 timestamps
+type ListUsersRequest struct {
+	// Cursor is the next_cursor of the previous page, the other parameters must not change between pages
+	Cursor         string `form:"cursor"`
+	Limit          int    `form:"limit" binding:"omitempty,min=1,max=200"`
+	CreatedAfter   string `form:"created_after"`
+	CreatedBefore  string `form:"created_before"`
+	Status         string `form:"status" binding:"omitempty,oneof=active pending_verification suspended locked deactivated deleted all"`
+	Role           string `form:"role"`
+	UserNamePrefix string `form:"username_prefix"`
+	Sort           string `form:"sort" binding:"omitempty,oneof=created_at user_name"`
+	Order          string `form:"order" binding:"omitempty,oneof=asc desc"`
+}
+
+// ListUsersResponse is returned as the data and the meta of the response envelope
+type ListUsersResponse struct {
+	Users []GetUserByUserNameResponse
+	Meta  PageMeta
+}
+
+// PageMeta describes a page of a listing, NextCursor is empty on the last page
+type PageMeta struct {
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type GetOrderByOrderUserNameRequest struct {
@@ -62,6 +234,10 @@ type Response struct {
 	ErrorCode int `json:"error_code,omitempty"`
 	// Data is the data returned in the response.
 	Data interface{} `json:"data,omitempty"`
+	// Meta describes the data, such as the page of a listing.
+	Meta interface{} `json:"meta,omitempty"`
+	// Errors details why the request failed, such as the password policy rules it violates.
+	Errors interface{} `json:"errors,omitempty"`
 }
 
 type TokenValidationRequest struct {
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// AuthorizationCode is stored hashed and can be exchanged once. The session is started when the user
+// authenticates, so replaying a used code can end the session of the tokens it was exchanged for.
+type AuthorizationCode struct {
+	gorm.Model
+	CodeHash            string    `gorm:"size:64;uniqueIndex;not null;"`
+	ClientID            string    `gorm:"size:64;index;not null;"`
+	UserUUID            uuid.UUID `gorm:"type:uuid;not null;"`
+	SessionUUID         uuid.UUID `gorm:"type:uuid;not null;"`
+	RedirectURI         string    `gorm:"type:text;not null;"`
+	Scope               string    `gorm:"size:512"`
+	Nonce               string    `gorm:"size:255"`
+	CodeChallenge       string    `gorm:"size:128;not null;"`
+	CodeChallengeMethod string    `gorm:"size:16;not null;"`
+	ExpiresAt           time.Time `gorm:"not null;"`
+	UsedAt              *time.Time
+}
+
+type AuthorizationCodeRepository interface {
+	CreateAuthorizationCode(ctx context.Context, code *AuthorizationCode) error
+	GetAuthorizationCodeByHash(ctx context.Context, codeHash string) (*AuthorizationCode, error)
+	UseAuthorizationCode(ctx context.Context, code *AuthorizationCode) error
+}
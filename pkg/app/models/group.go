@@ -0,0 +1,47 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// Group organises the users of a tenant, such as a team or a department. Groups can contain other groups,
+// members of a subgroup are effectively members of every group above it. Names are unique per tenant.
+type Group struct {
+	gorm.Model
+	TenantID    string `gorm:"size:63;not null;default:'';index:idx_group_tenant_name,unique,priority:1"`
+	Name        string `gorm:"size:64;not null;index:idx_group_tenant_name,unique,priority:2"`
+	Description string `gorm:"size:255"`
+}
+
+// GroupMember links a user directly to a group, users are linked by their UUID
+type GroupMember struct {
+	GroupID   uint      `gorm:"primaryKey"`
+	UserUUID  uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	CreatedAt time.Time `gorm:"not null;"`
+}
+
+// GroupNesting makes the child group a member of the parent group
+type GroupNesting struct {
+	ParentGroupID uint      `gorm:"primaryKey"`
+	ChildGroupID  uint      `gorm:"primaryKey;index"`
+	CreatedAt     time.Time `gorm:"not null;"`
+}
+
+type GroupRepository interface {
+	CreateGroup(ctx context.Context, group *Group) error
+	GetGroups(ctx context.Context) ([]Group, error)
+	GetGroupByName(ctx context.Context, name string) (*Group, error)
+	UpdateGroup(ctx context.Context, group *Group) error
+	DeleteGroup(ctx context.Context, group *Group) error
+	AddMember(ctx context.Context, group *Group, userID uuid.UUID) error
+	RemoveMember(ctx context.Context, group *Group, userID uuid.UUID) error
+	GetMembers(ctx context.Context, group *Group) ([]User, error)
+	AddSubgroup(ctx context.Context, parent *Group, child *Group) error
+	RemoveSubgroup(ctx context.Context, parent *Group, child *Group) error
+	GetSubgroups(ctx context.Context, group *Group) ([]Group, error)
+	GetEffectiveGroups(ctx context.Context, userID string) ([]Group, error)
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// Kinds of identifiers failed logins are counted by
+const (
+	LoginAttemptKindUserName = "username"
+	LoginAttemptKindClientIP = "client_ip"
+)
+
+// LoginAttempt counts the recent failed logins of a username or a client IP within a tenant. Usernames
+// without an account are counted as well, so a lockout does not reveal whether an account exists.
+type LoginAttempt struct {
+	TenantID      string    `gorm:"primaryKey;size:63"`
+	Kind          string    `gorm:"primaryKey;size:16"`
+	Identifier    string    `gorm:"primaryKey;size:255"`
+	Failures      int       `gorm:"not null;"`
+	LastFailureAt time.Time `gorm:"not null;"`
+}
+
+type LoginAttemptRepository interface {
+	GetLoginAttempt(ctx context.Context, kind string, identifier string) (*LoginAttempt, error)
+	RecordLoginFailure(ctx context.Context, kind string, identifier string, windowStart time.Time) (*LoginAttempt, error)
+	ResetLoginAttempts(ctx context.Context, kind string, identifier string) error
+}
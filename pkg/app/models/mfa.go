@@ -0,0 +1,46 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// Authentication methods recorded in the amr claim, as registered by RFC 8176
+const (
+	AuthMethodPassword = "pwd"
+	AuthMethodOTP      = "otp"
+	AuthMethodMFA      = "mfa"
+	AuthMethodSMS      = "sms"
+)
+
+// MFAFactor is the TOTP secret of a user. It only protects logins once ConfirmedAt is set, which happens
+// when the user proves their authenticator app generates valid codes. LastUsedStep keeps codes from being
+// replayed.
+type MFAFactor struct {
+	gorm.Model
+	UserUUID     uuid.UUID `gorm:"type:uuid;uniqueIndex;not null;"`
+	Secret       string    `gorm:"size:64;not null;"`
+	ConfirmedAt  *time.Time
+	LastUsedStep int64 `gorm:"not null;default:0"`
+}
+
+// RecoveryCode is a single use code that stands in for a TOTP code when the authenticator app is lost, it is
+// stored hashed
+type RecoveryCode struct {
+	gorm.Model
+	UserUUID uuid.UUID `gorm:"type:uuid;index;not null;"`
+	CodeHash string    `gorm:"size:64;uniqueIndex;not null;"`
+	UsedAt   *time.Time
+}
+
+type MFARepository interface {
+	GetMFAFactor(ctx context.Context, userID string) (*MFAFactor, error)
+	SaveMFAFactor(ctx context.Context, factor *MFAFactor) error
+	ConfirmMFAFactor(ctx context.Context, factor *MFAFactor, recoveryCodeHashes []string) error
+	UseTOTPStep(ctx context.Context, factor *MFAFactor, step int64) error
+	UseRecoveryCode(ctx context.Context, userID string, codeHash string) error
+	DeleteMFAFactor(ctx context.Context, userID string) error
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Grant types an OAuth client can be allowed to use
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+// OAuthClient is an application registered to obtain tokens through the OAuth endpoints. Public clients
+// (SPAs, mobile apps) have no secret and must use PKCE, first party clients are not asked for consent.
+// Machine clients using the client credentials grant need no redirect URI. RedirectURIs, Scopes and
+// GrantTypes are space separated lists, Scopes is the allow-list of scopes the client may request.
+type OAuthClient struct {
+	gorm.Model
+	ClientID         string `gorm:"size:64;uniqueIndex;not null;"`
+	ClientSecretHash string `gorm:"size:255"`
+	Name             string `gorm:"size:255;not null;"`
+	RedirectURIs     string `gorm:"type:text"`
+	Scopes           string `gorm:"size:512"`
+	GrantTypes       string `gorm:"size:255"`
+	FirstParty       bool   `gorm:"not null;default:false"`
+	Public           bool   `gorm:"not null;default:false"`
+}
+
+// RedirectURIList returns the redirect URIs registered for the client
+func (c *OAuthClient) RedirectURIList() []string {
+	return strings.Fields(c.RedirectURIs)
+}
+
+// ScopeList returns the scopes the client may request
+func (c *OAuthClient) ScopeList() []string {
+	return strings.Fields(c.Scopes)
+}
+
+// GrantTypeList returns the grant types the client may use
+func (c *OAuthClient) GrantTypeList() []string {
+	return strings.Fields(c.GrantTypes)
+}
+
+type OAuthClientRepository interface {
+	CreateClient(ctx context.Context, client *OAuthClient) error
+	GetClientByClientID(ctx context.Context, clientID string) (*OAuthClient, error)
+}
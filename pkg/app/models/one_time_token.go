@@ -0,0 +1,34 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// Purposes a one time token can be issued for, a token is only accepted for the purpose it was issued for
+const (
+	OneTimeTokenPasswordReset = "password_reset"
+	OneTimeTokenMFAChallenge  = "mfa_challenge"
+)
+
+// OneTimeToken is a single use secret sent to a user, it is stored hashed. Issuing a new token for the same
+// user and purpose supersedes the tokens issued before it.
+type OneTimeToken struct {
+	gorm.Model
+	TenantID  string    `gorm:"size:63;not null;"`
+	UserUUID  uuid.UUID `gorm:"type:uuid;index;not null;"`
+	Purpose   string    `gorm:"size:32;not null;"`
+	TokenHash string    `gorm:"size:64;uniqueIndex;not null;"`
+	ExpiresAt time.Time `gorm:"not null;"`
+	UsedAt    *time.Time
+}
+
+type OneTimeTokenRepository interface {
+	CreateOneTimeToken(ctx context.Context, token *OneTimeToken) error
+	GetOneTimeToken(ctx context.Context, purpose string, tokenHash string) (*OneTimeToken, error)
+	UseOneTimeToken(ctx context.Context, purpose string, tokenHash string) (*OneTimeToken, error)
+	GetLatestOneTimeToken(ctx context.Context, purpose string, userID string) (*OneTimeToken, error)
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Organization is a tenant, such as a country or a business unit. Usernames are unique per organization.
+type Organization struct {
+	gorm.Model
+	TenantID string `gorm:"size:63;uniqueIndex;not null;"`
+	Name     string `gorm:"size:255;not null;"`
+}
+
+type OrganizationRepository interface {
+	CreateOrganization(ctx context.Context, organization *Organization) error
+	EnsureOrganization(ctx context.Context, organization *Organization) error
+	GetOrganizations(ctx context.Context) ([]Organization, error)
+	GetOrganizationByTenantID(ctx context.Context, tenantID string) (*Organization, error)
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// OTPCode is a short numeric code sent by SMS or email to log a user in without a password. It is stored as a
+// bcrypt hash, as short codes are easy to guess from a plain hash, and stops working once it was used, after
+// OTP_MAX_ATTEMPTS wrong guesses or when a newer code is sent to the user.
+type OTPCode struct {
+	gorm.Model
+	TenantID  string    `gorm:"size:63;not null;"`
+	UserUUID  uuid.UUID `gorm:"type:uuid;index;not null;"`
+	Channel   string    `gorm:"size:16;not null;"`
+	CodeHash  string    `gorm:"size:60;not null;"`
+	Attempts  int       `gorm:"not null;default:0;"`
+	ExpiresAt time.Time `gorm:"not null;"`
+	UsedAt    *time.Time
+}
+
+type OTPCodeRepository interface {
+	CreateOTPCode(ctx context.Context, code *OTPCode) error
+	GetLatestOTPCode(ctx context.Context, userID string) (*OTPCode, error)
+	RecordOTPFailure(ctx context.Context, code *OTPCode, maxAttempts int) error
+	UseOTPCode(ctx context.Context, code *OTPCode) error
+}
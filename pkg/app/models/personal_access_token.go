@@ -0,0 +1,38 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// PersonalAccessTokenPrefix starts every personal access token, it tells them apart from JWTs in the
+// Authorization header and makes leaked tokens easy to scan for
+const PersonalAccessTokenPrefix = "ump_"
+
+// PersonalAccessToken is a long lived credential a user creates for scripts and CI jobs. Only the hash of the
+// token is stored, TokenPrefix keeps its first characters so users can tell their tokens apart. Scopes are the
+// space separated permissions the token is limited to, ExpiresAt is nil for tokens that do not expire.
+type PersonalAccessToken struct {
+	gorm.Model
+	UUID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();unique"`
+	TenantID    string    `gorm:"size:63;not null;"`
+	UserUUID    uuid.UUID `gorm:"type:uuid;index;not null;"`
+	Name        string    `gorm:"size:100;not null;"`
+	TokenPrefix string    `gorm:"size:16;not null;"`
+	TokenHash   string    `gorm:"size:64;uniqueIndex;not null;"`
+	Scopes      string    `gorm:"size:1024"`
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+}
+
+type PersonalAccessTokenRepository interface {
+	CreatePersonalAccessToken(ctx context.Context, token *PersonalAccessToken) error
+	GetPersonalAccessTokens(ctx context.Context, userID string) ([]PersonalAccessToken, error)
+	GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (*PersonalAccessToken, error)
+	TouchPersonalAccessToken(ctx context.Context, token *PersonalAccessToken, notBefore time.Time) error
+	RevokePersonalAccessToken(ctx context.Context, userID string, tokenID string) error
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// Reasons recorded when a refresh token is revoked
+const (
+	RefreshTokenRevokedRotated         = "rotated"
+	RefreshTokenRevokedReuseDetected   = "reuse_detected"
+	RefreshTokenRevokedSessionEnded    = "session_ended"
+	RefreshTokenRevokedUserDeleted     = "user_deleted"
+	RefreshTokenRevokedPasswordReset   = "password_reset"
+	RefreshTokenRevokedPasswordChanged = "password_changed"
+	RefreshTokenRevokedStatusChanged   = "status_changed"
+)
+
+// RefreshToken is stored hashed, tokens issued for the same session share a FamilyID equal to the session id.
+// ClientID is empty for tokens issued by the password login.
+type RefreshToken struct {
+	gorm.Model
+	UUID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();unique"`
+	UserUUID      uuid.UUID  `gorm:"type:uuid;index;not null;"`
+	FamilyID      uuid.UUID  `gorm:"type:uuid;index;not null;"`
+	ClientID      string     `gorm:"size:64;index"`
+	Scope         string     `gorm:"size:512"`
+	TokenHash     string     `gorm:"size:64;uniqueIndex;not null;"`
+	ExpiresAt     time.Time  `gorm:"not null;"`
+	RevokedAt     *time.Time `gorm:"index"`
+	RevokedReason string     `gorm:"size:32"`
+}
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, refreshToken *RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, current *RefreshToken, next *RefreshToken) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID, reason string) error
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of identifiers that can be placed on the revocation list
+const (
+	RevocationKindJTI = "jti"
+	RevocationKindSID = "sid"
+)
+
+type RevokedToken struct {
+	gorm.Model
+	Kind      string    `gorm:"size:8;index:idx_revoked_token_kind_value,unique;not null;"`
+	Value     string    `gorm:"size:255;index:idx_revoked_token_kind_value,unique;not null;"`
+	ExpiresAt time.Time `gorm:"index;not null;"`
+}
+
+// RevocationRepository holds revoked token ids and session ids until the tokens they cover have expired
+type RevocationRepository interface {
+	RevokeToken(ctx context.Context, kind string, value string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string, sid string) (bool, error)
+	PruneExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// Permissions checked by the service, they are seeded at startup and all granted to the admin role
+const (
+	PermissionUsersRead   = "users:read"
+	PermissionUsersWrite  = "users:write"
+	PermissionRolesRead   = "roles:read"
+	PermissionRolesWrite  = "roles:write"
+	PermissionGroupsRead  = "groups:read"
+	PermissionGroupsWrite = "groups:write"
+	// PermissionClientsWrite registers OAuth clients, which are shared by every tenant
+	PermissionClientsWrite = "clients:write"
+	// PermissionKeysRead and PermissionKeysWrite list and rotate the signing keys of every tenant
+	PermissionKeysRead  = "keys:read"
+	PermissionKeysWrite = "keys:write"
+	// PermissionOrganizationsRead and PermissionOrganizationsWrite list and create the tenants
+	PermissionOrganizationsRead  = "organizations:read"
+	PermissionOrganizationsWrite = "organizations:write"
+)
+
+// DefaultPermissions are the permissions seeded at startup
+var DefaultPermissions = []string{PermissionUsersRead, PermissionUsersWrite, PermissionRolesRead, PermissionRolesWrite, PermissionGroupsRead, PermissionGroupsWrite, PermissionClientsWrite, PermissionKeysRead, PermissionKeysWrite, PermissionOrganizationsRead, PermissionOrganizationsWrite}
+
+// PlatformPermissions act on every tenant, they only take effect for users and clients of the default tenant
+var PlatformPermissions = []string{PermissionClientsWrite, PermissionKeysRead, PermissionKeysWrite, PermissionOrganizationsRead, PermissionOrganizationsWrite}
+
+// RoleAdmin is the seeded role holding every permission
+const RoleAdmin = "admin"
+
+type Permission struct {
+	gorm.Model
+	Name string `gorm:"size:64;uniqueIndex;not null;"`
+}
+
+// Role bundles permissions. Custom roles belong to the tenant they were created in and names are unique per
+// tenant, seeded roles such as admin have no tenant and are shared by every tenant.
+type Role struct {
+	gorm.Model
+	TenantID    string       `gorm:"size:63;not null;default:'';index:idx_role_tenant_name,unique,priority:1"`
+	Name        string       `gorm:"size:64;not null;index:idx_role_tenant_name,unique,priority:2"`
+	Description string       `gorm:"size:255"`
+	Permissions []Permission `gorm:"many2many:role_permissions;"`
+}
+
+// UserRole links a user to a role, users are linked by their UUID
+type UserRole struct {
+	UserUUID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	RoleID    uint      `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"not null;"`
+}
+
+type RoleRepository interface {
+	SeedRoles(ctx context.Context, permissions []string, adminRole string) error
+	CreateRole(ctx context.Context, role *Role, permissions []string) error
+	GetRoles(ctx context.Context) ([]Role, error)
+	GetRoleByName(ctx context.Context, name string) (*Role, error)
+	GrantRole(ctx context.Context, userID uuid.UUID, role *Role) error
+	RevokeRole(ctx context.Context, userID uuid.UUID, role *Role) error
+	GetRoleMembers(ctx context.Context, role *Role) ([]User, error)
+	GetUserRoles(ctx context.Context, userID string) ([]Role, error)
+}
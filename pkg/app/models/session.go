@@ -0,0 +1,30 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// Session is a login of a user, AuthMethods are the space separated methods the user authenticated with and
+// become the amr claim of every token issued for the session
+type Session struct {
+	gorm.Model
+	UUID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();unique"`
+	UserUUID    uuid.UUID  `gorm:"type:uuid;index;not null;"`
+	UserAgent   string     `gorm:"size:512"`
+	ClientIP    string     `gorm:"size:64"`
+	AuthMethods string     `gorm:"size:64"`
+	LastSeenAt  time.Time  `gorm:"not null;"`
+	EndedAt     *time.Time `gorm:"index"`
+}
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *Session) error
+	GetSessionByID(ctx context.Context, sessionID string) (*Session, error)
+	GetActiveSessionsByUserID(ctx context.Context, userID string) ([]Session, error)
+	TouchSession(ctx context.Context, sessionID string) error
+	EndSession(ctx context.Context, sessionID string) error
+}
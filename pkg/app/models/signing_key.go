@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SigningKey persists the JWT key ring, PrivateKeyPEM is empty for the key configured through the environment
+// since only its lifecycle is tracked. Rotated keys are stored encrypted with SIGNING_KEY_ENCRYPTION_KEY, the
+// column only holds a plain PEM for keys stored before encryption. The repository implements jwt.KeyStore.
+type SigningKey struct {
+	gorm.Model
+	KID           string     `gorm:"size:128;uniqueIndex;not null;"`
+	Algorithm     string     `gorm:"size:16;not null;"`
+	PrivateKeyPEM string     `gorm:"type:text"`
+	Status        string     `gorm:"size:16;not null;"`
+	RetiredAt     *time.Time `gorm:""`
+	ExpiresAt     *time.Time `gorm:""`
+}
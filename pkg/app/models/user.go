@@ -8,16 +8,103 @@ import (
 	"gorm.io/gorm"
 )
 
+// Statuses of a user. A user pending verification registered with an email address and has not confirmed it
+// yet. Only active users can log in, the tokens of a user are revoked when they leave the active status.
+const (
+	UserStatusActive              = "active"
+	UserStatusPendingVerification = "pending_verification"
+	UserStatusSuspended           = "suspended"
+	UserStatusLocked              = "locked"
+	UserStatusDeactivated         = "deactivated"
+)
+
+// Statuses users can be listed by besides the statuses above, deleted users are the soft deleted ones
+const (
+	UserStatusDeleted = "deleted"
+	UserStatusAll     = "all"
+)
+
+// UserStatusTransitions lists the statuses a user can be moved to from each status
+var UserStatusTransitions = map[string][]string{
+	UserStatusPendingVerification: {UserStatusActive, UserStatusSuspended, UserStatusDeactivated},
+	UserStatusActive:              {UserStatusSuspended, UserStatusLocked, UserStatusDeactivated},
+	UserStatusSuspended:           {UserStatusActive, UserStatusDeactivated},
+	UserStatusLocked:              {UserStatusActive, UserStatusSuspended, UserStatusDeactivated},
+	UserStatusDeactivated:         {UserStatusActive},
+}
+
+// CanTransition tells whether a user can be moved from one status to the other
+func CanTransition(from string, to string) bool {
+	for _, status := range UserStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Fields users can be listed by
+const (
+	UserSortCreatedAt = "created_at"
+	UserSortUserName  = "user_name"
+)
+
+// User is an account of a tenant. The email is stored in lower case, it and the phone number are null when not
+// given so that they only have to be unique when set. EmailVerifiedAt is when the user confirmed the email
+// address they have now, it is nil while the address is unconfirmed. StatusReason, StatusChangedAt and
+// StatusChangedBy, the id of the admin, describe the last status change made by an admin.
 type User struct {
 	gorm.Model
-	UUID      uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid();unique"`
-	UserName  string    `gorm:"index:idx_user_uuid,unique;not null;"`
-	Password  string    `gorm:"size:255;not null;" json:"password"`
-	CreatedAt time.Time `gorm:"not null;"`
-	UpdatedAt time.Time `gorm:"not null;"`
+	UUID                    uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid();unique"`
+	TenantID                string    `gorm:"size:63;not null;default:'';index:idx_user_tenant_user_name,unique,priority:1;index:idx_user_tenant_email,unique,priority:1;index:idx_user_tenant_phone_number,unique,priority:1"`
+	UserName                string    `gorm:"index:idx_user_tenant_user_name,unique,priority:2;not null;"`
+	Password                string    `gorm:"size:255;not null;" json:"password"`
+	Email                   *string   `gorm:"size:320;index:idx_user_tenant_email,unique,priority:2"`
+	PhoneNumber             *string   `gorm:"size:16;index:idx_user_tenant_phone_number,unique,priority:2"`
+	FirstName               string    `gorm:"size:100"`
+	LastName                string    `gorm:"size:100"`
+	DisplayName             string    `gorm:"size:255"`
+	Locale                  string    `gorm:"size:35"`
+	Timezone                string    `gorm:"size:64"`
+	AvatarURL               string    `gorm:"size:2048"`
+	Status                  string    `gorm:"size:32;not null;default:active"`
+	StatusReason            string    `gorm:"size:255"`
+	StatusChangedBy         string    `gorm:"size:36"`
+	StatusChangedAt         *time.Time
+	EmailVerifiedAt         *time.Time
+	EmailVerificationSentAt *time.Time
+	CreatedAt               time.Time `gorm:"not null;"`
+	UpdatedAt               time.Time `gorm:"not null;"`
 }
 
+// UserRepository queries are scoped to the tenant of the request context, see tenant.ID
 type UserRepository interface {
-	RegisterUser(ctx context.Context, userID string, password string) (string, error)
+	RegisterUser(ctx context.Context, user *User) (string, error)
 	GetUserByUserName(ctx context.Context, userName string) (*User, error)
+	GetUserByUserID(ctx context.Context, userID string) (*User, error)
+	GetUserByEmail(ctx context.Context, email string) (*User, error)
+	GetUserByPhoneNumber(ctx context.Context, phoneNumber string) (*User, error)
+	VerifyEmail(ctx context.Context, userID string, email string) error
+	MarkEmailVerificationSent(ctx context.Context, user *User, notBefore time.Time) error
+	ChangeUserStatus(ctx context.Context, user *User, status string) error
+	UpdateUser(ctx context.Context, user *User) error
+	ChangeUserName(ctx context.Context, user *User, userName string) error
+	ChangePassword(ctx context.Context, user *User, password string) error
+	DeleteUser(ctx context.Context, user *User) error
+	RestoreUser(ctx context.Context, userName string) (*User, error)
+	ListUsers(ctx context.Context, filter *UserFilter) ([]User, int64, error)
+}
+
+// UserFilter selects and orders the users of a listing, zero values do not filter
+type UserFilter struct {
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+	Status         string
+	Role           string
+	UserNamePrefix string
+	SortBy         string
+	Descending     bool
+	Limit          int
+	// After is the last user of the previous page, the listing continues behind it
+	After *User
 }
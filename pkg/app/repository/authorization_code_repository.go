@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/mtnapm"
+	"go.elastic.co/apm/v2"
+)
+
+type authorizationCodeRepository struct {
+	database *gorm.DB
+}
+
+func NewAuthorizationCodeRepository(database *gorm.DB) models.AuthorizationCodeRepository {
+	return &authorizationCodeRepository{
+		database: database,
+	}
+}
+
+func (a *authorizationCodeRepository) CreateAuthorizationCode(ctx context.Context, code *models.AuthorizationCode) error {
+	//for fetching the database query
+	statement := a.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Create(code)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := a.database.Create(code).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[AuthorizationCodeRepository][CreateAuthorizationCode] Error in creating authorization code: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (a *authorizationCodeRepository) GetAuthorizationCodeByHash(ctx context.Context, codeHash string) (*models.AuthorizationCode, error) {
+	var code models.AuthorizationCode
+
+	//for fetching the database query
+	statement := a.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("code_hash = ?", codeHash).First(&code)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := a.database.Where("code_hash = ?", codeHash).First(&code).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+			log.Println("[AuthorizationCodeRepository][GetAuthorizationCodeByHash] Authorization code not found: ", err)
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid authorization code", cerr.InvalidRequestErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[AuthorizationCodeRepository][GetAuthorizationCodeByHash] Error in fetching authorization code: ", err)
+		return nil, err
+	}
+	return &code, nil
+}
+
+// UseAuthorizationCode marks the code as used. The update only succeeds while the code is still unused, so
+// two concurrent exchanges of the same code cannot both succeed.
+func (a *authorizationCodeRepository) UseAuthorizationCode(ctx context.Context, code *models.AuthorizationCode) error {
+	now := time.Now()
+
+	//for fetching the database query
+	statement := a.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&models.AuthorizationCode{}).Where("id = ? AND used_at IS NULL", code.ID).Update("used_at", now)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	result := a.database.Model(&models.AuthorizationCode{}).Where("id = ? AND used_at IS NULL", code.ID).Update("used_at", now)
+	if result.Error != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", result.Error.Error())).Send()
+		log.Println("[AuthorizationCodeRepository][UseAuthorizationCode] Error in using authorization code: ", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Authorization code has already been used", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	code.UsedAt = &now
+	return nil
+}
@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/consts"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/mtnapm"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
+	"go.elastic.co/apm/v2"
+)
+
+// descendantsQuery checks whether the second group is the first group or nested anywhere below it, UNION
+// drops rows already seen so the walk ends even if the nesting graph had a cycle
+const descendantsQuery = `WITH RECURSIVE descendants(id) AS (
+	SELECT CAST(? AS bigint)
+	UNION
+	SELECT group_nestings.child_group_id FROM group_nestings JOIN descendants ON group_nestings.parent_group_id = descendants.id
+) SELECT COUNT(*) FROM descendants WHERE id = ?`
+
+// effectiveGroupsQuery walks up from the groups the user is a direct member of to every group containing them
+const effectiveGroupsQuery = `WITH RECURSIVE effective(id) AS (
+	SELECT group_members.group_id FROM group_members WHERE group_members.user_uuid = ?
+	UNION
+	SELECT group_nestings.parent_group_id FROM group_nestings JOIN effective ON group_nestings.child_group_id = effective.id
+) SELECT groups.* FROM groups JOIN effective ON groups.id = effective.id WHERE groups.tenant_id = ? AND groups.deleted_at IS NULL ORDER BY groups.name`
+
+type groupRepository struct {
+	database *gorm.DB
+}
+
+func NewGroupRepository(database *gorm.DB) models.GroupRepository {
+	return &groupRepository{
+		database: database,
+	}
+}
+
+func (r *groupRepository) CreateGroup(ctx context.Context, group *models.Group) error {
+	group.TenantID = tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Create(group)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Create(group).Error; err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == consts.UniqueViolation {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", pgErr.Error())).Send()
+			log.Println("[GroupRepository][CreateGroup] Group already exists: ", pgErr.Error())
+			return cerr.NewCustomErrorWithCodeAndOrigin("Group already exists", cerr.DuplicateEntryErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[GroupRepository][CreateGroup] Error in creating group: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *groupRepository) GetGroups(ctx context.Context) ([]models.Group, error) {
+	var groups []models.Group
+	tenantID := tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("tenant_id = ?", tenantID).Order("name").Find(&groups)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Where("tenant_id = ?", tenantID).Order("name").Find(&groups).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[GroupRepository][GetGroups] Error in fetching groups: ", err)
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (r *groupRepository) GetGroupByName(ctx context.Context, name string) (*models.Group, error) {
+	var group models.Group
+	tenantID := tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("tenant_id = ? AND name = ?", tenantID, name).First(&group)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Where("tenant_id = ? AND name = ?", tenantID, name).First(&group).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+			log.Println("[GroupRepository][GetGroupByName] Group not found: ", err)
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("Group not found", cerr.NotFoundErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[GroupRepository][GetGroupByName] Error in fetching group: ", err)
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *groupRepository) UpdateGroup(ctx context.Context, group *models.Group) error {
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(group).Where("tenant_id = ?", group.TenantID).Update("description", group.Description)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Model(group).Where("tenant_id = ?", group.TenantID).Update("description", group.Description).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[GroupRepository][UpdateGroup] Error in updating group: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteGroup removes the group together with its memberships and its place in the nesting, the name can
+// be reused afterwards
+func (r *groupRepository) DeleteGroup(ctx context.Context, group *models.Group) error {
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Unscoped().Where("tenant_id = ?", group.TenantID).Delete(group)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	err := r.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", group.ID).Delete(&models.GroupMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("parent_group_id = ? OR child_group_id = ?", group.ID, group.ID).Delete(&models.GroupNesting{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("tenant_id = ?", group.TenantID).Delete(group).Error
+	})
+	if err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[GroupRepository][DeleteGroup] Error in deleting group: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *groupRepository) AddMember(ctx context.Context, group *models.Group, userID uuid.UUID) error {
+	member := &models.GroupMember{GroupID: group.ID, UserUUID: userID, CreatedAt: time.Now()}
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(member)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	// Adding a user who already is a member is not an error
+	if err := r.database.Clauses(clause.OnConflict{DoNothing: true}).Create(member).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[GroupRepository][AddMember] Error in adding member: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *groupRepository) RemoveMember(ctx context.Context, group *models.Group, userID uuid.UUID) error {
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("group_id = ? AND user_uuid = ?", group.ID, userID).Delete(&models.GroupMember{})
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	result := r.database.Where("group_id = ? AND user_uuid = ?", group.ID, userID).Delete(&models.GroupMember{})
+	if result.Error != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", result.Error.Error())).Send()
+		log.Println("[GroupRepository][RemoveMember] Error in removing member: ", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return cerr.NewCustomErrorWithCodeAndOrigin("User is not a member of the group", cerr.NotFoundErrorCode, nil)
+	}
+
+	return nil
+}
+
+func (r *groupRepository) GetMembers(ctx context.Context, group *models.Group) ([]models.User, error) {
+	var users []models.User
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Joins("JOIN group_members ON group_members.user_uuid = users.uuid").Where("group_members.group_id = ? AND users.tenant_id = ?", group.ID, group.TenantID).Order("users.user_name").Find(&users)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Joins("JOIN group_members ON group_members.user_uuid = users.uuid").Where("group_members.group_id = ? AND users.tenant_id = ?", group.ID, group.TenantID).Order("users.user_name").Find(&users).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[GroupRepository][GetMembers] Error in fetching members: ", err)
+		return nil, err
+	}
+	return users, nil
+}
+
+// AddSubgroup nests the child group in the parent group, nesting a group inside itself or one of its own
+// subgroups is rejected as it would create a cycle
+func (r *groupRepository) AddSubgroup(ctx context.Context, parent *models.Group, child *models.Group) error {
+	nesting := &models.GroupNesting{ParentGroupID: parent.ID, ChildGroupID: child.ID, CreatedAt: time.Now()}
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(nesting)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	err := r.database.Transaction(func(tx *gorm.DB) error {
+		// Nesting changes are serialised, otherwise two concurrent changes could each pass the check and
+		// together close a cycle
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('group_nestings'))").Error; err != nil {
+			return err
+		}
+
+		var cycle int64
+		if err := tx.Raw(descendantsQuery, child.ID, parent.ID).Scan(&cycle).Error; err != nil {
+			return err
+		}
+		if cycle > 0 {
+			return cerr.NewCustomErrorWithCodeAndOrigin("Group cannot be nested inside itself", cerr.InvalidRequestErrorCode, nil)
+		}
+
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(nesting).Error
+	})
+	if err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[GroupRepository][AddSubgroup] Error in adding subgroup: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *groupRepository) RemoveSubgroup(ctx context.Context, parent *models.Group, child *models.Group) error {
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("parent_group_id = ? AND child_group_id = ?", parent.ID, child.ID).Delete(&models.GroupNesting{})
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	result := r.database.Where("parent_group_id = ? AND child_group_id = ?", parent.ID, child.ID).Delete(&models.GroupNesting{})
+	if result.Error != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", result.Error.Error())).Send()
+		log.Println("[GroupRepository][RemoveSubgroup] Error in removing subgroup: ", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Group is not a subgroup of the group", cerr.NotFoundErrorCode, nil)
+	}
+
+	return nil
+}
+
+func (r *groupRepository) GetSubgroups(ctx context.Context, group *models.Group) ([]models.Group, error) {
+	var groups []models.Group
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Joins("JOIN group_nestings ON group_nestings.child_group_id = groups.id").Where("group_nestings.parent_group_id = ? AND groups.tenant_id = ?", group.ID, group.TenantID).Order("groups.name").Find(&groups)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Joins("JOIN group_nestings ON group_nestings.child_group_id = groups.id").Where("group_nestings.parent_group_id = ? AND groups.tenant_id = ?", group.ID, group.TenantID).Order("groups.name").Find(&groups).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[GroupRepository][GetSubgroups] Error in fetching subgroups: ", err)
+		return nil, err
+	}
+	return groups, nil
+}
+
+// GetEffectiveGroups returns the groups the user is a member of, directly or through nested groups
+func (r *groupRepository) GetEffectiveGroups(ctx context.Context, userID string) ([]models.Group, error) {
+	var groups []models.Group
+	tenantID := tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Raw(effectiveGroupsQuery, userID, tenantID).Scan(&groups)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Raw(effectiveGroupsQuery, userID, tenantID).Scan(&groups).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[GroupRepository][GetEffectiveGroups] Error in fetching effective groups: ", err)
+		return nil, err
+	}
+	return groups, nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
+)
+
+func TestGroupRepositoryScopesQueriesToTenant(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "tenant-a")
+	group := &models.Group{TenantID: "tenant-a", Name: "engineering"}
+	group.ID = 7
+
+	tests := []struct {
+		name      string
+		run       func(r models.GroupRepository) error
+		fragments []string
+	}{
+		{name: "GetGroups", run: func(r models.GroupRepository) error { _, err := r.GetGroups(ctx); return err }, fragments: []string{`FROM "groups"`, "tenant_id"}},
+		{name: "GetGroupByName", run: func(r models.GroupRepository) error { _, err := r.GetGroupByName(ctx, "engineering"); return err }, fragments: []string{`FROM "groups"`, "tenant_id"}},
+		{name: "UpdateGroup", run: func(r models.GroupRepository) error { return r.UpdateGroup(ctx, group) }, fragments: []string{`UPDATE "groups"`, "tenant_id"}},
+		{name: "GetMembers", run: func(r models.GroupRepository) error { _, err := r.GetMembers(ctx, group); return err }, fragments: []string{"JOIN group_members", "users.tenant_id"}},
+		{name: "GetSubgroups", run: func(r models.GroupRepository) error { _, err := r.GetSubgroups(ctx, group); return err }, fragments: []string{"JOIN group_nestings", "groups.tenant_id"}},
+		{name: "GetEffectiveGroups", run: func(r models.GroupRepository) error { _, err := r.GetEffectiveGroups(ctx, "user-1"); return err }, fragments: []string{"WITH RECURSIVE effective", "groups.tenant_id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, recorder := newDryRunDB(t)
+			_ = tt.run(NewGroupRepository(db))
+			recorder.requireTenantScoped(t, "tenant-a", "tenant-b", tt.fragments...)
+		})
+	}
+}
+
+func TestGroupRepositoryCreateGroupSetsTenant(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	group := &models.Group{TenantID: "tenant-b", Name: "engineering"}
+
+	if err := NewGroupRepository(db).CreateGroup(tenant.NewContext(context.Background(), "tenant-a"), group); err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+	if group.TenantID != "tenant-a" {
+		t.Errorf("CreateGroup TenantID = %q, want the request tenant %q", group.TenantID, "tenant-a")
+	}
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/mtnapm"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
+	"go.elastic.co/apm/v2"
+)
+
+type loginAttemptRepository struct {
+	database *gorm.DB
+}
+
+func NewLoginAttemptRepository(database *gorm.DB) models.LoginAttemptRepository {
+	return &loginAttemptRepository{
+		database: database,
+	}
+}
+
+// GetLoginAttempt returns the failed logins counted for the identifier, nil when there are none
+func (l *loginAttemptRepository) GetLoginAttempt(ctx context.Context, kind string, identifier string) (*models.LoginAttempt, error) {
+	var attempts []models.LoginAttempt
+	tenantID := tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := l.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("tenant_id = ? AND kind = ? AND identifier = ?", tenantID, kind, identifier).Limit(1).Find(&attempts)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := l.database.Where("tenant_id = ? AND kind = ? AND identifier = ?", tenantID, kind, identifier).Limit(1).Find(&attempts).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[LoginAttemptRepository][GetLoginAttempt] Error in fetching login attempt: ", err)
+		return nil, err
+	}
+	if len(attempts) == 0 {
+		return nil, nil
+	}
+
+	return &attempts[0], nil
+}
+
+// RecordLoginFailure counts a failed login for the identifier and returns the updated count. Failures before
+// windowStart are forgotten. The count is updated in a single statement so concurrent failures are all counted.
+func (l *loginAttemptRepository) RecordLoginFailure(ctx context.Context, kind string, identifier string, windowStart time.Time) (*models.LoginAttempt, error) {
+	attempt := &models.LoginAttempt{
+		TenantID:      tenant.ID(ctx),
+		Kind:          kind,
+		Identifier:    identifier,
+		Failures:      1,
+		LastFailureAt: time.Now(),
+	}
+	onConflict := clause.OnConflict{
+		Columns: []clause.Column{{Name: "tenant_id"}, {Name: "kind"}, {Name: "identifier"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":        gorm.Expr("CASE WHEN login_attempts.last_failure_at < ? THEN 1 ELSE login_attempts.failures + 1 END", windowStart),
+			"last_failure_at": attempt.LastFailureAt,
+		}),
+	}
+
+	//for fetching the database query
+	statement := l.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Clauses(onConflict, clause.Returning{}).Create(attempt)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := l.database.Clauses(onConflict, clause.Returning{}).Create(attempt).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[LoginAttemptRepository][RecordLoginFailure] Error in recording login failure: ", err)
+		return nil, err
+	}
+
+	return attempt, nil
+}
+
+// ResetLoginAttempts forgets the failed logins of the identifier, which lifts its lockout
+func (l *loginAttemptRepository) ResetLoginAttempts(ctx context.Context, kind string, identifier string) error {
+	tenantID := tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := l.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("tenant_id = ? AND kind = ? AND identifier = ?", tenantID, kind, identifier).Delete(&models.LoginAttempt{})
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := l.database.Where("tenant_id = ? AND kind = ? AND identifier = ?", tenantID, kind, identifier).Delete(&models.LoginAttempt{}).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[LoginAttemptRepository][ResetLoginAttempts] Error in resetting login attempts: ", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/consts"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/mtnapm"
+	"go.elastic.co/apm/v2"
+)
+
+type mfaRepository struct {
+	database *gorm.DB
+}
+
+func NewMFARepository(database *gorm.DB) models.MFARepository {
+	return &mfaRepository{
+		database: database,
+	}
+}
+
+// GetMFAFactor returns the TOTP factor of the user, nil when the user has none
+func (m *mfaRepository) GetMFAFactor(ctx context.Context, userID string) (*models.MFAFactor, error) {
+	var factors []models.MFAFactor
+
+	//for fetching the database query
+	statement := m.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("user_uuid = ?", userID).Limit(1).Find(&factors)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := m.database.Where("user_uuid = ?", userID).Limit(1).Find(&factors).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[MFARepository][GetMFAFactor] Error in fetching MFA factor: ", err)
+		return nil, err
+	}
+	if len(factors) == 0 {
+		return nil, nil
+	}
+
+	return &factors[0], nil
+}
+
+// SaveMFAFactor stores a new unconfirmed factor in place of an unconfirmed one the user may have, a confirmed
+// factor has to be deleted first
+func (m *mfaRepository) SaveMFAFactor(ctx context.Context, factor *models.MFAFactor) error {
+	//for fetching the database query
+	statement := m.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Create(factor)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	err := m.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_uuid = ? AND confirmed_at IS NULL", factor.UserUUID).Delete(&models.MFAFactor{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(factor).Error
+	})
+	if err != nil {
+		// Check if err is of type *pgconn.PgError and error code is 23505, which is the error code for unique_violation
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == consts.UniqueViolation {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", pgErr.Error())).Send()
+			log.Println("[MFARepository][SaveMFAFactor] MFA is already enabled: ", pgErr.Error())
+			return cerr.NewCustomErrorWithCodeAndOrigin("MFA is already enabled", cerr.DuplicateEntryErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[MFARepository][SaveMFAFactor] Error in saving MFA factor: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// ConfirmMFAFactor turns the factor on and replaces the recovery codes of the user
+func (m *mfaRepository) ConfirmMFAFactor(ctx context.Context, factor *models.MFAFactor, recoveryCodeHashes []string) error {
+	now := time.Now()
+	recoveryCodes := make([]models.RecoveryCode, 0, len(recoveryCodeHashes))
+	for _, codeHash := range recoveryCodeHashes {
+		recoveryCodes = append(recoveryCodes, models.RecoveryCode{UserUUID: factor.UserUUID, CodeHash: codeHash})
+	}
+
+	//for fetching the database query
+	statement := m.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(factor).Where("confirmed_at IS NULL").Update("confirmed_at", now)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	err := m.database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(factor).Where("confirmed_at IS NULL").Update("confirmed_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return cerr.NewCustomErrorWithCodeAndOrigin("MFA is already enabled", cerr.DuplicateEntryErrorCode, nil)
+		}
+		if err := tx.Unscoped().Where("user_uuid = ?", factor.UserUUID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&recoveryCodes).Error
+	})
+	if err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[MFARepository][ConfirmMFAFactor] Error in confirming MFA factor: ", err)
+		return err
+	}
+
+	factor.ConfirmedAt = &now
+	return nil
+}
+
+// UseTOTPStep records the time step of an accepted code. The update only succeeds for a step after the last
+// one used, so a code cannot be accepted twice even by concurrent requests.
+func (m *mfaRepository) UseTOTPStep(ctx context.Context, factor *models.MFAFactor, step int64) error {
+	//for fetching the database query
+	statement := m.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(factor).Where("last_used_step < ?", step).Update("last_used_step", step)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	result := m.database.Model(factor).Where("last_used_step < ?", step).Update("last_used_step", step)
+	if result.Error != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", result.Error.Error())).Send()
+		log.Println("[MFARepository][UseTOTPStep] Error in using TOTP step: ", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Code has already been used", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks the recovery code of the user as used, it fails for an unknown or used code
+func (m *mfaRepository) UseRecoveryCode(ctx context.Context, userID string, codeHash string) error {
+	//for fetching the database query
+	statement := m.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&models.RecoveryCode{}).Where("user_uuid = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).Update("used_at", time.Now())
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	result := m.database.Model(&models.RecoveryCode{}).Where("user_uuid = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).Update("used_at", time.Now())
+	if result.Error != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", result.Error.Error())).Send()
+		log.Println("[MFARepository][UseRecoveryCode] Error in using recovery code: ", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Invalid code", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	return nil
+}
+
+// DeleteMFAFactor turns MFA off for the user, the factor and the recovery codes are removed
+func (m *mfaRepository) DeleteMFAFactor(ctx context.Context, userID string) error {
+	//for fetching the database query
+	statement := m.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Unscoped().Where("user_uuid = ?", userID).Delete(&models.MFAFactor{})
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	err := m.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_uuid = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_uuid = ?", userID).Delete(&models.MFAFactor{}).Error
+	})
+	if err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[MFARepository][DeleteMFAFactor] Error in deleting MFA factor: ", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/consts"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/mtnapm"
+	"go.elastic.co/apm/v2"
+)
+
+type oauthClientRepository struct {
+	database *gorm.DB
+}
+
+func NewOAuthClientRepository(database *gorm.DB) models.OAuthClientRepository {
+	return &oauthClientRepository{
+		database: database,
+	}
+}
+
+func (o *oauthClientRepository) CreateClient(ctx context.Context, client *models.OAuthClient) error {
+	//for fetching the database query
+	statement := o.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Create(client)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := o.database.Create(client).Error; err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == consts.UniqueViolation {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", pgErr.Error())).Send()
+			log.Println("[OAuthClientRepository][CreateClient] Client already exists: ", pgErr.Error())
+			return cerr.NewCustomErrorWithCodeAndOrigin("Client already exists", cerr.DuplicateEntryErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[OAuthClientRepository][CreateClient] Error in creating client: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (o *oauthClientRepository) GetClientByClientID(ctx context.Context, clientID string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+
+	//for fetching the database query
+	statement := o.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("client_id = ?", clientID).First(&client)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := o.database.Where("client_id = ?", clientID).First(&client).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+			log.Println("[OAuthClientRepository][GetClientByClientID] Client not found: ", err)
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("Client not found", cerr.NotFoundErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[OAuthClientRepository][GetClientByClientID] Error in fetching client: ", err)
+		return nil, err
+	}
+	return &client, nil
+}
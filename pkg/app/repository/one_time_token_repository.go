@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/mtnapm"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
+	"go.elastic.co/apm/v2"
+)
+
+type oneTimeTokenRepository struct {
+	database *gorm.DB
+}
+
+func NewOneTimeTokenRepository(database *gorm.DB) models.OneTimeTokenRepository {
+	return &oneTimeTokenRepository{
+		database: database,
+	}
+}
+
+// CreateOneTimeToken stores the token and marks the unused tokens issued before it for the same user and
+// purpose as used, so only the latest token sent to the user works
+func (o *oneTimeTokenRepository) CreateOneTimeToken(ctx context.Context, token *models.OneTimeToken) error {
+	token.TenantID = tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := o.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Create(token)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	err := o.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.OneTimeToken{}).Where("user_uuid = ? AND purpose = ? AND used_at IS NULL", token.UserUUID, token.Purpose).Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+	if err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[OneTimeTokenRepository][CreateOneTimeToken] Error in creating one time token: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetOneTimeToken returns the token of the tenant without using it up, it fails for a used or expired token
+func (o *oneTimeTokenRepository) GetOneTimeToken(ctx context.Context, purpose string, tokenHash string) (*models.OneTimeToken, error) {
+	var token models.OneTimeToken
+	tenantID := tenant.ID(ctx)
+	now := time.Now()
+
+	//for fetching the database query
+	statement := o.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("tenant_id = ? AND purpose = ? AND token_hash = ? AND used_at IS NULL AND expires_at > ?", tenantID, purpose, tokenHash, now).First(&token)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := o.database.Where("tenant_id = ? AND purpose = ? AND token_hash = ? AND used_at IS NULL AND expires_at > ?", tenantID, purpose, tokenHash, now).First(&token).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+			log.Println("[OneTimeTokenRepository][GetOneTimeToken] One time token not found: ", err)
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid or expired token", cerr.InvalidRequestErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[OneTimeTokenRepository][GetOneTimeToken] Error in fetching one time token: ", err)
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// UseOneTimeToken marks the token as used and returns it. The update only succeeds for an unused and
+// unexpired token of the tenant, so a token cannot be redeemed twice even by concurrent requests.
+func (o *oneTimeTokenRepository) UseOneTimeToken(ctx context.Context, purpose string, tokenHash string) (*models.OneTimeToken, error) {
+	var token models.OneTimeToken
+	tenantID := tenant.ID(ctx)
+	now := time.Now()
+
+	//for fetching the database query
+	statement := o.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&token).Clauses(clause.Returning{}).
+			Where("tenant_id = ? AND purpose = ? AND token_hash = ? AND used_at IS NULL AND expires_at > ?", tenantID, purpose, tokenHash, now).
+			Update("used_at", now)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	result := o.database.Model(&token).Clauses(clause.Returning{}).
+		Where("tenant_id = ? AND purpose = ? AND token_hash = ? AND used_at IS NULL AND expires_at > ?", tenantID, purpose, tokenHash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", result.Error.Error())).Send()
+		log.Println("[OneTimeTokenRepository][UseOneTimeToken] Error in using one time token: ", result.Error)
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid or expired token", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	return &token, nil
+}
+
+// GetLatestOneTimeToken returns the token issued to the user last for the purpose whether or not it still works,
+// nil when none was issued
+func (o *oneTimeTokenRepository) GetLatestOneTimeToken(ctx context.Context, purpose string, userID string) (*models.OneTimeToken, error) {
+	var tokens []models.OneTimeToken
+	tenantID := tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := o.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("tenant_id = ? AND purpose = ? AND user_uuid = ?", tenantID, purpose, userID).Order("id DESC").Limit(1).Find(&tokens)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := o.database.Where("tenant_id = ? AND purpose = ? AND user_uuid = ?", tenantID, purpose, userID).Order("id DESC").Limit(1).Find(&tokens).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[OneTimeTokenRepository][GetLatestOneTimeToken] Error in fetching one time token: ", err)
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	return &tokens[0], nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/consts"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/mtnapm"
+	"go.elastic.co/apm/v2"
+)
+
+type organizationRepository struct {
+	database *gorm.DB
+}
+
+func NewOrganizationRepository(database *gorm.DB) models.OrganizationRepository {
+	return &organizationRepository{
+		database: database,
+	}
+}
+
+func (r *organizationRepository) CreateOrganization(ctx context.Context, organization *models.Organization) error {
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Create(organization)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Create(organization).Error; err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == consts.UniqueViolation {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", pgErr.Error())).Send()
+			log.Println("[OrganizationRepository][CreateOrganization] Organization already exists: ", pgErr.Error())
+			return cerr.NewCustomErrorWithCodeAndOrigin("Organization already exists", cerr.DuplicateEntryErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[OrganizationRepository][CreateOrganization] Error in creating organization: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// EnsureOrganization creates the organization unless one with its tenant id already exists
+func (r *organizationRepository) EnsureOrganization(ctx context.Context, organization *models.Organization) error {
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("tenant_id = ?", organization.TenantID).FirstOrCreate(organization)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Where("tenant_id = ?", organization.TenantID).FirstOrCreate(organization).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[OrganizationRepository][EnsureOrganization] Error in creating organization: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *organizationRepository) GetOrganizations(ctx context.Context) ([]models.Organization, error) {
+	var organizations []models.Organization
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("tenant_id").Find(&organizations)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Order("tenant_id").Find(&organizations).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[OrganizationRepository][GetOrganizations] Error in fetching organizations: ", err)
+		return nil, err
+	}
+	return organizations, nil
+}
+
+func (r *organizationRepository) GetOrganizationByTenantID(ctx context.Context, tenantID string) (*models.Organization, error) {
+	var organization models.Organization
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("tenant_id = ?", tenantID).First(&organization)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Where("tenant_id = ?", tenantID).First(&organization).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+			log.Println("[OrganizationRepository][GetOrganizationByTenantID] Organization not found: ", err)
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("Unknown tenant", cerr.NotFoundErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[OrganizationRepository][GetOrganizationByTenantID] Error in fetching organization: ", err)
+		return nil, err
+	}
+	return &organization, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/mtnapm"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
+	"go.elastic.co/apm/v2"
+)
+
+type otpCodeRepository struct {
+	database *gorm.DB
+}
+
+func NewOTPCodeRepository(database *gorm.DB) models.OTPCodeRepository {
+	return &otpCodeRepository{
+		database: database,
+	}
+}
+
+// CreateOTPCode stores the code and marks the unused codes sent to the user before it as used, so only the
+// latest code works
+func (o *otpCodeRepository) CreateOTPCode(ctx context.Context, code *models.OTPCode) error {
+	code.TenantID = tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := o.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Create(code)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	err := o.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.OTPCode{}).Where("user_uuid = ? AND used_at IS NULL", code.UserUUID).Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(code).Error
+	})
+	if err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[OTPCodeRepository][CreateOTPCode] Error in creating OTP code: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetLatestOTPCode returns the code sent to the user last whether or not it still works, nil when none was sent
+func (o *otpCodeRepository) GetLatestOTPCode(ctx context.Context, userID string) (*models.OTPCode, error) {
+	var codes []models.OTPCode
+	tenantID := tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := o.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("tenant_id = ? AND user_uuid = ?", tenantID, userID).Order("id DESC").Limit(1).Find(&codes)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := o.database.Where("tenant_id = ? AND user_uuid = ?", tenantID, userID).Order("id DESC").Limit(1).Find(&codes).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[OTPCodeRepository][GetLatestOTPCode] Error in fetching OTP code: ", err)
+		return nil, err
+	}
+	if len(codes) == 0 {
+		return nil, nil
+	}
+
+	return &codes[0], nil
+}
+
+// RecordOTPFailure counts a wrong guess of the code, the code is used up with the guess that reaches
+// maxAttempts. The count is updated in a single statement so concurrent guesses are all counted.
+func (o *otpCodeRepository) RecordOTPFailure(ctx context.Context, code *models.OTPCode, maxAttempts int) error {
+	updates := map[string]interface{}{
+		"attempts": gorm.Expr("attempts + 1"),
+		"used_at":  gorm.Expr("CASE WHEN attempts + 1 >= ? THEN ?::timestamptz ELSE used_at END", maxAttempts, time.Now()),
+	}
+
+	//for fetching the database query
+	statement := o.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(code).Where("used_at IS NULL").Updates(updates)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := o.database.Model(code).Where("used_at IS NULL").Updates(updates).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[OTPCodeRepository][RecordOTPFailure] Error in recording OTP failure: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// UseOTPCode marks the code as used, it fails when the code was used up in the meantime
+func (o *otpCodeRepository) UseOTPCode(ctx context.Context, code *models.OTPCode) error {
+	//for fetching the database query
+	statement := o.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(code).Where("used_at IS NULL").Update("used_at", time.Now())
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	result := o.database.Model(code).Where("used_at IS NULL").Update("used_at", time.Now())
+	if result.Error != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", result.Error.Error())).Send()
+		log.Println("[OTPCodeRepository][UseOTPCode] Error in using OTP code: ", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Invalid or expired code", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/mtnapm"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
+	"go.elastic.co/apm/v2"
+)
+
+type personalAccessTokenRepository struct {
+	database *gorm.DB
+}
+
+func NewPersonalAccessTokenRepository(database *gorm.DB) models.PersonalAccessTokenRepository {
+	return &personalAccessTokenRepository{
+		database: database,
+	}
+}
+
+func (p *personalAccessTokenRepository) CreatePersonalAccessToken(ctx context.Context, token *models.PersonalAccessToken) error {
+	token.TenantID = tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := p.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Create(token)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := p.database.Create(token).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[PersonalAccessTokenRepository][CreatePersonalAccessToken] Error in creating personal access token: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetPersonalAccessTokens returns the tokens of the user that have not been revoked, newest first
+func (p *personalAccessTokenRepository) GetPersonalAccessTokens(ctx context.Context, userID string) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	tenantID := tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := p.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("tenant_id = ? AND user_uuid = ? AND revoked_at IS NULL", tenantID, userID).Order("id DESC").Find(&tokens)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := p.database.Where("tenant_id = ? AND user_uuid = ? AND revoked_at IS NULL", tenantID, userID).Order("id DESC").Find(&tokens).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[PersonalAccessTokenRepository][GetPersonalAccessTokens] Error in fetching personal access tokens: ", err)
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// GetPersonalAccessTokenByHash returns the token of the tenant with the hash whether or not it still works
+func (p *personalAccessTokenRepository) GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	tenantID := tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := p.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("tenant_id = ? AND token_hash = ?", tenantID, tokenHash).First(&token)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := p.database.Where("tenant_id = ? AND token_hash = ?", tenantID, tokenHash).First(&token).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+			log.Println("[PersonalAccessTokenRepository][GetPersonalAccessTokenByHash] Personal access token not found: ", err)
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid token", cerr.InvalidRequestErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[PersonalAccessTokenRepository][GetPersonalAccessTokenByHash] Error in fetching personal access token: ", err)
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// TouchPersonalAccessToken records that the token is used now. Uses are only recorded when the last one was
+// before notBefore, so a busy token does not write on every request.
+func (p *personalAccessTokenRepository) TouchPersonalAccessToken(ctx context.Context, token *models.PersonalAccessToken, notBefore time.Time) error {
+	now := time.Now()
+
+	//for fetching the database query
+	statement := p.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(token).Where("last_used_at IS NULL OR last_used_at < ?", notBefore).Update("last_used_at", now)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := p.database.Model(token).Where("last_used_at IS NULL OR last_used_at < ?", notBefore).Update("last_used_at", now).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[PersonalAccessTokenRepository][TouchPersonalAccessToken] Error in touching personal access token: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// RevokePersonalAccessToken revokes a token of the user, tokens of other users are reported as not found
+func (p *personalAccessTokenRepository) RevokePersonalAccessToken(ctx context.Context, userID string, tokenID string) error {
+	tenantID := tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := p.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&models.PersonalAccessToken{}).Where("tenant_id = ? AND user_uuid = ? AND uuid = ? AND revoked_at IS NULL", tenantID, userID, tokenID).Update("revoked_at", time.Now())
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	result := p.database.Model(&models.PersonalAccessToken{}).Where("tenant_id = ? AND user_uuid = ? AND uuid = ? AND revoked_at IS NULL", tenantID, userID, tokenID).Update("revoked_at", time.Now())
+	if result.Error != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", result.Error.Error())).Send()
+		log.Println("[PersonalAccessTokenRepository][RevokePersonalAccessToken] Error in revoking personal access token: ", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Token not found", cerr.NotFoundErrorCode, nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/mtnapm"
+	"go.elastic.co/apm/v2"
+)
+
+type refreshTokenRepository struct {
+	database *gorm.DB
+}
+
+func NewRefreshTokenRepository(database *gorm.DB) models.RefreshTokenRepository {
+	return &refreshTokenRepository{
+		database: database,
+	}
+}
+
+func (r *refreshTokenRepository) CreateRefreshToken(ctx context.Context, refreshToken *models.RefreshToken) error {
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Create(refreshToken)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Create(refreshToken).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[RefreshTokenRepository][CreateRefreshToken] Error in creating refresh token: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *refreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var refreshToken models.RefreshToken
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("token_hash = ?", tokenHash).First(&refreshToken)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Where("token_hash = ?", tokenHash).First(&refreshToken).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+			log.Println("[RefreshTokenRepository][GetRefreshTokenByHash] Refresh token not found: ", err)
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid refresh token", cerr.InvalidRequestErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[RefreshTokenRepository][GetRefreshTokenByHash] Error in fetching refresh token: ", err)
+		return nil, err
+	}
+	return &refreshToken, nil
+}
+
+// RotateRefreshToken marks the current token as rotated and stores its successor in a single transaction.
+// The update only succeeds while the current token is still unrevoked, so two concurrent refreshes with the
+// same token cannot both obtain a successor.
+func (r *refreshTokenRepository) RotateRefreshToken(ctx context.Context, current *models.RefreshToken, next *models.RefreshToken) error {
+	now := time.Now()
+	updates := map[string]interface{}{"revoked_at": now, "revoked_reason": models.RefreshTokenRevokedRotated}
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&models.RefreshToken{}).Where("id = ? AND revoked_at IS NULL", current.ID).Updates(updates)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	err := r.database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RefreshToken{}).Where("id = ? AND revoked_at IS NULL", current.ID).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return cerr.NewCustomErrorWithCodeAndOrigin("Refresh token has already been used", cerr.InvalidRequestErrorCode, nil)
+		}
+		return tx.Create(next).Error
+	})
+	if err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[RefreshTokenRepository][RotateRefreshToken] Error in rotating refresh token: ", err)
+		return err
+	}
+
+	current.RevokedAt = &now
+	current.RevokedReason = models.RefreshTokenRevokedRotated
+	return nil
+}
+
+func (r *refreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID, reason string) error {
+	updates := map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason}
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&models.RefreshToken{}).Where("family_id = ? AND revoked_at IS NULL", familyID).Updates(updates)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Model(&models.RefreshToken{}).Where("family_id = ? AND revoked_at IS NULL", familyID).Updates(updates).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[RefreshTokenRepository][RevokeRefreshTokenFamily] Error in revoking refresh token family: ", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordedQuery is a statement built by a repository, with its bind variables
+type recordedQuery struct {
+	SQL  string
+	Vars []interface{}
+}
+
+// queryRecorder collects the statements of a dry run database, nothing is sent to Postgres
+type queryRecorder struct {
+	queries []recordedQuery
+}
+
+// newDryRunDB returns a Postgres flavoured database that only builds statements and records them. Nothing reaches
+// Postgres, so the repositories return empty results, and scans of raw queries fail with gorm.ErrDryRunModeUnsupported.
+func newDryRunDB(t *testing.T) (*gorm.DB, *queryRecorder) {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+
+	recorder := &queryRecorder{}
+	record := func(tx *gorm.DB) {
+		if tx.Statement.SQL.Len() == 0 {
+			return
+		}
+		recorder.queries = append(recorder.queries, recordedQuery{SQL: tx.Statement.SQL.String(), Vars: append([]interface{}{}, tx.Statement.Vars...)})
+	}
+	for name, err := range map[string]error{
+		"query":  db.Callback().Query().After("gorm:query").Register("test:record_query", record),
+		"row":    db.Callback().Row().After("gorm:row").Register("test:record_row", record),
+		"raw":    db.Callback().Raw().After("gorm:raw").Register("test:record_raw", record),
+		"create": db.Callback().Create().After("gorm:create").Register("test:record_create", record),
+		"update": db.Callback().Update().After("gorm:update").Register("test:record_update", record),
+		"delete": db.Callback().Delete().After("gorm:delete").Register("test:record_delete", record),
+	} {
+		if err != nil {
+			t.Fatalf("registering the %s callback: %v", name, err)
+		}
+	}
+
+	return db, recorder
+}
+
+// containing returns the recorded queries whose SQL contains every fragment
+func (r *queryRecorder) containing(fragments ...string) []recordedQuery {
+	var matches []recordedQuery
+	for _, query := range r.queries {
+		matched := true
+		for _, fragment := range fragments {
+			if !strings.Contains(query.SQL, fragment) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, query)
+		}
+	}
+	return matches
+}
+
+// requireTenantScoped fails the test unless a query containing the fragments binds the tenant and none of the
+// queries bind the other tenant
+func (r *queryRecorder) requireTenantScoped(t *testing.T, tenantID string, otherTenantID string, fragments ...string) {
+	t.Helper()
+
+	matches := r.containing(fragments...)
+	if len(matches) == 0 {
+		t.Fatalf("no query contains %q, recorded %v", fragments, r.queries)
+	}
+	for _, query := range matches {
+		if !bindsValue(query, tenantID) {
+			t.Errorf("query %q binds %v, want the tenant %q", query.SQL, query.Vars, tenantID)
+		}
+	}
+	for _, query := range r.queries {
+		if bindsValue(query, otherTenantID) {
+			t.Errorf("query %q binds the other tenant %q", query.SQL, otherTenantID)
+		}
+	}
+}
+
+func bindsValue(query recordedQuery, value string) bool {
+	for _, v := range query.Vars {
+		if fmt.Sprint(v) == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+)
+
+// memoryRevocationRepository keeps the revocation list in process, it is meant for local development
+// and single instance deployments since entries are neither shared nor persisted
+type memoryRevocationRepository struct {
+	mu      sync.RWMutex
+	entries map[string]time.Time
+}
+
+func NewMemoryRevocationRepository() models.RevocationRepository {
+	return &memoryRevocationRepository{
+		entries: make(map[string]time.Time),
+	}
+}
+
+func (m *memoryRevocationRepository) RevokeToken(ctx context.Context, kind string, value string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := kind + ":" + value
+	if current, ok := m.entries[key]; !ok || expiresAt.After(current) {
+		m.entries[key] = expiresAt
+	}
+	return nil
+}
+
+func (m *memoryRevocationRepository) IsRevoked(ctx context.Context, jti string, sid string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	for _, key := range []string{models.RevocationKindJTI + ":" + jti, models.RevocationKindSID + ":" + sid} {
+		if expiresAt, ok := m.entries[key]; ok && expiresAt.After(now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memoryRevocationRepository) PruneExpired(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var pruned int64
+	now := time.Now()
+	for key, expiresAt := range m.entries {
+		if !expiresAt.After(now) {
+			delete(m.entries, key)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+)
+
+func TestMemoryRevocationRepository(t *testing.T) {
+	ctx := context.Background()
+	repository := NewMemoryRevocationRepository()
+	now := time.Now()
+
+	revoke := func(kind string, value string, expiresAt time.Time) {
+		t.Helper()
+		if err := repository.RevokeToken(ctx, kind, value, expiresAt); err != nil {
+			t.Fatalf("RevokeToken(%s, %s) error = %v", kind, value, err)
+		}
+	}
+	revoke(models.RevocationKindJTI, "jti-1", now.Add(time.Hour))
+	revoke(models.RevocationKindSID, "sid-1", now.Add(time.Hour))
+	revoke(models.RevocationKindJTI, "jti-expired", now.Add(-time.Minute))
+	// Revoking again with an earlier expiry must not shorten the entry
+	revoke(models.RevocationKindSID, "sid-1", now.Add(-time.Minute))
+
+	tests := []struct {
+		name string
+		jti  string
+		sid  string
+		want bool
+	}{
+		{name: "revoked jti", jti: "jti-1", sid: "sid-2", want: true},
+		{name: "revoked sid", jti: "jti-2", sid: "sid-1", want: true},
+		{name: "jti is not matched against sids", jti: "sid-1", sid: "sid-2", want: false},
+		{name: "sid is not matched against jtis", jti: "jti-2", sid: "jti-1", want: false},
+		{name: "expired entry", jti: "jti-expired", sid: "sid-2", want: false},
+		{name: "not revoked", jti: "jti-2", sid: "sid-2", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revoked, err := repository.IsRevoked(ctx, tt.jti, tt.sid)
+			if err != nil {
+				t.Fatalf("IsRevoked() error = %v", err)
+			}
+			if revoked != tt.want {
+				t.Errorf("IsRevoked(%q, %q) = %v, want %v", tt.jti, tt.sid, revoked, tt.want)
+			}
+		})
+	}
+
+	pruned, err := repository.PruneExpired(ctx)
+	if err != nil {
+		t.Fatalf("PruneExpired() error = %v", err)
+	}
+	if pruned != 1 {
+		t.Errorf("PruneExpired() = %d, want 1", pruned)
+	}
+}
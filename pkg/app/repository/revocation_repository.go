@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/mtnapm"
+	"go.elastic.co/apm/v2"
+)
+
+type revocationRepository struct {
+	database *gorm.DB
+}
+
+func NewRevocationRepository(database *gorm.DB) models.RevocationRepository {
+	return &revocationRepository{
+		database: database,
+	}
+}
+
+func (r *revocationRepository) RevokeToken(ctx context.Context, kind string, value string, expiresAt time.Time) error {
+	revokedToken := &models.RevokedToken{
+		Kind:      kind,
+		Value:     value,
+		ExpiresAt: expiresAt,
+	}
+
+	// Revoking the same id twice keeps the later expiry
+	onConflict := clause.OnConflict{
+		Columns:   []clause.Column{{Name: "kind"}, {Name: "value"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"expires_at": gorm.Expr("GREATEST(revoked_tokens.expires_at, excluded.expires_at)"), "deleted_at": nil}),
+	}
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Clauses(onConflict).Create(revokedToken)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Clauses(onConflict).Create(revokedToken).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[RevocationRepository][RevokeToken] Error in revoking token: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *revocationRepository) IsRevoked(ctx context.Context, jti string, sid string) (bool, error) {
+	var count int64
+	now := time.Now()
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&models.RevokedToken{}).
+			Where("expires_at > ? AND ((kind = ? AND value = ?) OR (kind = ? AND value = ?))", now, models.RevocationKindJTI, jti, models.RevocationKindSID, sid).
+			Count(&count)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	err := r.database.Model(&models.RevokedToken{}).
+		Where("expires_at > ? AND ((kind = ? AND value = ?) OR (kind = ? AND value = ?))", now, models.RevocationKindJTI, jti, models.RevocationKindSID, sid).
+		Count(&count).Error
+	if err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[RevocationRepository][IsRevoked] Error in checking revocation: ", err)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// PruneExpired permanently removes entries whose tokens can no longer be presented
+func (r *revocationRepository) PruneExpired(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Unscoped().Where("expires_at <= ?", now).Delete(&models.RevokedToken{})
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	result := r.database.Unscoped().Where("expires_at <= ?", now).Delete(&models.RevokedToken{})
+	if result.Error != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", result.Error.Error())).Send()
+		log.Println("[RevocationRepository][PruneExpired] Error in pruning revoked tokens: ", result.Error)
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/consts"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/mtnapm"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
+	"go.elastic.co/apm/v2"
+)
+
+type roleRepository struct {
+	database *gorm.DB
+}
+
+func NewRoleRepository(database *gorm.DB) models.RoleRepository {
+	return &roleRepository{
+		database: database,
+	}
+}
+
+// SeedRoles makes sure the permissions exist and that the admin role holds all of them, it is safe to run on
+// every startup. The admin role has no tenant and is shared by every tenant.
+func (r *roleRepository) SeedRoles(ctx context.Context, permissions []string, adminRole string) error {
+	err := r.database.Transaction(func(tx *gorm.DB) error {
+		seeded := make([]models.Permission, 0, len(permissions))
+		for _, name := range permissions {
+			permission := models.Permission{Name: name}
+			if err := tx.Where("name = ?", name).FirstOrCreate(&permission).Error; err != nil {
+				return err
+			}
+			seeded = append(seeded, permission)
+		}
+
+		role := models.Role{Name: adminRole, Description: "Holds every permission"}
+		if err := tx.Where("tenant_id = '' AND name = ?", adminRole).FirstOrCreate(&role).Error; err != nil {
+			return err
+		}
+		return tx.Model(&role).Association("Permissions").Append(seeded)
+	})
+	if err != nil {
+		log.Println("[RoleRepository][SeedRoles] Error in seeding roles: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// CreateRole creates a custom role in the tenant of the request, the names of the shared roles are taken in
+// every tenant
+func (r *roleRepository) CreateRole(ctx context.Context, role *models.Role, permissions []string) error {
+	role.TenantID = tenant.ID(ctx)
+
+	var shared int64
+	if err := r.database.Model(&models.Role{}).Where("tenant_id = '' AND name = ?", role.Name).Count(&shared).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[RoleRepository][CreateRole] Error in fetching shared roles: ", err)
+		return err
+	}
+	if shared > 0 {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Role already exists", cerr.DuplicateEntryErrorCode, nil)
+	}
+
+	// Every permission must exist, roles cannot invent new ones
+	if len(permissions) > 0 {
+		var found []models.Permission
+		if err := r.database.Where("name IN ?", permissions).Find(&found).Error; err != nil {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+			log.Println("[RoleRepository][CreateRole] Error in fetching permissions: ", err)
+			return err
+		}
+		if len(found) != len(permissions) {
+			return cerr.NewCustomErrorWithCodeAndOrigin("Unknown permission", cerr.InvalidRequestErrorCode, nil)
+		}
+		role.Permissions = found
+	}
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Omit("Permissions.*").Create(role)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Omit("Permissions.*").Create(role).Error; err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == consts.UniqueViolation {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", pgErr.Error())).Send()
+			log.Println("[RoleRepository][CreateRole] Role already exists: ", pgErr.Error())
+			return cerr.NewCustomErrorWithCodeAndOrigin("Role already exists", cerr.DuplicateEntryErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[RoleRepository][CreateRole] Error in creating role: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetRoles returns the roles of the tenant of the request and the shared roles
+func (r *roleRepository) GetRoles(ctx context.Context) ([]models.Role, error) {
+	var roles []models.Role
+	tenantIDs := []string{tenant.ID(ctx), ""}
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Preload("Permissions").Where("tenant_id IN ?", tenantIDs).Order("name").Find(&roles)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Preload("Permissions").Where("tenant_id IN ?", tenantIDs).Order("name").Find(&roles).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[RoleRepository][GetRoles] Error in fetching roles: ", err)
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GetRoleByName looks the role up in the tenant of the request and the shared roles
+func (r *roleRepository) GetRoleByName(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	tenantIDs := []string{tenant.ID(ctx), ""}
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Preload("Permissions").Where("tenant_id IN ? AND name = ?", tenantIDs, name).First(&role)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Preload("Permissions").Where("tenant_id IN ? AND name = ?", tenantIDs, name).First(&role).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+			log.Println("[RoleRepository][GetRoleByName] Role not found: ", err)
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("Role not found", cerr.NotFoundErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[RoleRepository][GetRoleByName] Error in fetching role: ", err)
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) GrantRole(ctx context.Context, userID uuid.UUID, role *models.Role) error {
+	userRole := &models.UserRole{UserUUID: userID, RoleID: role.ID, CreatedAt: time.Now()}
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(userRole)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	// Granting a role the user already holds is not an error
+	if err := r.database.Clauses(clause.OnConflict{DoNothing: true}).Create(userRole).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[RoleRepository][GrantRole] Error in granting role: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *roleRepository) RevokeRole(ctx context.Context, userID uuid.UUID, role *models.Role) error {
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("user_uuid = ? AND role_id = ?", userID, role.ID).Delete(&models.UserRole{})
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	result := r.database.Where("user_uuid = ? AND role_id = ?", userID, role.ID).Delete(&models.UserRole{})
+	if result.Error != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", result.Error.Error())).Send()
+		log.Println("[RoleRepository][RevokeRole] Error in revoking role: ", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return cerr.NewCustomErrorWithCodeAndOrigin("User does not hold the role", cerr.NotFoundErrorCode, nil)
+	}
+
+	return nil
+}
+
+// GetRoleMembers returns the users of the tenant of the request holding the role, shared roles are held by users
+// of every tenant
+func (r *roleRepository) GetRoleMembers(ctx context.Context, role *models.Role) ([]models.User, error) {
+	var users []models.User
+	tenantID := tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Joins("JOIN user_roles ON user_roles.user_uuid = users.uuid").Where("user_roles.role_id = ? AND users.tenant_id = ?", role.ID, tenantID).Order("users.user_name").Find(&users)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Joins("JOIN user_roles ON user_roles.user_uuid = users.uuid").Where("user_roles.role_id = ? AND users.tenant_id = ?", role.ID, tenantID).Order("users.user_name").Find(&users).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[RoleRepository][GetRoleMembers] Error in fetching role members: ", err)
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetUserRoles returns the roles of the user among the roles of the tenant of the request and the shared roles
+func (r *roleRepository) GetUserRoles(ctx context.Context, userID string) ([]models.Role, error) {
+	var roles []models.Role
+	tenantIDs := []string{tenant.ID(ctx), ""}
+
+	//for fetching the database query
+	statement := r.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Preload("Permissions").Joins("JOIN user_roles ON user_roles.role_id = roles.id").Where("user_roles.user_uuid = ? AND roles.tenant_id IN ?", userID, tenantIDs).Find(&roles)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := r.database.Preload("Permissions").Joins("JOIN user_roles ON user_roles.role_id = roles.id").Where("user_roles.user_uuid = ? AND roles.tenant_id IN ?", userID, tenantIDs).Find(&roles).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[RoleRepository][GetUserRoles] Error in fetching user roles: ", err)
+		return nil, err
+	}
+	return roles, nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
+)
+
+func TestRoleRepositoryScopesQueriesToTenant(t *testing.T) {
+	// An admin of tenant-a holds the shared admin role, which is also held by users of tenant-b
+	ctx := tenant.NewContext(context.Background(), "tenant-a")
+	sharedRole := &models.Role{Name: models.RoleAdmin}
+	sharedRole.ID = 1
+
+	tests := []struct {
+		name      string
+		run       func(r models.RoleRepository) error
+		fragments []string
+	}{
+		{name: "GetRoleMembers of a shared role", run: func(r models.RoleRepository) error { _, err := r.GetRoleMembers(ctx, sharedRole); return err }, fragments: []string{"JOIN user_roles", "users.tenant_id"}},
+		{name: "GetRoles", run: func(r models.RoleRepository) error { _, err := r.GetRoles(ctx); return err }, fragments: []string{`FROM "roles"`, "tenant_id IN"}},
+		{name: "GetRoleByName", run: func(r models.RoleRepository) error { _, err := r.GetRoleByName(ctx, "auditor"); return err }, fragments: []string{`FROM "roles"`, "tenant_id IN"}},
+		{name: "GetUserRoles", run: func(r models.RoleRepository) error { _, err := r.GetUserRoles(ctx, "user-1"); return err }, fragments: []string{"JOIN user_roles", "roles.tenant_id IN"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, recorder := newDryRunDB(t)
+			_ = tt.run(NewRoleRepository(db))
+			recorder.requireTenantScoped(t, "tenant-a", "tenant-b", tt.fragments...)
+		})
+	}
+}
+
+func TestRoleRepositoryCreateRoleSetsTenant(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	role := &models.Role{TenantID: "tenant-b", Name: "auditor"}
+
+	if err := NewRoleRepository(db).CreateRole(tenant.NewContext(context.Background(), "tenant-a"), role, nil); err != nil {
+		t.Fatalf("CreateRole: %v", err)
+	}
+	if role.TenantID != "tenant-a" {
+		t.Errorf("CreateRole TenantID = %q, want the request tenant %q", role.TenantID, "tenant-a")
+	}
+	if len(recorder.containing(`INSERT INTO "roles"`)) == 0 {
+		t.Errorf("CreateRole did not insert the role, recorded %v", recorder.queries)
+	}
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/mtnapm"
+	"go.elastic.co/apm/v2"
+)
+
+type sessionRepository struct {
+	database *gorm.DB
+}
+
+func NewSessionRepository(database *gorm.DB) models.SessionRepository {
+	return &sessionRepository{
+		database: database,
+	}
+}
+
+func (s *sessionRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	//for fetching the database query
+	statement := s.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Create(session)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := s.database.Create(session).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[SessionRepository][CreateSession] Error in creating session: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *sessionRepository) GetSessionByID(ctx context.Context, sessionID string) (*models.Session, error) {
+	var session models.Session
+
+	//for fetching the database query
+	statement := s.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("uuid = ?", sessionID).First(&session)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := s.database.Where("uuid = ?", sessionID).First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+			log.Println("[SessionRepository][GetSessionByID] Session not found: ", err)
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("Session not found", cerr.NotFoundErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[SessionRepository][GetSessionByID] Error in fetching session: ", err)
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *sessionRepository) GetActiveSessionsByUserID(ctx context.Context, userID string) ([]models.Session, error) {
+	var sessions []models.Session
+
+	//for fetching the database query
+	statement := s.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("user_uuid = ? AND ended_at IS NULL", userID).Order("last_seen_at DESC").Find(&sessions)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := s.database.Where("user_uuid = ? AND ended_at IS NULL", userID).Order("last_seen_at DESC").Find(&sessions).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[SessionRepository][GetActiveSessionsByUserID] Error in fetching sessions: ", err)
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (s *sessionRepository) TouchSession(ctx context.Context, sessionID string) error {
+	now := time.Now()
+
+	//for fetching the database query
+	statement := s.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&models.Session{}).Where("uuid = ? AND ended_at IS NULL", sessionID).Update("last_seen_at", now)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := s.database.Model(&models.Session{}).Where("uuid = ? AND ended_at IS NULL", sessionID).Update("last_seen_at", now).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[SessionRepository][TouchSession] Error in updating session: ", err)
+		return err
+	}
+	return nil
+}
+
+func (s *sessionRepository) EndSession(ctx context.Context, sessionID string) error {
+	now := time.Now()
+
+	//for fetching the database query
+	statement := s.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&models.Session{}).Where("uuid = ? AND ended_at IS NULL", sessionID).Update("ended_at", now)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := s.database.Model(&models.Session{}).Where("uuid = ? AND ended_at IS NULL", sessionID).Update("ended_at", now).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[SessionRepository][EndSession] Error in ending session: ", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/jwt"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/mtnapm"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/utils"
+	"go.elastic.co/apm/v2"
+)
+
+// encryptedKeyPrefix marks private keys stored encrypted with AES-GCM
+const encryptedKeyPrefix = "aes-gcm:"
+
+type signingKeyRepository struct {
+	database      *gorm.DB
+	encryptionKey []byte
+}
+
+// NewSigningKeyRepository returns a key store encrypting private keys with encryptionKey, without a key rotated
+// keys cannot be saved
+func NewSigningKeyRepository(database *gorm.DB, encryptionKey []byte) jwt.KeyStore {
+	return &signingKeyRepository{
+		database:      database,
+		encryptionKey: encryptionKey,
+	}
+}
+
+func (s *signingKeyRepository) GetSigningKeys(ctx context.Context) ([]*jwt.SigningKey, error) {
+	var rows []models.SigningKey
+
+	//for fetching the database query
+	statement := s.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("created_at").Find(&rows)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := s.database.Order("created_at").Find(&rows).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[SigningKeyRepository][GetSigningKeys] Error in fetching signing keys: ", err)
+		return nil, err
+	}
+
+	keys := make([]*jwt.SigningKey, 0, len(rows))
+	for _, row := range rows {
+		key := &jwt.SigningKey{ID: row.KID, Algorithm: row.Algorithm, Configured: true}
+		if row.PrivateKeyPEM != "" {
+			data, err := s.privateKeyPEM(row)
+			if err != nil {
+				log.Println("[SigningKeyRepository][GetSigningKeys] Error in decrypting signing key ", row.KID, ": ", err)
+				continue
+			}
+			private, err := jwt.ParsePrivateKeyPEM(data)
+			if err != nil {
+				log.Println("[SigningKeyRepository][GetSigningKeys] Error in parsing signing key ", row.KID, ": ", err)
+				continue
+			}
+			key, err = jwt.NewSigningKey(row.KID, row.Algorithm, private)
+			if err != nil {
+				log.Println("[SigningKeyRepository][GetSigningKeys] Error in loading signing key ", row.KID, ": ", err)
+				continue
+			}
+		}
+		key.Status = row.Status
+		key.CreatedAt = row.CreatedAt
+		key.RetiredAt = row.RetiredAt
+		key.ExpiresAt = row.ExpiresAt
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func (s *signingKeyRepository) SaveSigningKey(ctx context.Context, key *jwt.SigningKey) error {
+	row := &models.SigningKey{
+		KID:       key.ID,
+		Algorithm: key.Algorithm,
+		Status:    key.Status,
+		RetiredAt: key.RetiredAt,
+		ExpiresAt: key.ExpiresAt,
+	}
+
+	// The material of the configured key stays in its PEM file, that of rotated keys is only stored encrypted
+	if !key.Configured {
+		if s.encryptionKey == nil {
+			return fmt.Errorf("SIGNING_KEY_ENCRYPTION_KEY is not set, rotated signing keys cannot be stored")
+		}
+		data, err := jwt.MarshalPrivateKeyPEM(key.Private)
+		if err != nil {
+			log.Println("[SigningKeyRepository][SaveSigningKey] Error in encoding signing key: ", err)
+			return err
+		}
+		encrypted, err := utils.Encrypt(s.encryptionKey, data, []byte(key.ID))
+		if err != nil {
+			log.Println("[SigningKeyRepository][SaveSigningKey] Error in encrypting signing key: ", err)
+			return err
+		}
+		row.PrivateKeyPEM = encryptedKeyPrefix + encrypted
+	}
+
+	onConflict := clause.OnConflict{
+		Columns:   []clause.Column{{Name: "kid"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "retired_at", "expires_at", "updated_at"}),
+	}
+
+	//for fetching the database query
+	statement := s.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Clauses(onConflict).Create(row)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := s.database.Clauses(onConflict).Create(row).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[SigningKeyRepository][SaveSigningKey] Error in saving signing key: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// privateKeyPEM returns the PEM of a stored key, the kid is bound to the ciphertext so keys cannot be swapped
+func (s *signingKeyRepository) privateKeyPEM(row models.SigningKey) ([]byte, error) {
+	if !strings.HasPrefix(row.PrivateKeyPEM, encryptedKeyPrefix) {
+		log.Println("[SigningKeyRepository][privateKeyPEM] Signing key is stored unencrypted, rotate it: ", row.KID)
+		return []byte(row.PrivateKeyPEM), nil
+	}
+	if s.encryptionKey == nil {
+		return nil, fmt.Errorf("SIGNING_KEY_ENCRYPTION_KEY is not set")
+	}
+	return utils.Decrypt(s.encryptionKey, strings.TrimPrefix(row.PrivateKeyPEM, encryptedKeyPrefix), []byte(row.KID))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgconn"
@@ -13,9 +14,16 @@ import (
 	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/common/consts"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/common/mtnapm"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
 	"go.elastic.co/apm/v2"
 )
 
+// profileColumns are the columns UpdateUser saves
+var profileColumns = []string{"display_name", "email", "email_verified_at", "phone_number", "first_name", "last_name", "locale", "timezone", "avatar_url"}
+
+// likeEscaper escapes the wildcards of a LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 type userRepository struct {
 	database *gorm.DB
 }
@@ -26,15 +34,12 @@ func NewUserRepository(database *gorm.DB) models.UserRepository {
 	}
 }
 
-func (u *userRepository) RegisterUser(ctx context.Context, userName string, password string) (string, error) {
+func (u *userRepository) RegisterUser(ctx context.Context, user *models.User) (string, error) {
 
 	localUTCTime := time.Now()
-	user := &models.User{
-		UserName:  userName,
-		Password:  password,
-		CreatedAt: localUTCTime,
-		UpdatedAt: localUTCTime,
-	}
+	user.TenantID = tenant.ID(ctx)
+	user.CreatedAt = localUTCTime
+	user.UpdatedAt = localUTCTime
 
 	//for fetching the database query
 	statement := u.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
@@ -49,7 +54,7 @@ func (u *userRepository) RegisterUser(ctx context.Context, userName string, pass
 		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == consts.UniqueViolation {
 			apm.CaptureError(ctx, fmt.Errorf("db error: %s", pgErr.Error())).Send()
 			log.Println("[UserRepository][RegisterUser] User already exists for this user: ", pgErr.Error())
-			return "", cerr.NewCustomErrorWithCodeAndOrigin("User already exists for this user", cerr.InvalidRequestErrorCode, err)
+			return "", duplicateUserError(pgErr, "User already exists for this user")
 		}
 		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
 		log.Println("[UserRepository][RegisterUser] Error in creating user: ", err)
@@ -61,16 +66,17 @@ func (u *userRepository) RegisterUser(ctx context.Context, userName string, pass
 
 func (u *userRepository) GetUserByUserName(ctx context.Context, userName string) (*models.User, error) {
 	var user models.User
+	tenantID := tenant.ID(ctx)
 
 	//for fetching the database query
 	statement := u.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
-		return tx.Where("user_name = ?", userName).First(&user)
+		return tx.Where("tenant_id = ? AND user_name = ?", tenantID, userName).First(&user)
 	})
 
 	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
 	defer instrument.GetSpan().End()
 
-	if err := u.database.Where("user_name = ?", userName).First(&user).Error; err != nil {
+	if err := u.database.Where("tenant_id = ? AND user_name = ?", tenantID, userName).First(&user).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
 			log.Println("[UserRepository][GetUserByUserName] User not found: ", err)
@@ -82,3 +88,388 @@ func (u *userRepository) GetUserByUserName(ctx context.Context, userName string)
 	}
 	return &user, nil
 }
+
+func (u *userRepository) GetUserByUserID(ctx context.Context, userID string) (*models.User, error) {
+	var user models.User
+	tenantID := tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := u.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("tenant_id = ? AND uuid = ?", tenantID, userID).First(&user)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := u.database.Where("tenant_id = ? AND uuid = ?", tenantID, userID).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+			log.Println("[UserRepository][GetUserByUserID] User not found: ", err)
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("User not found", cerr.InvalidRequestErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[UserRepository][GetUserByUserID] Error in fetching user: ", err)
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserByEmail returns the user of the tenant with the email address, which is expected to be normalised
+func (u *userRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	tenantID := tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := u.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("tenant_id = ? AND email = ?", tenantID, email).First(&user)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := u.database.Where("tenant_id = ? AND email = ?", tenantID, email).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+			log.Println("[UserRepository][GetUserByEmail] User not found: ", err)
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("User not found", cerr.InvalidRequestErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[UserRepository][GetUserByEmail] Error in fetching user: ", err)
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserByPhoneNumber returns the user of the tenant with the phone number, which is expected to be in E.164 format
+func (u *userRepository) GetUserByPhoneNumber(ctx context.Context, phoneNumber string) (*models.User, error) {
+	var user models.User
+	tenantID := tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := u.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("tenant_id = ? AND phone_number = ?", tenantID, phoneNumber).First(&user)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := u.database.Where("tenant_id = ? AND phone_number = ?", tenantID, phoneNumber).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+			log.Println("[UserRepository][GetUserByPhoneNumber] User not found: ", err)
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("User not found", cerr.InvalidRequestErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[UserRepository][GetUserByPhoneNumber] Error in fetching user: ", err)
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateUser saves the profile fields of the user, the username and the password are changed separately
+func (u *userRepository) UpdateUser(ctx context.Context, user *models.User) error {
+	//for fetching the database query
+	statement := u.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(user).Where("tenant_id = ?", user.TenantID).Select(profileColumns).Updates(user)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := u.database.Model(user).Where("tenant_id = ?", user.TenantID).Select(profileColumns).Updates(user).Error; err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == consts.UniqueViolation {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", pgErr.Error())).Send()
+			log.Println("[UserRepository][UpdateUser] Profile is already in use: ", pgErr.Error())
+			return duplicateUserError(pgErr, "User already exists for this user")
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[UserRepository][UpdateUser] Error in updating user: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (u *userRepository) ChangeUserName(ctx context.Context, user *models.User, userName string) error {
+	//for fetching the database query
+	statement := u.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(user).Where("tenant_id = ?", user.TenantID).Update("user_name", userName)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := u.database.Model(user).Where("tenant_id = ?", user.TenantID).Update("user_name", userName).Error; err != nil {
+		// Usernames of deleted users stay taken so that the account can be restored
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == consts.UniqueViolation {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", pgErr.Error())).Send()
+			log.Println("[UserRepository][ChangeUserName] Username is already taken: ", pgErr.Error())
+			return cerr.NewCustomErrorWithCodeAndOrigin("Username is already taken", cerr.InvalidRequestErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[UserRepository][ChangeUserName] Error in changing username: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// ChangePassword stores the new password hash of the user
+func (u *userRepository) ChangePassword(ctx context.Context, user *models.User, password string) error {
+	//for fetching the database query
+	statement := u.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(user).Where("tenant_id = ?", user.TenantID).Update("password", password)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := u.database.Model(user).Where("tenant_id = ?", user.TenantID).Update("password", password).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[UserRepository][ChangePassword] Error in changing password: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// VerifyEmail records that the user confirmed the email address and activates a user pending verification. It
+// fails when the user has another address by now, so a link sent to an old address cannot confirm a new one.
+func (u *userRepository) VerifyEmail(ctx context.Context, userID string, email string) error {
+	tenantID := tenant.ID(ctx)
+	updates := map[string]interface{}{
+		"email_verified_at": gorm.Expr("COALESCE(email_verified_at, ?::timestamptz)", time.Now()),
+		"status":            gorm.Expr("CASE WHEN status = ? THEN ? ELSE status END", models.UserStatusPendingVerification, models.UserStatusActive),
+	}
+
+	//for fetching the database query
+	statement := u.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&models.User{}).Where("tenant_id = ? AND uuid = ? AND email = ?", tenantID, userID, email).Updates(updates)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	result := u.database.Model(&models.User{}).Where("tenant_id = ? AND uuid = ? AND email = ?", tenantID, userID, email).Updates(updates)
+	if result.Error != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", result.Error.Error())).Send()
+		log.Println("[UserRepository][VerifyEmail] Error in verifying email: ", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Invalid or expired token", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	return nil
+}
+
+// MarkEmailVerificationSent records that a verification email is sent to the user now. It fails when one was
+// already sent after notBefore, the check and the update are one statement so concurrent requests send one email.
+func (u *userRepository) MarkEmailVerificationSent(ctx context.Context, user *models.User, notBefore time.Time) error {
+	now := time.Now()
+
+	//for fetching the database query
+	statement := u.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(user).Where("tenant_id = ? AND (email_verification_sent_at IS NULL OR email_verification_sent_at < ?)", user.TenantID, notBefore).Update("email_verification_sent_at", now)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	result := u.database.Model(user).Where("tenant_id = ? AND (email_verification_sent_at IS NULL OR email_verification_sent_at < ?)", user.TenantID, notBefore).Update("email_verification_sent_at", now)
+	if result.Error != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", result.Error.Error())).Send()
+		log.Println("[UserRepository][MarkEmailVerificationSent] Error in marking verification email sent: ", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return cerr.NewCustomErrorWithCodeAndOrigin("A verification email was sent recently, try again later", cerr.TooManyRequestsErrorCode, nil)
+	}
+
+	return nil
+}
+
+// ChangeUserStatus moves the user from the status it has to the given one and records the reason and the admin
+// held by the user. It fails when the status was changed by someone else since the user was read.
+func (u *userRepository) ChangeUserStatus(ctx context.Context, user *models.User, status string) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":            status,
+		"status_reason":     user.StatusReason,
+		"status_changed_by": user.StatusChangedBy,
+		"status_changed_at": now,
+	}
+
+	//for fetching the database query
+	statement := u.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(user).Where("tenant_id = ? AND status = ?", user.TenantID, user.Status).Updates(updates)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	result := u.database.Model(user).Where("tenant_id = ? AND status = ?", user.TenantID, user.Status).Updates(updates)
+	if result.Error != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", result.Error.Error())).Send()
+		log.Println("[UserRepository][ChangeUserStatus] Error in changing status: ", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return cerr.NewCustomErrorWithCodeAndOrigin("User status was changed meanwhile, try again", cerr.InvalidRequestErrorCode, nil)
+	}
+	user.Status = status
+	user.StatusChangedAt = &now
+
+	return nil
+}
+
+// DeleteUser soft deletes the user, the record is kept and can be restored
+func (u *userRepository) DeleteUser(ctx context.Context, user *models.User) error {
+	//for fetching the database query
+	statement := u.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("tenant_id = ?", user.TenantID).Delete(user)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := u.database.Where("tenant_id = ?", user.TenantID).Delete(user).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[UserRepository][DeleteUser] Error in deleting user: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// RestoreUser undoes the soft delete of the user
+func (u *userRepository) RestoreUser(ctx context.Context, userName string) (*models.User, error) {
+	var user models.User
+	tenantID := tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := u.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Unscoped().Where("tenant_id = ? AND user_name = ? AND deleted_at IS NOT NULL", tenantID, userName).First(&user)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	if err := u.database.Unscoped().Where("tenant_id = ? AND user_name = ? AND deleted_at IS NOT NULL", tenantID, userName).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+			log.Println("[UserRepository][RestoreUser] Deleted user not found: ", err)
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("Deleted user not found", cerr.NotFoundErrorCode, err)
+		}
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[UserRepository][RestoreUser] Error in fetching user: ", err)
+		return nil, err
+	}
+
+	if err := u.database.Unscoped().Model(&user).Update("deleted_at", nil).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[UserRepository][RestoreUser] Error in restoring user: ", err)
+		return nil, err
+	}
+	user.DeletedAt = gorm.DeletedAt{}
+
+	return &user, nil
+}
+
+// ListUsers returns a page of the users matching the filter and how many users match it in total
+func (u *userRepository) ListUsers(ctx context.Context, filter *models.UserFilter) ([]models.User, int64, error) {
+	var users []models.User
+	var total int64
+	tenantID := tenant.ID(ctx)
+
+	//for fetching the database query
+	statement := u.database.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return listUsersPage(filterUsers(tx, tenantID, filter), filter).Find(&users)
+	})
+
+	instrument := mtnapm.InitGormAPM(ctx, "postgresql", statement)
+	defer instrument.GetSpan().End()
+
+	// The total ignores the cursor, it counts every page
+	if err := filterUsers(u.database, tenantID, filter).Count(&total).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[UserRepository][ListUsers] Error in counting users: ", err)
+		return nil, 0, err
+	}
+
+	if err := listUsersPage(filterUsers(u.database, tenantID, filter), filter).Find(&users).Error; err != nil {
+		apm.CaptureError(ctx, fmt.Errorf("db error: %s", err.Error())).Send()
+		log.Println("[UserRepository][ListUsers] Error in fetching users: ", err)
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// filterUsers applies the filters of a listing
+func filterUsers(tx *gorm.DB, tenantID string, filter *models.UserFilter) *gorm.DB {
+	query := tx.Model(&models.User{}).Where("users.tenant_id = ?", tenantID)
+
+	switch filter.Status {
+	case models.UserStatusDeleted:
+		query = query.Unscoped().Where("users.deleted_at IS NOT NULL")
+	case models.UserStatusAll:
+		query = query.Unscoped()
+	case "":
+		// Every user that is not deleted
+	default:
+		query = query.Where("users.status = ?", filter.Status)
+	}
+
+	if filter.Role != "" {
+		query = query.Where("users.uuid IN (?)", tx.Session(&gorm.Session{NewDB: true}).Table("user_roles").
+			Select("user_roles.user_uuid").
+			Joins("JOIN roles ON roles.id = user_roles.role_id").
+			Where("roles.name = ? AND roles.tenant_id IN ?", filter.Role, []string{tenantID, ""}))
+	}
+	if filter.UserNamePrefix != "" {
+		query = query.Where(`users.user_name LIKE ? ESCAPE '\'`, likeEscaper.Replace(filter.UserNamePrefix)+"%")
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("users.created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("users.created_at < ?", *filter.CreatedBefore)
+	}
+
+	return query
+}
+
+// listUsersPage orders the listing and continues it behind the last user of the previous page, the id breaks
+// ties between users sharing the sort value
+func listUsersPage(query *gorm.DB, filter *models.UserFilter) *gorm.DB {
+	column := "users.created_at"
+	if filter.SortBy == models.UserSortUserName {
+		column = "users.user_name"
+	}
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		var value interface{} = filter.After.CreatedAt
+		if filter.SortBy == models.UserSortUserName {
+			value = filter.After.UserName
+		}
+		query = query.Where(fmt.Sprintf("(%s, users.id) %s (?, ?)", column, comparison), value, filter.After.ID)
+	}
+
+	return query.Order(fmt.Sprintf("%s %s, users.id %s", column, direction, direction)).Limit(filter.Limit)
+}
+
+// duplicateUserError tells which of the unique fields of a user is already taken, fallback is used for the username
+func duplicateUserError(pgErr *pgconn.PgError, fallback string) error {
+	switch pgErr.ConstraintName {
+	case "idx_user_tenant_email":
+		return cerr.NewCustomErrorWithCodeAndOrigin("Email is already in use", cerr.InvalidRequestErrorCode, pgErr)
+	case "idx_user_tenant_phone_number":
+		return cerr.NewCustomErrorWithCodeAndOrigin("Phone number is already in use", cerr.InvalidRequestErrorCode, pgErr)
+	}
+	return cerr.NewCustomErrorWithCodeAndOrigin(fallback, cerr.InvalidRequestErrorCode, pgErr)
+}
@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyPasswordHash is compared against when the user does not exist, so that an unknown username takes as
+// long to reject as a wrong password
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not the password of any user"), bcrypt.DefaultCost)
+
+// authenticatePassword looks the user up and compares the password, every flow that logs a user in with a
+// password goes through it. The username is expected to be normalised already. An unknown username and a
+// wrong password fail alike, and both count towards the lockout of the username and the client IP. The
+// caller resets the count with recordSuccess once the user passed every factor.
+func authenticatePassword(ctx context.Context, userRepository models.UserRepository, guard *loginGuard, userName string, password string, clientIP string) (*models.User, error) {
+	if err := guard.check(ctx, userName, clientIP); err != nil {
+		return nil, err
+	}
+
+	// Call the repository
+	user, err := userRepository.GetUserByUserName(ctx, userName)
+	if err != nil {
+		if cerr.GetErrorCode(err) != cerr.InvalidRequestErrorCode {
+			return nil, err
+		}
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return nil, guard.fail(ctx, userName, clientIP, errInvalidCredentials())
+	}
+
+	// Compare the password
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, guard.fail(ctx, userName, clientIP, errInvalidCredentials())
+	}
+
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// checkUserStatus rejects users who may not log in. It is only called once the user proved who they are, so
+// the distinct error reveals nothing to someone guessing at accounts.
+func checkUserStatus(user *models.User) error {
+	switch user.Status {
+	case models.UserStatusPendingVerification:
+		return cerr.NewCustomErrorWithCodeAndOrigin("Email address has not been verified", cerr.ForbiddenErrorCode, nil)
+	case models.UserStatusSuspended:
+		return cerr.NewCustomErrorWithCodeAndOrigin("User has been suspended", cerr.ForbiddenErrorCode, nil)
+	case models.UserStatusLocked:
+		return cerr.NewCustomErrorWithCodeAndOrigin("User has been locked", cerr.ForbiddenErrorCode, nil)
+	case models.UserStatusDeactivated:
+		return cerr.NewCustomErrorWithCodeAndOrigin("User has been deactivated", cerr.ForbiddenErrorCode, nil)
+	}
+	return nil
+}
+
+// errInvalidCredentials is returned for an unknown username and a wrong password alike
+func errInvalidCredentials() error {
+	return cerr.NewCustomErrorWithCodeAndOrigin("Invalid username or password", cerr.InvalidRequestErrorCode, nil)
+}
+
+// loginGuard throttles password logins. Every failed login of a username doubles the time before it may be
+// tried again, starting at LOGIN_BACKOFF_BASE_SECONDS, and LOGIN_LOCKOUT_THRESHOLD failures lock it for
+// LOGIN_LOCKOUT_DURATION minutes. A client IP is only locked, after LOGIN_IP_LOCKOUT_THRESHOLD failures, as
+// many users can share one. Failures are forgotten LOGIN_LOCKOUT_DURATION minutes after the last one.
+type loginGuard struct {
+	loginAttemptRepository models.LoginAttemptRepository
+}
+
+// check rejects the login while the username or the client IP has to wait
+func (g *loginGuard) check(ctx context.Context, userName string, clientIP string) error {
+	attempt, err := g.loginAttemptRepository.GetLoginAttempt(ctx, models.LoginAttemptKindUserName, userName)
+	if err != nil {
+		return err
+	}
+	retryAt := userNameRetryAt(attempt)
+
+	if clientIP != "" {
+		attempt, err := g.loginAttemptRepository.GetLoginAttempt(ctx, models.LoginAttemptKindClientIP, clientIP)
+		if err != nil {
+			return err
+		}
+		if ipRetryAt := clientIPRetryAt(attempt); ipRetryAt.After(retryAt) {
+			retryAt = ipRetryAt
+		}
+	}
+
+	if time.Now().Before(retryAt) {
+		return cerr.NewCustomErrorWithDetails("Too many failed login attempts, try again later", cerr.TooManyRequestsErrorCode, retryAt)
+	}
+
+	return nil
+}
+
+// fail counts the failed login and returns loginErr, the error the login fails with
+func (g *loginGuard) fail(ctx context.Context, userName string, clientIP string, loginErr error) error {
+	windowStart := time.Now().Add(-lockoutDuration())
+
+	attempt, err := g.loginAttemptRepository.RecordLoginFailure(ctx, models.LoginAttemptKindUserName, userName, windowStart)
+	if err != nil {
+		return err
+	}
+	if attempt.Failures == env.EnvConfig.LoginLockoutThreshold {
+		log.Println("[LoginGuard][fail] Username locked after failed logins: ", userName)
+	}
+
+	if clientIP != "" {
+		attempt, err := g.loginAttemptRepository.RecordLoginFailure(ctx, models.LoginAttemptKindClientIP, clientIP, windowStart)
+		if err != nil {
+			return err
+		}
+		if attempt.Failures == env.EnvConfig.LoginIPLockoutThreshold {
+			log.Println("[LoginGuard][fail] Client IP locked after failed logins: ", clientIP)
+		}
+	}
+
+	return loginErr
+}
+
+// recordSuccess forgets the failed logins of the username. Those of the client IP are kept, otherwise anyone
+// with an account could clear them between guesses at other accounts.
+func (g *loginGuard) recordSuccess(ctx context.Context, userName string) error {
+	return g.loginAttemptRepository.ResetLoginAttempts(ctx, models.LoginAttemptKindUserName, userName)
+}
+
+// unlock lifts the lockout of the username
+func (g *loginGuard) unlock(ctx context.Context, userName string) error {
+	return g.loginAttemptRepository.ResetLoginAttempts(ctx, models.LoginAttemptKindUserName, userName)
+}
+
+// userNameRetryAt is when the username may be tried again, a threshold or a base of 0 turns the lockout or
+// the back-off off
+func userNameRetryAt(attempt *models.LoginAttempt) time.Time {
+	if attempt == nil || attempt.LastFailureAt.Before(time.Now().Add(-lockoutDuration())) {
+		return time.Time{}
+	}
+
+	threshold := env.EnvConfig.LoginLockoutThreshold
+	if threshold > 0 && attempt.Failures >= threshold {
+		return attempt.LastFailureAt.Add(lockoutDuration())
+	}
+
+	base := time.Duration(env.EnvConfig.LoginBackoffBaseSeconds) * time.Second
+	if base <= 0 {
+		return time.Time{}
+	}
+	// The shift is bounded so the back-off cannot overflow, it never exceeds the lockout anyway
+	shift := attempt.Failures - 1
+	if shift > 20 {
+		shift = 20
+	}
+	backoff := base << shift
+	if backoff > lockoutDuration() {
+		backoff = lockoutDuration()
+	}
+	return attempt.LastFailureAt.Add(backoff)
+}
+
+// clientIPRetryAt is when logins from the client IP are accepted again
+func clientIPRetryAt(attempt *models.LoginAttempt) time.Time {
+	if attempt == nil || attempt.LastFailureAt.Before(time.Now().Add(-lockoutDuration())) {
+		return time.Time{}
+	}
+
+	threshold := env.EnvConfig.LoginIPLockoutThreshold
+	if threshold > 0 && attempt.Failures >= threshold {
+		return attempt.LastFailureAt.Add(lockoutDuration())
+	}
+	return time.Time{}
+}
+
+func lockoutDuration() time.Duration {
+	return time.Duration(env.EnvConfig.LoginLockoutDuration) * time.Minute
+}
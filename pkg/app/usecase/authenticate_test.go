@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
+)
+
+// setLoginGuardConfig sets the lockout settings for the test and restores them afterwards
+func setLoginGuardConfig(t *testing.T, threshold int, ipThreshold int, durationMinutes int, backoffBaseSeconds int) {
+	t.Helper()
+	previous := env.EnvConfig
+	t.Cleanup(func() { env.EnvConfig = previous })
+
+	env.EnvConfig.LoginLockoutThreshold = threshold
+	env.EnvConfig.LoginIPLockoutThreshold = ipThreshold
+	env.EnvConfig.LoginLockoutDuration = durationMinutes
+	env.EnvConfig.LoginBackoffBaseSeconds = backoffBaseSeconds
+}
+
+func TestUserNameRetryAt(t *testing.T) {
+	lastFailureAt := time.Now().Add(-time.Second)
+	lockout := 15 * time.Minute
+
+	tests := []struct {
+		name          string
+		threshold     int
+		backoffBase   int
+		attempt       *models.LoginAttempt
+		wantWait      time.Duration
+		wantNoBackoff bool
+	}{
+		{name: "no failures", threshold: 5, backoffBase: 1, attempt: nil, wantNoBackoff: true},
+		{name: "first failure", threshold: 5, backoffBase: 1, attempt: &models.LoginAttempt{Failures: 1, LastFailureAt: lastFailureAt}, wantWait: time.Second},
+		{name: "second failure doubles", threshold: 5, backoffBase: 1, attempt: &models.LoginAttempt{Failures: 2, LastFailureAt: lastFailureAt}, wantWait: 2 * time.Second},
+		{name: "last failure before lockout", threshold: 5, backoffBase: 1, attempt: &models.LoginAttempt{Failures: 4, LastFailureAt: lastFailureAt}, wantWait: 8 * time.Second},
+		{name: "threshold locks", threshold: 5, backoffBase: 1, attempt: &models.LoginAttempt{Failures: 5, LastFailureAt: lastFailureAt}, wantWait: lockout},
+		{name: "above threshold stays locked", threshold: 5, backoffBase: 1, attempt: &models.LoginAttempt{Failures: 9, LastFailureAt: lastFailureAt}, wantWait: lockout},
+		{name: "base scales the back-off", threshold: 5, backoffBase: 3, attempt: &models.LoginAttempt{Failures: 3, LastFailureAt: lastFailureAt}, wantWait: 12 * time.Second},
+		{name: "back-off capped at lockout", threshold: 0, backoffBase: 1, attempt: &models.LoginAttempt{Failures: 11, LastFailureAt: lastFailureAt}, wantWait: lockout},
+		{name: "back-off of many failures does not overflow", threshold: 0, backoffBase: 1, attempt: &models.LoginAttempt{Failures: 100, LastFailureAt: lastFailureAt}, wantWait: lockout},
+		{name: "lockout turned off", threshold: 0, backoffBase: 1, attempt: &models.LoginAttempt{Failures: 5, LastFailureAt: lastFailureAt}, wantWait: 16 * time.Second},
+		{name: "back-off turned off", threshold: 5, backoffBase: 0, attempt: &models.LoginAttempt{Failures: 4, LastFailureAt: lastFailureAt}, wantNoBackoff: true},
+		{name: "back-off turned off still locks", threshold: 5, backoffBase: 0, attempt: &models.LoginAttempt{Failures: 5, LastFailureAt: lastFailureAt}, wantWait: lockout},
+		{name: "failures forgotten after lockout duration", threshold: 5, backoffBase: 1, attempt: &models.LoginAttempt{Failures: 5, LastFailureAt: time.Now().Add(-lockout - time.Second)}, wantNoBackoff: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setLoginGuardConfig(t, tt.threshold, 50, 15, tt.backoffBase)
+
+			retryAt := userNameRetryAt(tt.attempt)
+			if tt.wantNoBackoff {
+				if !retryAt.IsZero() {
+					t.Errorf("userNameRetryAt = %v, want zero", retryAt)
+				}
+				return
+			}
+			if want := tt.attempt.LastFailureAt.Add(tt.wantWait); !retryAt.Equal(want) {
+				t.Errorf("userNameRetryAt = last failure + %v, want + %v", retryAt.Sub(tt.attempt.LastFailureAt), tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestClientIPRetryAt(t *testing.T) {
+	lastFailureAt := time.Now().Add(-time.Second)
+
+	tests := []struct {
+		name        string
+		ipThreshold int
+		failures    int
+		wantLocked  bool
+	}{
+		{name: "below threshold", ipThreshold: 50, failures: 49, wantLocked: false},
+		{name: "at threshold", ipThreshold: 50, failures: 50, wantLocked: true},
+		{name: "lockout turned off", ipThreshold: 0, failures: 500, wantLocked: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setLoginGuardConfig(t, 5, tt.ipThreshold, 15, 1)
+
+			retryAt := clientIPRetryAt(&models.LoginAttempt{Failures: tt.failures, LastFailureAt: lastFailureAt})
+			if locked := !retryAt.IsZero(); locked != tt.wantLocked {
+				t.Errorf("clientIPRetryAt locked = %v, want %v", locked, tt.wantLocked)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"log"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/utils"
+)
+
+// Sources the authorizer reads the permissions of a user from
+const (
+	PermissionSourceToken      = "token"
+	PermissionSourceRepository = "repository"
+)
+
+type authorizer struct {
+	roleRepository models.RoleRepository
+	source         string
+}
+
+// NewAuthorizer returns an authorizer reading user permissions from the token or, with the repository source,
+// looking them up on every check so that revoked roles take effect before the token expires
+func NewAuthorizer(roleRepository models.RoleRepository, source string) domain.Authorizer {
+	return &authorizer{
+		roleRepository: roleRepository,
+		source:         source,
+	}
+}
+
+func (a *authorizer) HasPermission(ctx context.Context, principal *domain.Principal, permission string) (bool, error) {
+	// Roles are granted within a tenant, the admins of a tenant must not gain the permissions acting on all of them
+	principalTenant := principal.TenantID
+	if principalTenant == "" {
+		principalTenant = tenant.Default()
+	}
+	if utils.Contains(models.PlatformPermissions, permission) && principalTenant != tenant.Default() {
+		return false, nil
+	}
+
+	// Tokens issued to a client itself carry what the client was allowed as scopes
+	if principal.UserID == "" {
+		return principal.HasScope(permission), nil
+	}
+
+	// Tokens issued to an OAuth client for a user and personal access tokens only carry the permissions of the
+	// user they were limited to by their scope
+	if principal.IsDelegated() && !principal.HasScope(permission) {
+		return false, nil
+	}
+
+	// Tokens issued before permissions were embedded fall back to the repository
+	if a.source == PermissionSourceToken && principal.Permissions != nil {
+		return principal.HasPermission(permission), nil
+	}
+
+	roles, err := a.roleRepository.GetUserRoles(ctx, principal.UserID)
+	if err != nil {
+		log.Println("[Authorizer][HasPermission] Error in GetUserRoles: ", err)
+		return false, err
+	}
+	for _, role := range roles {
+		for _, p := range role.Permissions {
+			if p.Name == permission {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
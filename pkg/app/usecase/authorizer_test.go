@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+)
+
+// fakeRoleRepository grants the permissions to every user and counts the lookups
+type fakeRoleRepository struct {
+	models.RoleRepository
+	permissions []string
+	err         error
+	lookups     int
+}
+
+func (r *fakeRoleRepository) GetUserRoles(ctx context.Context, userID string) ([]models.Role, error) {
+	r.lookups++
+	if r.err != nil {
+		return nil, r.err
+	}
+	role := models.Role{Name: "member"}
+	for _, name := range r.permissions {
+		role.Permissions = append(role.Permissions, models.Permission{Name: name})
+	}
+	return []models.Role{role}, nil
+}
+
+func TestAuthorizerHasPermission(t *testing.T) {
+	tests := []struct {
+		name                string
+		source              string
+		principal           domain.Principal
+		repository          []string
+		repositoryErr       error
+		permission          string
+		want                bool
+		wantErr             bool
+		wantRepositoryCalls int
+	}{
+		// Tokens issued to a client itself
+		{name: "client with the scope", source: PermissionSourceToken, principal: domain.Principal{ClientID: "client-1", Scopes: []string{models.PermissionUsersRead}}, permission: models.PermissionUsersRead, want: true},
+		{name: "client without the scope", source: PermissionSourceToken, principal: domain.Principal{ClientID: "client-1", Scopes: []string{models.PermissionUsersRead}}, permission: models.PermissionUsersWrite, want: false},
+		{name: "client ignores token permissions", source: PermissionSourceToken, principal: domain.Principal{ClientID: "client-1", Permissions: []string{models.PermissionUsersWrite}}, permission: models.PermissionUsersWrite, want: false},
+		{name: "client of the default tenant with a platform scope", source: PermissionSourceToken, principal: domain.Principal{ClientID: "client-1", Scopes: []string{models.PermissionKeysWrite}}, permission: models.PermissionKeysWrite, want: true},
+		{name: "client of another tenant with a platform scope", source: PermissionSourceToken, principal: domain.Principal{ClientID: "client-1", TenantID: "tenant-b", Scopes: []string{models.PermissionKeysWrite}}, permission: models.PermissionKeysWrite, want: false},
+
+		// Tokens an OAuth client obtained for a user
+		{name: "oauth token with the scope and the permission", source: PermissionSourceToken, principal: domain.Principal{UserID: "user-1", ClientID: "client-1", Scopes: []string{models.PermissionUsersRead}, Permissions: []string{models.PermissionUsersRead}}, permission: models.PermissionUsersRead, want: true},
+		{name: "oauth token with the permission but not the scope", source: PermissionSourceToken, principal: domain.Principal{UserID: "user-1", ClientID: "client-1", Scopes: []string{"openid"}, Permissions: []string{models.PermissionUsersRead}}, permission: models.PermissionUsersRead, want: false},
+		{name: "oauth token with the scope but not the permission", source: PermissionSourceToken, principal: domain.Principal{UserID: "user-1", ClientID: "client-1", Scopes: []string{models.PermissionUsersRead}, Permissions: []string{}}, permission: models.PermissionUsersRead, want: false},
+
+		// Personal access tokens, their permissions are always looked up
+		{name: "personal access token with the scope and the role", source: PermissionSourceToken, principal: domain.Principal{UserID: "user-1", PersonalAccessTokenID: "pat-1", Scopes: []string{models.PermissionUsersRead}}, repository: []string{models.PermissionUsersRead}, permission: models.PermissionUsersRead, want: true, wantRepositoryCalls: 1},
+		{name: "personal access token without the scope", source: PermissionSourceToken, principal: domain.Principal{UserID: "user-1", PersonalAccessTokenID: "pat-1", Scopes: []string{models.PermissionUsersRead}}, repository: []string{models.PermissionUsersWrite}, permission: models.PermissionUsersWrite, want: false},
+		{name: "personal access token whose role was revoked", source: PermissionSourceToken, principal: domain.Principal{UserID: "user-1", PersonalAccessTokenID: "pat-1", Scopes: []string{models.PermissionUsersRead}}, repository: nil, permission: models.PermissionUsersRead, want: false, wantRepositoryCalls: 1},
+
+		// Token source against repository source
+		{name: "token source reads the token", source: PermissionSourceToken, principal: domain.Principal{UserID: "user-1", Permissions: []string{models.PermissionRolesRead}}, repository: nil, permission: models.PermissionRolesRead, want: true},
+		{name: "token source without the permission", source: PermissionSourceToken, principal: domain.Principal{UserID: "user-1", Permissions: []string{models.PermissionRolesRead}}, repository: []string{models.PermissionRolesWrite}, permission: models.PermissionRolesWrite, want: false},
+		{name: "token source falls back for tokens without permissions", source: PermissionSourceToken, principal: domain.Principal{UserID: "user-1"}, repository: []string{models.PermissionRolesRead}, permission: models.PermissionRolesRead, want: true, wantRepositoryCalls: 1},
+		{name: "repository source ignores the token", source: PermissionSourceRepository, principal: domain.Principal{UserID: "user-1", Permissions: []string{models.PermissionRolesWrite}}, repository: nil, permission: models.PermissionRolesWrite, want: false, wantRepositoryCalls: 1},
+		{name: "repository source reads the roles", source: PermissionSourceRepository, principal: domain.Principal{UserID: "user-1", Permissions: []string{}}, repository: []string{models.PermissionRolesWrite}, permission: models.PermissionRolesWrite, want: true, wantRepositoryCalls: 1},
+		{name: "repository error", source: PermissionSourceRepository, principal: domain.Principal{UserID: "user-1"}, repositoryErr: errors.New("connection refused"), permission: models.PermissionRolesRead, wantErr: true, wantRepositoryCalls: 1},
+
+		// Platform permissions only take effect in the default tenant
+		{name: "admin of the default tenant", source: PermissionSourceToken, principal: domain.Principal{UserID: "user-1", TenantID: "default", Permissions: []string{models.PermissionOrganizationsWrite}}, permission: models.PermissionOrganizationsWrite, want: true},
+		{name: "admin of a token without a tenant", source: PermissionSourceToken, principal: domain.Principal{UserID: "user-1", Permissions: []string{models.PermissionOrganizationsWrite}}, permission: models.PermissionOrganizationsWrite, want: true},
+		{name: "admin of another tenant", source: PermissionSourceRepository, principal: domain.Principal{UserID: "user-1", TenantID: "tenant-b"}, repository: []string{models.PermissionOrganizationsWrite}, permission: models.PermissionOrganizationsWrite, want: false},
+		{name: "admin of another tenant keeps tenant permissions", source: PermissionSourceRepository, principal: domain.Principal{UserID: "user-1", TenantID: "tenant-b"}, repository: []string{models.PermissionUsersWrite}, permission: models.PermissionUsersWrite, want: true, wantRepositoryCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roleRepository := &fakeRoleRepository{permissions: tt.repository, err: tt.repositoryErr}
+			principal := tt.principal
+
+			got, err := NewAuthorizer(roleRepository, tt.source).HasPermission(context.Background(), &principal, tt.permission)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HasPermission error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("HasPermission(%s) = %v, want %v", tt.permission, got, tt.want)
+			}
+			if roleRepository.lookups != tt.wantRepositoryCalls {
+				t.Errorf("HasPermission looked the roles up %d times, want %d", roleRepository.lookups, tt.wantRepositoryCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/utils"
+)
+
+type claimsBuilder struct {
+	namespace       string
+	roleRepository  models.RoleRepository
+	groupRepository models.GroupRepository
+	includeGroups   bool
+}
+
+// NewClaimsBuilder returns the default claims builder, non standard claims are prefixed with namespace. The
+// effective groups of the user are only embedded when includeGroups is set, as they can make tokens large.
+func NewClaimsBuilder(namespace string, roleRepository models.RoleRepository, groupRepository models.GroupRepository, includeGroups bool) domain.ClaimsBuilder {
+	return &claimsBuilder{
+		namespace:       namespace,
+		roleRepository:  roleRepository,
+		groupRepository: groupRepository,
+		includeGroups:   includeGroups,
+	}
+}
+
+func (b *claimsBuilder) Build(ctx context.Context, user *models.User, scopes []string) (map[string]interface{}, error) {
+	claims := map[string]interface{}{
+		"preferred_username":       user.UserName,
+		"tenant_id":                user.TenantID,
+		"updated_at":               user.UpdatedAt.Unix(),
+		b.namespace + "created_at": user.CreatedAt.Unix(),
+	}
+
+	// The standard OpenID Connect profile claims are only set for the fields the user filled in
+	profile := map[string]string{
+		"email":        utils.StringValue(user.Email),
+		"phone_number": utils.StringValue(user.PhoneNumber),
+		"given_name":   user.FirstName,
+		"family_name":  user.LastName,
+		"name":         fullName(user),
+		"locale":       user.Locale,
+		"zoneinfo":     user.Timezone,
+		"picture":      user.AvatarURL,
+	}
+	for claim, value := range profile {
+		if value != "" {
+			claims[claim] = value
+		}
+	}
+	if user.Email != nil {
+		claims["email_verified"] = user.EmailVerifiedAt != nil
+	}
+
+	if len(scopes) > 0 {
+		claims["scope"] = strings.Join(scopes, " ")
+	}
+
+	// The roles and the permissions they grant are embedded so resource servers need no lookup
+	roles, err := b.roleRepository.GetUserRoles(ctx, user.UUID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	roleNames := make([]string, 0, len(roles))
+	granted := map[string]bool{}
+	for _, role := range roles {
+		roleNames = append(roleNames, role.Name)
+		for _, permission := range role.Permissions {
+			granted[permission.Name] = true
+		}
+	}
+	permissions := make([]string, 0, len(granted))
+	for permission := range granted {
+		permissions = append(permissions, permission)
+	}
+	sort.Strings(roleNames)
+	sort.Strings(permissions)
+
+	claims[b.namespace+"roles"] = roleNames
+	claims[b.namespace+"permissions"] = permissions
+
+	if b.includeGroups {
+		groups, err := b.groupRepository.GetEffectiveGroups(ctx, user.UUID.String())
+		if err != nil {
+			return nil, err
+		}
+		groupNames := make([]string, 0, len(groups))
+		for _, group := range groups {
+			groupNames = append(groupNames, group.Name)
+		}
+		claims[b.namespace+"groups"] = groupNames
+	}
+
+	return claims, nil
+}
+
+// fullName is the display name of the user, or the first and last name when none was set
+func fullName(user *models.User) string {
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/notifier"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/utils"
+)
+
+// emailVerificationClaims are signed into the verification link, so nothing has to be stored to check it.
+// The address is included so a link stops working once the user changed it.
+type emailVerificationClaims struct {
+	UserID    string `json:"sub"`
+	TenantID  string `json:"tid"`
+	Email     string `json:"email"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// emailVerifier sends and checks the links users confirm their email address with
+type emailVerifier struct {
+	userRepository models.UserRepository
+	notifier       notifier.Notifier
+	key            []byte
+}
+
+// send emails a verification link to the user unless the address is confirmed already. It fails with a too many
+// requests error when a link was sent less than EMAIL_VERIFICATION_RESEND_INTERVAL_SECONDS ago.
+func (v *emailVerifier) send(ctx context.Context, user *models.User) error {
+	email := utils.StringValue(user.Email)
+	if email == "" || user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	notBefore := time.Now().Add(-time.Duration(env.EnvConfig.EmailResendIntervalSeconds) * time.Second)
+	if err := v.userRepository.MarkEmailVerificationSent(ctx, user, notBefore); err != nil {
+		return err
+	}
+
+	token, err := v.sign(&emailVerificationClaims{
+		UserID:    user.UUID.String(),
+		TenantID:  user.TenantID,
+		Email:     email,
+		ExpiresAt: time.Now().Add(time.Duration(env.EnvConfig.EmailVerificationExpirationTime) * time.Minute).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	message := &notifier.Message{
+		Channel: notifier.ChannelEmail,
+		To:      email,
+		Subject: "Verify your email address",
+		Body:    fmt.Sprintf("Open %s to verify your email address, the link expires in %d minutes.", emailVerificationLink(user.TenantID, token), env.EnvConfig.EmailVerificationExpirationTime),
+	}
+	return v.notifier.Notify(ctx, message)
+}
+
+// sign returns the claims followed by their HMAC-SHA256, both base64url encoded and joined by a dot
+func (v *emailVerifier) sign(claims *emailVerificationClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(v.mac(encoded)), nil
+}
+
+// parse checks the signature and the expiry of a token made by sign and returns its claims
+func (v *emailVerifier) parse(token string) (*emailVerificationClaims, error) {
+	invalid := cerr.NewCustomErrorWithCodeAndOrigin("Invalid or expired token", cerr.InvalidRequestErrorCode, nil)
+
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return nil, invalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, v.mac(encoded)) {
+		return nil, invalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, invalid
+	}
+	var claims emailVerificationClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, invalid
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, invalid
+	}
+
+	return &claims, nil
+}
+
+func (v *emailVerifier) mac(encoded string) []byte {
+	mac := hmac.New(sha256.New, v.key)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// emailVerificationLink is the link to the form of EMAIL_VERIFICATION_URL with the token in its query, or to
+// the verification endpoint of this service when no form is configured
+func emailVerificationLink(tenantID string, token string) string {
+	link := env.EnvConfig.EmailVerificationURL
+	if link == "" {
+		link = strings.TrimSuffix(env.EnvConfig.JWTIssuer, "/")
+		// The endpoint is opened from a mail client, so the tenant has to be part of the path
+		if tenantID != tenant.Default() {
+			link += "/t/" + tenantID
+		}
+		link += "/user/email/verify"
+	}
+
+	verifyURL, err := url.Parse(link)
+	if err != nil {
+		return token
+	}
+	query := verifyURL.Query()
+	query.Set("token", token)
+	verifyURL.RawQuery = query.Encode()
+	return verifyURL.String()
+}
+
+type emailVerificationUsecase struct {
+	userRepository models.UserRepository
+	verifier       *emailVerifier
+}
+
+func NewEmailVerificationUsecase(userRepository models.UserRepository, notifier notifier.Notifier, emailVerificationKey []byte) domain.EmailVerificationUsecase {
+	return &emailVerificationUsecase{
+		userRepository: userRepository,
+		verifier: &emailVerifier{
+			userRepository: userRepository,
+			notifier:       notifier,
+			key:            emailVerificationKey,
+		},
+	}
+}
+
+// VerifyEmail confirms the email address of the verification link and activates the user if they were pending
+func (e *emailVerificationUsecase) VerifyEmail(ctx context.Context, verifyEmailRequest *domain.VerifyEmailRequest) error {
+	claims, err := e.verifier.parse(verifyEmailRequest.Token)
+	if err != nil {
+		return err
+	}
+	if claims.TenantID != tenant.ID(ctx) {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Invalid or expired token", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	// Call the repository
+	if err := e.userRepository.VerifyEmail(ctx, claims.UserID, claims.Email); err != nil {
+		log.Println("[EmailVerificationUsecase][VerifyEmail] Error in VerifyEmail: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// ResendVerificationEmail sends a new verification link. Unknown and confirmed addresses are not reported and
+// neither is a request within EMAIL_VERIFICATION_RESEND_INTERVAL_SECONDS of the last link, so the endpoint can
+// neither be used to find out who has an account nor to flood anyone with emails.
+func (e *emailVerificationUsecase) ResendVerificationEmail(ctx context.Context, resendVerificationEmailRequest *domain.ResendVerificationEmailRequest) error {
+	email, err := normalizeEmail(resendVerificationEmailRequest.Email)
+	if err != nil {
+		return err
+	}
+	if email == nil {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Invalid email", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	// Call the repository
+	user, err := e.userRepository.GetUserByEmail(ctx, *email)
+	if err != nil {
+		if cerr.GetErrorCode(err) == cerr.InvalidRequestErrorCode {
+			return nil
+		}
+		log.Println("[EmailVerificationUsecase][ResendVerificationEmail] Error in GetUserByEmail: ", err)
+		return err
+	}
+
+	if err := e.verifier.send(ctx, user); err != nil {
+		if cerr.GetErrorCode(err) == cerr.TooManyRequestsErrorCode {
+			log.Println("[EmailVerificationUsecase][ResendVerificationEmail] Verification email requested again too soon: ", user.UUID)
+			return nil
+		}
+		log.Println("[EmailVerificationUsecase][ResendVerificationEmail] Error in send: ", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/notifier"
+)
+
+// fakeUserRepository serves a single user, the methods a test does not need panic through the nil interface
+type fakeUserRepository struct {
+	models.UserRepository
+	user *models.User
+}
+
+func (r *fakeUserRepository) GetUserByUserID(ctx context.Context, userID string) (*models.User, error) {
+	if r.user == nil || r.user.UUID.String() != userID {
+		return nil, errUserNotFound()
+	}
+	return r.user, nil
+}
+
+func (r *fakeUserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	if r.user == nil || r.user.Email == nil || *r.user.Email != email {
+		return nil, errUserNotFound()
+	}
+	return r.user, nil
+}
+
+func (r *fakeUserRepository) GetUserByUserName(ctx context.Context, userName string) (*models.User, error) {
+	if r.user == nil || r.user.UserName != userName {
+		return nil, errUserNotFound()
+	}
+	return r.user, nil
+}
+
+// fakeLoginAttemptRepository counts failures in memory
+type fakeLoginAttemptRepository struct {
+	attempts map[string]*models.LoginAttempt
+}
+
+func (r *fakeLoginAttemptRepository) GetLoginAttempt(ctx context.Context, kind string, identifier string) (*models.LoginAttempt, error) {
+	return r.attempts[kind+":"+identifier], nil
+}
+
+func (r *fakeLoginAttemptRepository) RecordLoginFailure(ctx context.Context, kind string, identifier string, windowStart time.Time) (*models.LoginAttempt, error) {
+	if r.attempts == nil {
+		r.attempts = map[string]*models.LoginAttempt{}
+	}
+	attempt := r.attempts[kind+":"+identifier]
+	if attempt == nil || attempt.LastFailureAt.Before(windowStart) {
+		attempt = &models.LoginAttempt{Kind: kind, Identifier: identifier}
+		r.attempts[kind+":"+identifier] = attempt
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = time.Now()
+	return attempt, nil
+}
+
+func (r *fakeLoginAttemptRepository) ResetLoginAttempts(ctx context.Context, kind string, identifier string) error {
+	delete(r.attempts, kind+":"+identifier)
+	return nil
+}
+
+// fakeOneTimeTokenRepository keeps the issued tokens in memory
+type fakeOneTimeTokenRepository struct {
+	models.OneTimeTokenRepository
+	tokens []*models.OneTimeToken
+}
+
+func (r *fakeOneTimeTokenRepository) CreateOneTimeToken(ctx context.Context, token *models.OneTimeToken) error {
+	token.CreatedAt = time.Now()
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+func (r *fakeOneTimeTokenRepository) GetLatestOneTimeToken(ctx context.Context, purpose string, userID string) (*models.OneTimeToken, error) {
+	for i := len(r.tokens) - 1; i >= 0; i-- {
+		if r.tokens[i].Purpose == purpose && r.tokens[i].UserUUID.String() == userID {
+			return r.tokens[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// fakeNotifier records the messages instead of sending them
+type fakeNotifier struct {
+	messages []*notifier.Message
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, message *notifier.Message) error {
+	n.messages = append(n.messages, message)
+	return nil
+}
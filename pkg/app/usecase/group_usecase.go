@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	"context"
+	"html"
+	"log"
+	"strings"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+)
+
+type groupUsecase struct {
+	groupRepository models.GroupRepository
+	userRepository  models.UserRepository
+}
+
+func NewGroupUsecase(groupRepository models.GroupRepository, userRepository models.UserRepository) domain.GroupUsecase {
+	return &groupUsecase{
+		groupRepository: groupRepository,
+		userRepository:  userRepository,
+	}
+}
+
+func (g *groupUsecase) CreateGroup(ctx context.Context, createGroupRequest *domain.CreateGroupRequest) (*domain.GroupResponse, error) {
+	name := strings.ToLower(strings.TrimSpace(createGroupRequest.Name))
+	if !namePattern.MatchString(name) {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid group name", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	group := &models.Group{
+		Name:        name,
+		Description: strings.TrimSpace(createGroupRequest.Description),
+	}
+
+	// Call the repository
+	if err := g.groupRepository.CreateGroup(ctx, group); err != nil {
+		log.Println("[GroupUsecase][CreateGroup] Error in CreateGroup: ", err)
+		return nil, err
+	}
+
+	return toGroupResponse(group), nil
+}
+
+func (g *groupUsecase) GetGroups(ctx context.Context) (*domain.GetGroupsResponse, error) {
+	// Call the repository
+	groups, err := g.groupRepository.GetGroups(ctx)
+	if err != nil {
+		log.Println("[GroupUsecase][GetGroups] Error in GetGroups: ", err)
+		return nil, err
+	}
+
+	return toGetGroupsResponse(groups), nil
+}
+
+func (g *groupUsecase) GetGroup(ctx context.Context, groupRequest *domain.GroupRequest) (*domain.GetGroupResponse, error) {
+	group, err := g.groupRepository.GetGroupByName(ctx, groupRequest.GroupName)
+	if err != nil {
+		log.Println("[GroupUsecase][GetGroup] Error in GetGroupByName: ", err)
+		return nil, err
+	}
+
+	members, err := g.groupRepository.GetMembers(ctx, group)
+	if err != nil {
+		log.Println("[GroupUsecase][GetGroup] Error in GetMembers: ", err)
+		return nil, err
+	}
+
+	subgroups, err := g.groupRepository.GetSubgroups(ctx, group)
+	if err != nil {
+		log.Println("[GroupUsecase][GetGroup] Error in GetSubgroups: ", err)
+		return nil, err
+	}
+
+	response := &domain.GetGroupResponse{
+		GroupResponse: *toGroupResponse(group),
+		Members:       make([]domain.GroupMemberResponse, 0, len(members)),
+		Subgroups:     toGetGroupsResponse(subgroups).Groups,
+	}
+	for _, member := range members {
+		response.Members = append(response.Members, domain.GroupMemberResponse{
+			ID:       member.UUID.String(),
+			UserName: member.UserName,
+		})
+	}
+
+	return response, nil
+}
+
+func (g *groupUsecase) UpdateGroup(ctx context.Context, updateGroupRequest *domain.UpdateGroupRequest) (*domain.GroupResponse, error) {
+	group, err := g.groupRepository.GetGroupByName(ctx, updateGroupRequest.GroupName)
+	if err != nil {
+		log.Println("[GroupUsecase][UpdateGroup] Error in GetGroupByName: ", err)
+		return nil, err
+	}
+
+	// Call the repository
+	group.Description = strings.TrimSpace(updateGroupRequest.Description)
+	if err := g.groupRepository.UpdateGroup(ctx, group); err != nil {
+		log.Println("[GroupUsecase][UpdateGroup] Error in UpdateGroup: ", err)
+		return nil, err
+	}
+
+	return toGroupResponse(group), nil
+}
+
+func (g *groupUsecase) DeleteGroup(ctx context.Context, groupRequest *domain.GroupRequest) error {
+	group, err := g.groupRepository.GetGroupByName(ctx, groupRequest.GroupName)
+	if err != nil {
+		log.Println("[GroupUsecase][DeleteGroup] Error in GetGroupByName: ", err)
+		return err
+	}
+
+	// Call the repository
+	if err := g.groupRepository.DeleteGroup(ctx, group); err != nil {
+		log.Println("[GroupUsecase][DeleteGroup] Error in DeleteGroup: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (g *groupUsecase) AddMember(ctx context.Context, groupMemberRequest *domain.GroupMemberRequest) error {
+	group, user, err := g.groupMember(ctx, groupMemberRequest)
+	if err != nil {
+		log.Println("[GroupUsecase][AddMember] Error in groupMember: ", err)
+		return err
+	}
+
+	// Call the repository
+	if err := g.groupRepository.AddMember(ctx, group, user.UUID); err != nil {
+		log.Println("[GroupUsecase][AddMember] Error in AddMember: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (g *groupUsecase) RemoveMember(ctx context.Context, groupMemberRequest *domain.GroupMemberRequest) error {
+	group, user, err := g.groupMember(ctx, groupMemberRequest)
+	if err != nil {
+		log.Println("[GroupUsecase][RemoveMember] Error in groupMember: ", err)
+		return err
+	}
+
+	// Call the repository
+	if err := g.groupRepository.RemoveMember(ctx, group, user.UUID); err != nil {
+		log.Println("[GroupUsecase][RemoveMember] Error in RemoveMember: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (g *groupUsecase) AddSubgroup(ctx context.Context, subgroupRequest *domain.SubgroupRequest) error {
+	parent, child, err := g.subgroup(ctx, subgroupRequest)
+	if err != nil {
+		log.Println("[GroupUsecase][AddSubgroup] Error in subgroup: ", err)
+		return err
+	}
+
+	// Call the repository
+	if err := g.groupRepository.AddSubgroup(ctx, parent, child); err != nil {
+		log.Println("[GroupUsecase][AddSubgroup] Error in AddSubgroup: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (g *groupUsecase) RemoveSubgroup(ctx context.Context, subgroupRequest *domain.SubgroupRequest) error {
+	parent, child, err := g.subgroup(ctx, subgroupRequest)
+	if err != nil {
+		log.Println("[GroupUsecase][RemoveSubgroup] Error in subgroup: ", err)
+		return err
+	}
+
+	// Call the repository
+	if err := g.groupRepository.RemoveSubgroup(ctx, parent, child); err != nil {
+		log.Println("[GroupUsecase][RemoveSubgroup] Error in RemoveSubgroup: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (g *groupUsecase) GetEffectiveGroups(ctx context.Context, effectiveGroupsRequest *domain.EffectiveGroupsRequest) (*domain.GetGroupsResponse, error) {
+	userID := effectiveGroupsRequest.UserID
+	if userID == "" {
+		// Remove the space from the username
+		userName := html.EscapeString(strings.TrimSpace(effectiveGroupsRequest.UserName))
+		user, err := g.userRepository.GetUserByUserName(ctx, userName)
+		if err != nil {
+			log.Println("[GroupUsecase][GetEffectiveGroups] Error in GetUserByUserName: ", err)
+			return nil, err
+		}
+		userID = user.UUID.String()
+	}
+
+	// Call the repository
+	groups, err := g.groupRepository.GetEffectiveGroups(ctx, userID)
+	if err != nil {
+		log.Println("[GroupUsecase][GetEffectiveGroups] Error in GetEffectiveGroups: ", err)
+		return nil, err
+	}
+
+	return toGetGroupsResponse(groups), nil
+}
+
+// groupMember looks up the group and the user of a membership request
+func (g *groupUsecase) groupMember(ctx context.Context, groupMemberRequest *domain.GroupMemberRequest) (*models.Group, *models.User, error) {
+	group, err := g.groupRepository.GetGroupByName(ctx, groupMemberRequest.GroupName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Remove the space from the username
+	userName := html.EscapeString(strings.TrimSpace(groupMemberRequest.UserName))
+	user, err := g.userRepository.GetUserByUserName(ctx, userName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return group, user, nil
+}
+
+// subgroup looks up the parent and the child group of a nesting request
+func (g *groupUsecase) subgroup(ctx context.Context, subgroupRequest *domain.SubgroupRequest) (*models.Group, *models.Group, error) {
+	parent, err := g.groupRepository.GetGroupByName(ctx, subgroupRequest.GroupName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	child, err := g.groupRepository.GetGroupByName(ctx, subgroupRequest.SubgroupName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return parent, child, nil
+}
+
+func toGroupResponse(group *models.Group) *domain.GroupResponse {
+	return &domain.GroupResponse{
+		ID:          group.ID,
+		Name:        group.Name,
+		Description: group.Description,
+		CreatedAt:   group.CreatedAt.String(),
+		UpdatedAt:   group.UpdatedAt.String(),
+	}
+}
+
+func toGetGroupsResponse(groups []models.Group) *domain.GetGroupsResponse {
+	response := &domain.GetGroupsResponse{Groups: make([]domain.GroupResponse, 0, len(groups))}
+	for i := range groups {
+		response.Groups = append(response.Groups, *toGroupResponse(&groups[i]))
+	}
+	return response
+}
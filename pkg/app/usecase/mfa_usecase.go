@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/totp"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/utils"
+)
+
+// recoveryCodeCount is the number of recovery codes handed out when MFA is enabled
+const recoveryCodeCount = 10
+
+// mfaAuthMethods are recorded for a login that passed the password and a second factor
+var mfaAuthMethods = []string{models.AuthMethodPassword, models.AuthMethodOTP, models.AuthMethodMFA}
+
+type mfaUsecase struct {
+	userRepository models.UserRepository
+	mfaRepository  models.MFARepository
+	loginGuard     *loginGuard
+}
+
+func NewMFAUsecase(userRepository models.UserRepository, mfaRepository models.MFARepository, loginAttemptRepository models.LoginAttemptRepository) domain.MFAUsecase {
+	return &mfaUsecase{
+		userRepository: userRepository,
+		mfaRepository:  mfaRepository,
+		loginGuard:     &loginGuard{loginAttemptRepository: loginAttemptRepository},
+	}
+}
+
+// EnrollTOTP creates a new secret for the user, it replaces a secret that was never confirmed
+func (m *mfaUsecase) EnrollTOTP(ctx context.Context, enrollTOTPRequest *domain.EnrollTOTPRequest) (*domain.EnrollTOTPResponse, error) {
+	user, err := m.userRepository.GetUserByUserID(ctx, enrollTOTPRequest.UserID)
+	if err != nil {
+		log.Println("[MFAUsecase][EnrollTOTP] Error in GetUserByUserID: ", err)
+		return nil, err
+	}
+
+	factor, err := m.mfaRepository.GetMFAFactor(ctx, enrollTOTPRequest.UserID)
+	if err != nil {
+		log.Println("[MFAUsecase][EnrollTOTP] Error in GetMFAFactor: ", err)
+		return nil, err
+	}
+	if factor != nil && factor.ConfirmedAt != nil {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("MFA is already enabled", cerr.DuplicateEntryErrorCode, nil)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		log.Println("[MFAUsecase][EnrollTOTP] Error in GenerateSecret: ", err)
+		return nil, err
+	}
+
+	// Call the repository
+	if err := m.mfaRepository.SaveMFAFactor(ctx, &models.MFAFactor{UserUUID: user.UUID, Secret: secret}); err != nil {
+		log.Println("[MFAUsecase][EnrollTOTP] Error in SaveMFAFactor: ", err)
+		return nil, err
+	}
+
+	// The account is shown next to the codes in the authenticator app
+	accountName := utils.StringValue(user.Email)
+	if accountName == "" {
+		accountName = user.UserName
+	}
+
+	return &domain.EnrollTOTPResponse{
+		Secret: secret,
+		URI:    totp.URI(env.EnvConfig.MFAIssuer, accountName, secret),
+	}, nil
+}
+
+// ConfirmTOTP turns MFA on once the user entered a valid code and hands out the recovery codes
+func (m *mfaUsecase) ConfirmTOTP(ctx context.Context, confirmTOTPRequest *domain.ConfirmTOTPRequest) (*domain.ConfirmTOTPResponse, error) {
+	factor, err := m.mfaRepository.GetMFAFactor(ctx, confirmTOTPRequest.UserID)
+	if err != nil {
+		log.Println("[MFAUsecase][ConfirmTOTP] Error in GetMFAFactor: ", err)
+		return nil, err
+	}
+	if factor == nil {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("MFA enrolment has not been started", cerr.InvalidRequestErrorCode, nil)
+	}
+	if factor.ConfirmedAt != nil {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("MFA is already enabled", cerr.DuplicateEntryErrorCode, nil)
+	}
+
+	step, ok := totp.Validate(factor.Secret, confirmTOTPRequest.Code, time.Now())
+	if !ok {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid code", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	recoveryCodes := make([]string, 0, recoveryCodeCount)
+	recoveryCodeHashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			log.Println("[MFAUsecase][ConfirmTOTP] Error in newRecoveryCode: ", err)
+			return nil, err
+		}
+		recoveryCodes = append(recoveryCodes, code)
+		recoveryCodeHashes = append(recoveryCodeHashes, utils.HashToken(normalizeRecoveryCode(code)))
+	}
+
+	// Call the repository
+	if err := m.mfaRepository.ConfirmMFAFactor(ctx, factor, recoveryCodeHashes); err != nil {
+		log.Println("[MFAUsecase][ConfirmTOTP] Error in ConfirmMFAFactor: ", err)
+		return nil, err
+	}
+
+	// The confirming code must not be usable for a login
+	if err := m.mfaRepository.UseTOTPStep(ctx, factor, step); err != nil {
+		log.Println("[MFAUsecase][ConfirmTOTP] Error in UseTOTPStep: ", err)
+		return nil, err
+	}
+
+	return &domain.ConfirmTOTPResponse{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+// DisableTOTP turns MFA off, the user proves they still hold a factor with a code or a recovery code
+// DisableTOTP turns MFA off after checking a code. Wrong codes count towards the login lockout of the username
+// and the client IP, so it cannot be used to guess codes.
+func (m *mfaUsecase) DisableTOTP(ctx context.Context, disableTOTPRequest *domain.DisableTOTPRequest) error {
+	user, err := m.userRepository.GetUserByUserID(ctx, disableTOTPRequest.UserID)
+	if err != nil {
+		log.Println("[MFAUsecase][DisableTOTP] Error in GetUserByUserID: ", err)
+		return err
+	}
+
+	if err := m.loginGuard.check(ctx, user.UserName, disableTOTPRequest.ClientIP); err != nil {
+		log.Println("[MFAUsecase][DisableTOTP] Error in check: ", err)
+		return err
+	}
+
+	factor, err := m.mfaRepository.GetMFAFactor(ctx, disableTOTPRequest.UserID)
+	if err != nil {
+		log.Println("[MFAUsecase][DisableTOTP] Error in GetMFAFactor: ", err)
+		return err
+	}
+	if factor == nil || factor.ConfirmedAt == nil {
+		return cerr.NewCustomErrorWithCodeAndOrigin("MFA is not enabled", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	if err := verifyMFACode(ctx, m.mfaRepository, factor, disableTOTPRequest.Code); err != nil {
+		log.Println("[MFAUsecase][DisableTOTP] Error in verifyMFACode: ", err)
+		if cerr.GetErrorCode(err) != cerr.InvalidRequestErrorCode {
+			return err
+		}
+		return m.loginGuard.fail(ctx, user.UserName, disableTOTPRequest.ClientIP, err)
+	}
+
+	// Call the repository
+	if err := m.mfaRepository.DeleteMFAFactor(ctx, disableTOTPRequest.UserID); err != nil {
+		log.Println("[MFAUsecase][DisableTOTP] Error in DeleteMFAFactor: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// confirmedMFAFactor returns the TOTP factor of the user when MFA is enabled, nil otherwise
+func confirmedMFAFactor(ctx context.Context, mfaRepository models.MFARepository, userID string) (*models.MFAFactor, error) {
+	factor, err := mfaRepository.GetMFAFactor(ctx, userID)
+	if err != nil || factor == nil || factor.ConfirmedAt == nil {
+		return nil, err
+	}
+	return factor, nil
+}
+
+// verifyMFACode accepts a code of the authenticator app or one of the recovery codes of the user, either can
+// only be used once
+func verifyMFACode(ctx context.Context, mfaRepository models.MFARepository, factor *models.MFAFactor, code string) error {
+	code = strings.TrimSpace(code)
+	if step, ok := totp.Validate(factor.Secret, code, time.Now()); ok {
+		return mfaRepository.UseTOTPStep(ctx, factor, step)
+	}
+
+	// Anything that is not a valid code of the app is tried as a recovery code
+	return mfaRepository.UseRecoveryCode(ctx, factor.UserUUID.String(), utils.HashToken(normalizeRecoveryCode(code)))
+}
+
+// newRecoveryCode returns a random recovery code such as k3m9x-2qz7d
+func newRecoveryCode() (string, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", err
+	}
+	code := strings.ToLower(secret[:10])
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode ignores case, dashes and spaces so codes can be typed as convenient
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
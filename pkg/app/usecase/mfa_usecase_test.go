@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+)
+
+// fakeMFARepository holds the factor of one user, every recovery code is wrong
+type fakeMFARepository struct {
+	models.MFARepository
+	factor  *models.MFAFactor
+	deleted bool
+}
+
+func (r *fakeMFARepository) GetMFAFactor(ctx context.Context, userID string) (*models.MFAFactor, error) {
+	return r.factor, nil
+}
+
+func (r *fakeMFARepository) UseTOTPStep(ctx context.Context, factor *models.MFAFactor, step int64) error {
+	return nil
+}
+
+func (r *fakeMFARepository) UseRecoveryCode(ctx context.Context, userID string, codeHash string) error {
+	return cerr.NewCustomErrorWithCodeAndOrigin("Invalid code", cerr.InvalidRequestErrorCode, nil)
+}
+
+func (r *fakeMFARepository) DeleteMFAFactor(ctx context.Context, userID string) error {
+	r.deleted = true
+	return nil
+}
+
+func TestDisableTOTPCountsWrongCodes(t *testing.T) {
+	setLoginGuardConfig(t, 3, 50, 15, 0)
+
+	user := &models.User{UUID: uuid.Must(uuid.NewV4()), UserName: "alice"}
+	confirmedAt := time.Now()
+	mfaRepository := &fakeMFARepository{factor: &models.MFAFactor{UserUUID: user.UUID, Secret: "JBSWY3DPEHPK3PXP", ConfirmedAt: &confirmedAt}}
+	loginAttemptRepository := &fakeLoginAttemptRepository{}
+	mfa := NewMFAUsecase(&fakeUserRepository{user: user}, mfaRepository, loginAttemptRepository)
+	request := &domain.DisableTOTPRequest{Code: "not-a-code", UserID: user.UUID.String(), ClientIP: "192.0.2.1"}
+
+	for i := 1; i <= 3; i++ {
+		err := mfa.DisableTOTP(context.Background(), request)
+		if code := cerr.GetErrorCode(err); code != cerr.InvalidRequestErrorCode {
+			t.Fatalf("DisableTOTP attempt %d error code = %d, want %d", i, code, cerr.InvalidRequestErrorCode)
+		}
+		for _, key := range []string{models.LoginAttemptKindUserName + ":alice", models.LoginAttemptKindClientIP + ":192.0.2.1"} {
+			if attempt := loginAttemptRepository.attempts[key]; attempt == nil || attempt.Failures != i {
+				t.Fatalf("after attempt %d %s = %+v, want %d failures", i, key, attempt, i)
+			}
+		}
+	}
+
+	// The threshold is reached, the user is locked out before the code is looked at
+	err := mfa.DisableTOTP(context.Background(), request)
+	if code := cerr.GetErrorCode(err); code != cerr.TooManyRequestsErrorCode {
+		t.Fatalf("DisableTOTP of a locked user error code = %d, want %d", code, cerr.TooManyRequestsErrorCode)
+	}
+	if mfaRepository.deleted {
+		t.Error("DisableTOTP deleted the factor of a locked user")
+	}
+}
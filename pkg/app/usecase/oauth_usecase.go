@@ -0,0 +1,671 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"html"
+	"log"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/jwt"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/restclient"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// codeChallengeMethodS256 is the only PKCE method accepted, plain challenges offer no protection
+const codeChallengeMethodS256 = "S256"
+
+// codeVerifierPattern is the code verifier syntax of RFC 7636 section 4.1
+var codeVerifierPattern = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+
+// supportedGrantTypes are the grant types clients can be registered for
+var supportedGrantTypes = []string{models.GrantTypeAuthorizationCode, models.GrantTypeRefreshToken, models.GrantTypeClientCredentials}
+
+type oauthUsecase struct {
+	userRepository              models.UserRepository
+	sessionRepository           models.SessionRepository
+	oauthClientRepository       models.OAuthClientRepository
+	authorizationCodeRepository models.AuthorizationCodeRepository
+	mfaRepository               models.MFARepository
+	tokens                      *tokenIssuer
+	loginGuard                  *loginGuard
+}
+
+func NewOAuthUsecase(userRepository models.UserRepository, refreshTokenRepository models.RefreshTokenRepository, revocationRepository models.RevocationRepository, sessionRepository models.SessionRepository, oauthClientRepository models.OAuthClientRepository, authorizationCodeRepository models.AuthorizationCodeRepository, loginAttemptRepository models.LoginAttemptRepository, mfaRepository models.MFARepository, claimsBuilder domain.ClaimsBuilder) domain.OAuthUsecase {
+	return &oauthUsecase{
+		userRepository:              userRepository,
+		sessionRepository:           sessionRepository,
+		oauthClientRepository:       oauthClientRepository,
+		authorizationCodeRepository: authorizationCodeRepository,
+		mfaRepository:               mfaRepository,
+		tokens: &tokenIssuer{
+			userRepository:         userRepository,
+			refreshTokenRepository: refreshTokenRepository,
+			revocationRepository:   revocationRepository,
+			sessionRepository:      sessionRepository,
+			claimsBuilder:          claimsBuilder,
+		},
+		loginGuard: &loginGuard{loginAttemptRepository: loginAttemptRepository},
+	}
+}
+
+func (o *oauthUsecase) RegisterClient(ctx context.Context, registerClientRequest *domain.RegisterClientRequest) (*domain.RegisterClientResponse, error) {
+	grantTypes := registerClientRequest.GrantTypes
+	if len(grantTypes) == 0 {
+		grantTypes = []string{models.GrantTypeAuthorizationCode, models.GrantTypeRefreshToken}
+	}
+	for _, grantType := range grantTypes {
+		if !utils.Contains(supportedGrantTypes, grantType) {
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("Unsupported grant type: "+grantType, cerr.InvalidRequestErrorCode, nil)
+		}
+	}
+
+	// Public clients cannot keep a secret, so they cannot authenticate as themselves
+	if registerClientRequest.Public && utils.Contains(grantTypes, models.GrantTypeClientCredentials) {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Public clients cannot use the client credentials grant", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	userFacing := utils.Contains(grantTypes, models.GrantTypeAuthorizationCode)
+	if userFacing && len(registerClientRequest.RedirectURIs) == 0 {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Redirect URIs are required for the authorization code grant", cerr.InvalidRequestErrorCode, nil)
+	}
+	for _, redirectURI := range registerClientRequest.RedirectURIs {
+		if err := validateRedirectURI(redirectURI); err != nil {
+			return nil, err
+		}
+	}
+
+	// User facing clients get the OpenID Connect scopes unless they are restricted to some of them, machine
+	// clients only get the API scopes they are registered with
+	scopes := registerClientRequest.Scopes
+	if len(scopes) == 0 && userFacing {
+		scopes = domain.OAuthScopes
+	}
+	// Client tokens grant the permissions among their scopes, so only known scopes can be allowed
+	for _, scope := range scopes {
+		if !utils.Contains(domain.OAuthScopes, scope) && scope != domain.ScopeProfileWrite && !utils.Contains(models.DefaultPermissions, scope) {
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("Unknown scope: "+scope, cerr.InvalidRequestErrorCode, nil)
+		}
+	}
+
+	clientID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		log.Println("[OAuthUsecase][RegisterClient] Error in generating client id: ", err)
+		return nil, err
+	}
+
+	client := &models.OAuthClient{
+		ClientID:     clientID,
+		Name:         strings.TrimSpace(registerClientRequest.Name),
+		RedirectURIs: strings.Join(registerClientRequest.RedirectURIs, " "),
+		Scopes:       strings.Join(scopes, " "),
+		GrantTypes:   strings.Join(grantTypes, " "),
+		FirstParty:   registerClientRequest.FirstParty,
+		Public:       registerClientRequest.Public,
+	}
+
+	// Only confidential clients get a secret, it is stored hashed and shown once
+	var clientSecret string
+	if !client.Public {
+		clientSecret, err = utils.GenerateRandomToken(32)
+		if err != nil {
+			log.Println("[OAuthUsecase][RegisterClient] Error in generating client secret: ", err)
+			return nil, err
+		}
+
+		hashedSecret, err := bcrypt.GenerateFromPassword([]byte(clientSecret), bcrypt.DefaultCost)
+		if err != nil {
+			log.Println("[OAuthUsecase][RegisterClient] Error in hashing the client secret: ", err)
+			return nil, err
+		}
+		client.ClientSecretHash = string(hashedSecret)
+	}
+
+	if err := o.oauthClientRepository.CreateClient(ctx, client); err != nil {
+		log.Println("[OAuthUsecase][RegisterClient] Error in CreateClient: ", err)
+		return nil, err
+	}
+
+	return &domain.RegisterClientResponse{
+		ClientID:     client.ClientID,
+		ClientSecret: clientSecret,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectURIList(),
+		Scopes:       client.ScopeList(),
+		GrantTypes:   client.GrantTypeList(),
+		FirstParty:   client.FirstParty,
+		Public:       client.Public,
+	}, nil
+}
+
+func (o *oauthUsecase) ValidateAuthorizeRequest(ctx context.Context, authorizeRequest *domain.AuthorizeRequest) (*domain.AuthorizeResponse, error) {
+	client, scopes, err := o.checkAuthorizeRequest(ctx, authorizeRequest)
+	if err != nil {
+		return authorizeError(authorizeRequest, err)
+	}
+
+	return &domain.AuthorizeResponse{
+		Prompt: &domain.AuthorizePrompt{
+			ClientName: client.Name,
+			FirstParty: client.FirstParty,
+			Scopes:     scopes,
+		},
+	}, nil
+}
+
+func (o *oauthUsecase) Authorize(ctx context.Context, authorizeLoginRequest *domain.AuthorizeLoginRequest) (*domain.AuthorizeResponse, error) {
+	authorizeRequest := &authorizeLoginRequest.AuthorizeRequest
+
+	// The parameters travel through the login form, so they are checked again
+	client, scopes, err := o.checkAuthorizeRequest(ctx, authorizeRequest)
+	if err != nil {
+		return authorizeError(authorizeRequest, err)
+	}
+
+	// First party clients are trusted, any other client needs the user's consent
+	if !client.FirstParty && authorizeLoginRequest.Consent != "approve" {
+		return authorizeError(authorizeRequest, domain.NewOAuthError(domain.OAuthErrorAccessDenied, "The user denied the request"))
+	}
+
+	// Remove the space from the username
+	userName := html.EscapeString(strings.TrimSpace(authorizeLoginRequest.UserName))
+
+	// Wrong credentials show the login form again instead of failing the authorization request
+	loginPrompt := func(message string) *domain.AuthorizeResponse {
+		return &domain.AuthorizeResponse{
+			Prompt: &domain.AuthorizePrompt{
+				ClientName: client.Name,
+				FirstParty: client.FirstParty,
+				Scopes:     scopes,
+				Error:      message,
+			},
+		}
+	}
+
+	user, err := authenticatePassword(ctx, o.userRepository, o.loginGuard, userName, authorizeLoginRequest.Password, authorizeLoginRequest.ClientIP)
+	if err != nil {
+		log.Println("[OAuthUsecase][Authorize] Error in authenticatePassword: ", err)
+		message := "Invalid username or password"
+		if code := cerr.GetErrorCode(err); code == cerr.TooManyRequestsErrorCode || code == cerr.ForbiddenErrorCode {
+			message = cerr.GetErrorMessage(err)
+		}
+		return loginPrompt(message), nil
+	}
+
+	// A user with MFA enabled also enters a code, wrong codes count towards the lockout like wrong passwords
+	authMethods := []string{models.AuthMethodPassword}
+	factor, err := confirmedMFAFactor(ctx, o.mfaRepository, user.UUID.String())
+	if err != nil {
+		log.Println("[OAuthUsecase][Authorize] Error in confirmedMFAFactor: ", err)
+		return nil, err
+	}
+	if factor != nil {
+		if strings.TrimSpace(authorizeLoginRequest.MFACode) == "" {
+			return loginPrompt("Enter the code of your authenticator app or a recovery code"), nil
+		}
+		if err := verifyMFACode(ctx, o.mfaRepository, factor, authorizeLoginRequest.MFACode); err != nil {
+			log.Println("[OAuthUsecase][Authorize] Error in verifyMFACode: ", err)
+			if cerr.GetErrorCode(err) != cerr.InvalidRequestErrorCode {
+				return nil, err
+			}
+			loginErr := o.loginGuard.fail(ctx, user.UserName, authorizeLoginRequest.ClientIP, err)
+			if cerr.GetErrorCode(loginErr) != cerr.InvalidRequestErrorCode {
+				return nil, loginErr
+			}
+			return loginPrompt(cerr.GetErrorMessage(loginErr)), nil
+		}
+		authMethods = mfaAuthMethods
+	}
+
+	if err := o.loginGuard.recordSuccess(ctx, user.UserName); err != nil {
+		log.Println("[OAuthUsecase][Authorize] Error in recordSuccess: ", err)
+		return nil, err
+	}
+
+	// The session starts when the user authenticates, the code is exchanged for its tokens
+	session, err := o.tokens.startSession(ctx, user, authorizeLoginRequest.UserAgent, authorizeLoginRequest.ClientIP, authMethods)
+	if err != nil {
+		log.Println("[OAuthUsecase][Authorize] Error in startSession: ", err)
+		return nil, err
+	}
+
+	code, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		log.Println("[OAuthUsecase][Authorize] Error in generating authorization code: ", err)
+		return nil, err
+	}
+
+	authorizationCode := &models.AuthorizationCode{
+		CodeHash:            utils.HashToken(code),
+		ClientID:            client.ClientID,
+		UserUUID:            user.UUID,
+		SessionUUID:         session.UUID,
+		RedirectURI:         authorizeRequest.RedirectURI,
+		Scope:               strings.Join(scopes, " "),
+		Nonce:               authorizeRequest.Nonce,
+		CodeChallenge:       authorizeRequest.CodeChallenge,
+		CodeChallengeMethod: authorizeRequest.CodeChallengeMethod,
+		ExpiresAt:           time.Now().Add(time.Duration(env.EnvConfig.OAuthCodeExpirationTime) * time.Minute),
+	}
+	if err := o.authorizationCodeRepository.CreateAuthorizationCode(ctx, authorizationCode); err != nil {
+		log.Println("[OAuthUsecase][Authorize] Error in CreateAuthorizationCode: ", err)
+		return nil, err
+	}
+
+	return &domain.AuthorizeResponse{
+		RedirectURL: authorizeRedirect(authorizeRequest, url.Values{"code": {code}}),
+	}, nil
+}
+
+func (o *oauthUsecase) Token(ctx context.Context, tokenRequest *domain.TokenRequest) (*domain.TokenResponse, error) {
+	client, err := o.authenticateClient(ctx, tokenRequest.ClientID, tokenRequest.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if !utils.Contains(supportedGrantTypes, tokenRequest.GrantType) {
+		return nil, domain.NewOAuthError(domain.OAuthErrorUnsupportedGrantType, "Unsupported grant type")
+	}
+	if !utils.Contains(client.GrantTypeList(), tokenRequest.GrantType) {
+		return nil, domain.NewOAuthError(domain.OAuthErrorUnauthorizedClient, "The client may not use this grant type")
+	}
+
+	var tokens *issuedTokens
+	switch tokenRequest.GrantType {
+	case models.GrantTypeAuthorizationCode:
+		tokens, err = o.exchangeAuthorizationCode(ctx, client, tokenRequest)
+	case models.GrantTypeRefreshToken:
+		tokens, err = o.refreshToken(ctx, client, tokenRequest)
+	case models.GrantTypeClientCredentials:
+		tokens, err = o.clientCredentials(ctx, client, tokenRequest)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tokenLifetime, err := jwt.TokenLifetime()
+	if err != nil {
+		log.Println("[OAuthUsecase][Token] Error in TokenLifetime: ", err)
+		return nil, err
+	}
+
+	return &domain.TokenResponse{
+		BearerTokenResponse: restclient.BearerTokenResponse{
+			AccessToken: tokens.AccessToken,
+			ExpiresIn:   int(tokenLifetime.Seconds()),
+			TokenType:   "Bearer",
+			Scope:       strings.Join(tokens.Scopes, " "),
+		},
+		RefreshToken: tokens.RefreshToken,
+		IDToken:      tokens.IDToken,
+	}, nil
+}
+
+func (o *oauthUsecase) Introspect(ctx context.Context, introspectRequest *domain.IntrospectRequest) (*domain.IntrospectResponse, error) {
+	// Only resource servers holding a client secret may introspect tokens
+	client, err := o.authenticateClient(ctx, introspectRequest.ClientID, introspectRequest.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if client.Public {
+		return nil, domain.NewOAuthError(domain.OAuthErrorInvalidClient, "Client authentication failed")
+	}
+
+	// The hint only decides which kind of token is looked up first
+	if introspectRequest.TokenTypeHint == models.GrantTypeRefreshToken {
+		if response := o.introspectRefreshToken(ctx, introspectRequest.Token); response.Active {
+			return response, nil
+		}
+		return o.introspectAccessToken(ctx, introspectRequest.Token), nil
+	}
+
+	if response := o.introspectAccessToken(ctx, introspectRequest.Token); response.Active {
+		return response, nil
+	}
+	return o.introspectRefreshToken(ctx, introspectRequest.Token), nil
+}
+
+// introspectAccessToken describes an access token, expired, revoked or otherwise invalid tokens are inactive
+func (o *oauthUsecase) introspectAccessToken(ctx context.Context, token string) *domain.IntrospectResponse {
+	claims, err := jwt.GetClaims(ctx, token)
+	if err != nil {
+		return &domain.IntrospectResponse{Active: false}
+	}
+
+	response := &domain.IntrospectResponse{
+		Active:    true,
+		TokenType: "Bearer",
+		Exp:       jwt.ExpiresAt(claims).Unix(),
+		Iat:       jwt.NumericDate(claims, "iat").Unix(),
+	}
+	response.Scope, _ = claims["scope"].(string)
+	response.ClientID, _ = claims["client_id"].(string)
+	response.Username, _ = claims["preferred_username"].(string)
+	response.Sub, _ = claims["sub"].(string)
+	response.Aud, _ = claims["aud"].(string)
+	response.Iss, _ = claims["iss"].(string)
+	response.Jti, _ = claims["jti"].(string)
+	response.Sid, _ = claims["sid"].(string)
+	response.TenantID, _ = claims["tenant_id"].(string)
+
+	return response
+}
+
+// introspectRefreshToken describes a refresh token, it is active until it is rotated, revoked or expires
+func (o *oauthUsecase) introspectRefreshToken(ctx context.Context, token string) *domain.IntrospectResponse {
+	refreshToken, err := o.tokens.refreshTokenRepository.GetRefreshTokenByHash(ctx, utils.HashToken(token))
+	if err != nil || refreshToken.RevokedAt != nil || time.Now().After(refreshToken.ExpiresAt) {
+		return &domain.IntrospectResponse{Active: false}
+	}
+
+	response := &domain.IntrospectResponse{
+		Active:    true,
+		TokenType: "refresh_token",
+		Scope:     refreshToken.Scope,
+		ClientID:  refreshToken.ClientID,
+		Exp:       refreshToken.ExpiresAt.Unix(),
+		Iat:       refreshToken.CreatedAt.Unix(),
+		Sub:       refreshToken.UserUUID.String(),
+		Iss:       env.EnvConfig.JWTIssuer,
+		Sid:       refreshToken.FamilyID.String(),
+	}
+	if user, err := o.userRepository.GetUserByUserID(ctx, refreshToken.UserUUID.String()); err == nil {
+		response.Username = user.UserName
+	}
+
+	return response
+}
+
+// exchangeAuthorizationCode redeems a code issued to the client, the code verifier must match its PKCE challenge
+func (o *oauthUsecase) exchangeAuthorizationCode(ctx context.Context, client *models.OAuthClient, tokenRequest *domain.TokenRequest) (*issuedTokens, error) {
+	if tokenRequest.Code == "" || tokenRequest.CodeVerifier == "" {
+		return nil, domain.NewOAuthError(domain.OAuthErrorInvalidRequest, "code and code_verifier are required")
+	}
+
+	// Only the hash of a code is ever stored
+	code, err := o.authorizationCodeRepository.GetAuthorizationCodeByHash(ctx, utils.HashToken(tokenRequest.Code))
+	if err != nil {
+		log.Println("[OAuthUsecase][exchangeAuthorizationCode] Error in GetAuthorizationCodeByHash: ", err)
+		return nil, invalidGrant(err)
+	}
+
+	if code.ClientID != client.ClientID {
+		return nil, domain.NewOAuthError(domain.OAuthErrorInvalidGrant, "Authorization code was issued to another client")
+	}
+
+	// A code presented twice has leaked, the tokens it was exchanged for are revoked with its session
+	if code.UsedAt != nil {
+		return nil, o.codeReplayed(ctx, code)
+	}
+
+	if time.Now().After(code.ExpiresAt) {
+		return nil, domain.NewOAuthError(domain.OAuthErrorInvalidGrant, "Authorization code has expired")
+	}
+
+	if tokenRequest.RedirectURI != code.RedirectURI {
+		return nil, domain.NewOAuthError(domain.OAuthErrorInvalidGrant, "redirect_uri does not match the authorization request")
+	}
+
+	if !verifyCodeChallenge(tokenRequest.CodeVerifier, code.CodeChallenge) {
+		return nil, domain.NewOAuthError(domain.OAuthErrorInvalidGrant, "code_verifier does not match the code challenge")
+	}
+
+	// Losing the race against a concurrent exchange is treated as a replay
+	if err := o.authorizationCodeRepository.UseAuthorizationCode(ctx, code); err != nil {
+		log.Println("[OAuthUsecase][exchangeAuthorizationCode] Error in UseAuthorizationCode: ", err)
+		if cerr.GetErrorCode(err) == cerr.InvalidRequestErrorCode {
+			return nil, o.codeReplayed(ctx, code)
+		}
+		return nil, err
+	}
+
+	user, err := o.userRepository.GetUserByUserID(ctx, code.UserUUID.String())
+	if err != nil {
+		log.Println("[OAuthUsecase][exchangeAuthorizationCode] Error in GetUserByUserID: ", err)
+		return nil, invalidGrant(err)
+	}
+
+	session, err := o.sessionRepository.GetSessionByID(ctx, code.SessionUUID.String())
+	if err != nil {
+		log.Println("[OAuthUsecase][exchangeAuthorizationCode] Error in GetSessionByID: ", err)
+		return nil, invalidGrant(err)
+	}
+	if session.EndedAt != nil {
+		return nil, domain.NewOAuthError(domain.OAuthErrorInvalidGrant, "Session has ended")
+	}
+
+	tokens, err := o.tokens.issueTokens(ctx, user, session, &tokenGrant{
+		ClientID: client.ClientID,
+		Scopes:   strings.Fields(code.Scope),
+		Nonce:    code.Nonce,
+	})
+	if err != nil {
+		log.Println("[OAuthUsecase][exchangeAuthorizationCode] Error in issueTokens: ", err)
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// refreshToken rotates a refresh token issued to the client
+func (o *oauthUsecase) refreshToken(ctx context.Context, client *models.OAuthClient, tokenRequest *domain.TokenRequest) (*issuedTokens, error) {
+	if tokenRequest.RefreshToken == "" {
+		return nil, domain.NewOAuthError(domain.OAuthErrorInvalidRequest, "refresh_token is required")
+	}
+
+	tokens, err := o.tokens.refreshTokens(ctx, tokenRequest.RefreshToken, client.ClientID)
+	if err != nil {
+		log.Println("[OAuthUsecase][refreshToken] Error in refreshTokens: ", err)
+		return nil, invalidGrant(err)
+	}
+
+	return tokens, nil
+}
+
+// clientCredentials issues an access token to the client itself, the client is the subject of the token and
+// no refresh token is issued as the client can always authenticate again
+func (o *oauthUsecase) clientCredentials(ctx context.Context, client *models.OAuthClient, tokenRequest *domain.TokenRequest) (*issuedTokens, error) {
+	// A public client has nothing to authenticate with
+	if client.Public {
+		return nil, domain.NewOAuthError(domain.OAuthErrorUnauthorizedClient, "Public clients cannot use the client credentials grant")
+	}
+
+	// Without a scope parameter the client gets every scope it is allowed
+	scopes := client.ScopeList()
+	if tokenRequest.Scope != "" {
+		scopes = nil
+		for _, scope := range strings.Fields(tokenRequest.Scope) {
+			if !utils.Contains(client.ScopeList(), scope) {
+				return nil, domain.NewOAuthError(domain.OAuthErrorInvalidScope, "The client may not request the "+scope+" scope")
+			}
+			if !utils.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	claims := map[string]interface{}{
+		"client_id": client.ClientID,
+		"gty":       models.GrantTypeClientCredentials,
+		// The token is only accepted in the tenant it was requested in
+		"tenant_id": tenant.ID(ctx),
+	}
+	if len(scopes) > 0 {
+		claims["scope"] = strings.Join(scopes, " ")
+	}
+
+	accessToken, err := jwt.GenerateToken(client.ClientID, "", claims)
+	if err != nil {
+		log.Println("[OAuthUsecase][clientCredentials] Error in GenerateToken: ", err)
+		return nil, err
+	}
+
+	return &issuedTokens{
+		AccessToken: accessToken,
+		Scopes:      scopes,
+	}, nil
+}
+
+// codeReplayed ends the session a reused authorization code was issued for
+func (o *oauthUsecase) codeReplayed(ctx context.Context, code *models.AuthorizationCode) error {
+	log.Println("[OAuthUsecase][codeReplayed] Authorization code reuse detected for session: ", code.SessionUUID)
+	if err := o.tokens.endSession(ctx, code.SessionUUID.String(), models.RefreshTokenRevokedReuseDetected); err != nil {
+		log.Println("[OAuthUsecase][codeReplayed] Error in endSession: ", err)
+		return err
+	}
+
+	return domain.NewOAuthError(domain.OAuthErrorInvalidGrant, "Authorization code has already been used")
+}
+
+// authenticateClient looks the client up, confidential clients must present their secret
+func (o *oauthUsecase) authenticateClient(ctx context.Context, clientID string, clientSecret string) (*models.OAuthClient, error) {
+	if clientID == "" {
+		return nil, domain.NewOAuthError(domain.OAuthErrorInvalidClient, "Client authentication failed")
+	}
+
+	client, err := o.oauthClientRepository.GetClientByClientID(ctx, clientID)
+	if err != nil {
+		log.Println("[OAuthUsecase][authenticateClient] Error in GetClientByClientID: ", err)
+		if cerr.GetErrorCode(err) == cerr.NotFoundErrorCode {
+			return nil, domain.NewOAuthError(domain.OAuthErrorInvalidClient, "Client authentication failed")
+		}
+		return nil, err
+	}
+
+	if client.Public {
+		return client, nil
+	}
+
+	if clientSecret == "" || bcrypt.CompareHashAndPassword([]byte(client.ClientSecretHash), []byte(clientSecret)) != nil {
+		return nil, domain.NewOAuthError(domain.OAuthErrorInvalidClient, "Client authentication failed")
+	}
+
+	return client, nil
+}
+
+// checkAuthorizeRequest validates an authorization request and returns the client and the granted scopes.
+// An unknown client or redirect URI is reported as a plain error because the user must not be redirected to
+// it, any other problem is reported as an OAuth error that is sent back to the client.
+func (o *oauthUsecase) checkAuthorizeRequest(ctx context.Context, authorizeRequest *domain.AuthorizeRequest) (*models.OAuthClient, []string, error) {
+	client, err := o.oauthClientRepository.GetClientByClientID(ctx, authorizeRequest.ClientID)
+	if err != nil {
+		log.Println("[OAuthUsecase][checkAuthorizeRequest] Error in GetClientByClientID: ", err)
+		return nil, nil, err
+	}
+
+	// Redirect URIs must match a registered one exactly
+	if !utils.Contains(client.RedirectURIList(), authorizeRequest.RedirectURI) {
+		return nil, nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid redirect_uri", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	if authorizeRequest.ResponseType != "code" {
+		return nil, nil, domain.NewOAuthError(domain.OAuthErrorUnsupportedResponseType, "Only the code response type is supported")
+	}
+
+	if !utils.Contains(client.GrantTypeList(), models.GrantTypeAuthorizationCode) {
+		return nil, nil, domain.NewOAuthError(domain.OAuthErrorUnauthorizedClient, "The client may not use the authorization code grant")
+	}
+
+	if authorizeRequest.CodeChallenge == "" || authorizeRequest.CodeChallengeMethod != codeChallengeMethodS256 {
+		return nil, nil, domain.NewOAuthError(domain.OAuthErrorInvalidRequest, "PKCE with the S256 code challenge method is required")
+	}
+
+	var scopes []string
+	for _, scope := range strings.Fields(authorizeRequest.Scope) {
+		if !utils.Contains(client.ScopeList(), scope) {
+			return nil, nil, domain.NewOAuthError(domain.OAuthErrorInvalidScope, "The client may not request the "+scope+" scope")
+		}
+		if !utils.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return client, scopes, nil
+}
+
+// authorizeError sends OAuth errors back to the client, any other error is returned to be shown to the user
+func authorizeError(authorizeRequest *domain.AuthorizeRequest, err error) (*domain.AuthorizeResponse, error) {
+	var oauthErr *domain.OAuthError
+	if !errors.As(err, &oauthErr) {
+		return nil, err
+	}
+
+	return &domain.AuthorizeResponse{
+		RedirectURL: authorizeRedirect(authorizeRequest, url.Values{
+			"error":             {oauthErr.Code},
+			"error_description": {oauthErr.Description},
+		}),
+	}, nil
+}
+
+// authorizeRedirect adds the response parameters, the state and the issuer to the client's redirect URI
+func authorizeRedirect(authorizeRequest *domain.AuthorizeRequest, params url.Values) string {
+	redirectURL, err := url.Parse(authorizeRequest.RedirectURI)
+	if err != nil {
+		return authorizeRequest.RedirectURI
+	}
+
+	query := redirectURL.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	if authorizeRequest.State != "" {
+		query.Set("state", authorizeRequest.State)
+	}
+	query.Set("iss", env.EnvConfig.JWTIssuer)
+	redirectURL.RawQuery = query.Encode()
+
+	return redirectURL.String()
+}
+
+// invalidGrant reports the client errors of the token issuer as an invalid grant
+func invalidGrant(err error) error {
+	switch cerr.GetErrorCode(err) {
+	case cerr.InvalidRequestErrorCode, cerr.NotFoundErrorCode:
+		return domain.NewOAuthError(domain.OAuthErrorInvalidGrant, cerr.GetErrorMessage(err))
+	}
+	return err
+}
+
+// validateRedirectURI accepts absolute URIs without a fragment, plain http is only allowed on the loopback
+// interface used by native apps
+func validateRedirectURI(redirectURI string) error {
+	parsed, err := url.Parse(redirectURI)
+	if err != nil || parsed.Scheme == "" || parsed.Fragment != "" {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Invalid redirect URI: "+redirectURI, cerr.InvalidRequestErrorCode, err)
+	}
+
+	if parsed.Scheme == "http" {
+		host := parsed.Hostname()
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return cerr.NewCustomErrorWithCodeAndOrigin("Redirect URI must use https: "+redirectURI, cerr.InvalidRequestErrorCode, nil)
+		}
+	}
+
+	return nil
+}
+
+// verifyCodeChallenge checks the code verifier against the S256 code challenge of RFC 7636
+func verifyCodeChallenge(codeVerifier string, codeChallenge string) bool {
+	if !codeVerifierPattern.MatchString(codeVerifier) {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(codeVerifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(codeChallenge)) == 1
+}
@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	// The verifier and challenge of RFC 7636 appendix B
+	const (
+		verifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+		challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	)
+
+	tests := []struct {
+		name      string
+		verifier  string
+		challenge string
+		ok        bool
+	}{
+		{name: "RFC 7636 appendix B", verifier: verifier, challenge: challenge, ok: true},
+		{name: "wrong verifier", verifier: "eBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk", challenge: challenge, ok: false},
+		{name: "plain challenge", verifier: verifier, challenge: verifier, ok: false},
+		{name: "padded challenge", verifier: verifier, challenge: challenge + "=", ok: false},
+		{name: "verifier too short", verifier: verifier[:42], challenge: challenge, ok: false},
+		{name: "verifier too long", verifier: strings.Repeat("a", 129), challenge: challenge, ok: false},
+		{name: "verifier with invalid characters", verifier: "dBjftJeZ4CVP+mB92K27uhbUJU1p1r/wW1gFWFOEjXk", challenge: challenge, ok: false},
+		{name: "empty challenge", verifier: verifier, challenge: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ok := verifyCodeChallenge(tt.verifier, tt.challenge); ok != tt.ok {
+				t.Errorf("verifyCodeChallenge(%q, %q) = %v, want %v", tt.verifier, tt.challenge, ok, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/utils"
+)
+
+// issueOneTimeToken creates a one time token for the user that expires after lifetime, only its hash is stored
+func issueOneTimeToken(ctx context.Context, oneTimeTokenRepository models.OneTimeTokenRepository, userID uuid.UUID, purpose string, lifetime time.Duration) (string, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	oneTimeToken := &models.OneTimeToken{
+		UserUUID:  userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(lifetime),
+	}
+	if err := oneTimeTokenRepository.CreateOneTimeToken(ctx, oneTimeToken); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
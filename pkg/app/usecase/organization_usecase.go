@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
+)
+
+type organizationUsecase struct {
+	organizationRepository models.OrganizationRepository
+	// knownTenants caches the tenants resolved so far, organizations are never deleted
+	knownTenants sync.Map
+}
+
+func NewOrganizationUsecase(organizationRepository models.OrganizationRepository) domain.OrganizationUsecase {
+	return &organizationUsecase{
+		organizationRepository: organizationRepository,
+	}
+}
+
+func (o *organizationUsecase) CreateOrganization(ctx context.Context, createOrganizationRequest *domain.CreateOrganizationRequest) (*domain.OrganizationResponse, error) {
+	tenantID := strings.ToLower(strings.TrimSpace(createOrganizationRequest.TenantID))
+	if !tenant.Valid(tenantID) {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid tenant id", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	organization := &models.Organization{
+		TenantID: tenantID,
+		Name:     strings.TrimSpace(createOrganizationRequest.Name),
+	}
+
+	// Call the repository
+	if err := o.organizationRepository.CreateOrganization(ctx, organization); err != nil {
+		log.Println("[OrganizationUsecase][CreateOrganization] Error in CreateOrganization: ", err)
+		return nil, err
+	}
+
+	return toOrganizationResponse(organization), nil
+}
+
+func (o *organizationUsecase) GetOrganizations(ctx context.Context) (*domain.GetOrganizationsResponse, error) {
+	// Call the repository
+	organizations, err := o.organizationRepository.GetOrganizations(ctx)
+	if err != nil {
+		log.Println("[OrganizationUsecase][GetOrganizations] Error in GetOrganizations: ", err)
+		return nil, err
+	}
+
+	response := &domain.GetOrganizationsResponse{Organizations: make([]domain.OrganizationResponse, 0, len(organizations))}
+	for i := range organizations {
+		response.Organizations = append(response.Organizations, *toOrganizationResponse(&organizations[i]))
+	}
+
+	return response, nil
+}
+
+// ResolveTenant checks that an organization exists for the tenant id
+func (o *organizationUsecase) ResolveTenant(ctx context.Context, tenantID string) error {
+	if _, ok := o.knownTenants.Load(tenantID); ok {
+		return nil
+	}
+
+	if !tenant.Valid(tenantID) {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Unknown tenant", cerr.NotFoundErrorCode, nil)
+	}
+
+	// Call the repository
+	if _, err := o.organizationRepository.GetOrganizationByTenantID(ctx, tenantID); err != nil {
+		log.Println("[OrganizationUsecase][ResolveTenant] Error in GetOrganizationByTenantID: ", err)
+		return err
+	}
+
+	o.knownTenants.Store(tenantID, true)
+	return nil
+}
+
+func toOrganizationResponse(organization *models.Organization) *domain.OrganizationResponse {
+	return &domain.OrganizationResponse{
+		TenantID:  organization.TenantID,
+		Name:      organization.Name,
+		CreatedAt: organization.CreatedAt.String(),
+	}
+}
@@ -0,0 +1,304 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/notifier"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Bounds of OTP_LENGTH, shorter codes are too easy to guess and longer ones are a chore to type
+const (
+	minOTPLength = 4
+	maxOTPLength = 10
+)
+
+type otpUsecase struct {
+	userRepository    models.UserRepository
+	otpCodeRepository models.OTPCodeRepository
+	mfaRepository     models.MFARepository
+	notifier          notifier.Notifier
+	tokens            *tokenIssuer
+	loginGuard        *loginGuard
+}
+
+func NewOTPUsecase(userRepository models.UserRepository, refreshTokenRepository models.RefreshTokenRepository, revocationRepository models.RevocationRepository, sessionRepository models.SessionRepository, loginAttemptRepository models.LoginAttemptRepository, mfaRepository models.MFARepository, otpCodeRepository models.OTPCodeRepository, claimsBuilder domain.ClaimsBuilder, notifier notifier.Notifier) domain.OTPUsecase {
+	return &otpUsecase{
+		userRepository:    userRepository,
+		otpCodeRepository: otpCodeRepository,
+		mfaRepository:     mfaRepository,
+		notifier:          notifier,
+		tokens: &tokenIssuer{
+			userRepository:         userRepository,
+			refreshTokenRepository: refreshTokenRepository,
+			revocationRepository:   revocationRepository,
+			sessionRepository:      sessionRepository,
+			claimsBuilder:          claimsBuilder,
+		},
+		loginGuard: &loginGuard{loginAttemptRepository: loginAttemptRepository},
+	}
+}
+
+// StartOTPLogin sends a login code to the email address or phone number. The code is looked up and sent in
+// the background, so unknown users answer as fast as known ones and the endpoint cannot be used to find out
+// who has an account. A user asking again within OTP_RESEND_INTERVAL_SECONDS gets no new code, so nobody can
+// be flooded with messages either.
+func (o *otpUsecase) StartOTPLogin(ctx context.Context, startOTPLoginRequest *domain.StartOTPLoginRequest) error {
+	channel, recipient, err := otpRecipient(startOTPLoginRequest.Email, startOTPLoginRequest.PhoneNumber)
+	if err != nil {
+		return err
+	}
+
+	// The request context ends with the response, the background work only keeps its tenant
+	go o.sendOTPCode(tenant.NewContext(context.Background(), tenant.ID(ctx)), channel, recipient)
+
+	return nil
+}
+
+// sendOTPCode sends a new login code to the user with the email address or phone number, if there is one
+func (o *otpUsecase) sendOTPCode(ctx context.Context, channel string, recipient string) {
+	user, err := o.findOTPUser(ctx, channel, recipient)
+	if err != nil {
+		if cerr.GetErrorCode(err) != cerr.InvalidRequestErrorCode {
+			log.Println("[OTPUsecase][sendOTPCode] Error in findOTPUser: ", err)
+		}
+		return
+	}
+
+	latest, err := o.otpCodeRepository.GetLatestOTPCode(ctx, user.UUID.String())
+	if err != nil {
+		log.Println("[OTPUsecase][sendOTPCode] Error in GetLatestOTPCode: ", err)
+		return
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < time.Duration(env.EnvConfig.OTPResendIntervalSeconds)*time.Second {
+		log.Println("[OTPUsecase][sendOTPCode] Code requested again too soon: ", user.UUID)
+		return
+	}
+
+	code, err := newOTPCode(otpLength())
+	if err != nil {
+		log.Println("[OTPUsecase][sendOTPCode] Error in newOTPCode: ", err)
+		return
+	}
+
+	// Short codes are hashed with bcrypt, a fast hash of a six digit code is reversed in no time
+	codeHash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		log.Println("[OTPUsecase][sendOTPCode] Error in hashing the code: ", err)
+		return
+	}
+
+	// Call the repository
+	otpCode := &models.OTPCode{
+		UserUUID:  user.UUID,
+		Channel:   channel,
+		CodeHash:  string(codeHash),
+		ExpiresAt: time.Now().Add(time.Duration(env.EnvConfig.OTPExpirationTime) * time.Minute),
+	}
+	if err := o.otpCodeRepository.CreateOTPCode(ctx, otpCode); err != nil {
+		log.Println("[OTPUsecase][sendOTPCode] Error in CreateOTPCode: ", err)
+		return
+	}
+
+	message := &notifier.Message{
+		Channel: channel,
+		To:      recipient,
+		Subject: "Your login code",
+		Body:    fmt.Sprintf("Your login code is %s, it expires in %d minutes.", code, env.EnvConfig.OTPExpirationTime),
+	}
+	if err := o.notifier.Notify(ctx, message); err != nil {
+		log.Println("[OTPUsecase][sendOTPCode] Error in Notify: ", err)
+	}
+}
+
+// VerifyOTPLogin logs the user in with the code sent to them. Wrong codes count towards the lockout of the
+// username like wrong passwords do, and a code is used up after OTP_MAX_ATTEMPTS wrong guesses.
+func (o *otpUsecase) VerifyOTPLogin(ctx context.Context, verifyOTPLoginRequest *domain.VerifyOTPLoginRequest) (*domain.LoginUserResponse, error) {
+	channel, recipient, err := otpRecipient(verifyOTPLoginRequest.Email, verifyOTPLoginRequest.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := o.findOTPUser(ctx, channel, recipient)
+	if err != nil {
+		if cerr.GetErrorCode(err) != cerr.InvalidRequestErrorCode {
+			log.Println("[OTPUsecase][VerifyOTPLogin] Error in findOTPUser: ", err)
+			return nil, err
+		}
+		// Guesses at unknown recipients count against the recipient and the client IP, and take as long as
+		// guesses at known ones
+		guardKey := otpGuardKey(channel, recipient)
+		if err := o.loginGuard.check(ctx, guardKey, verifyOTPLoginRequest.ClientIP); err != nil {
+			return nil, err
+		}
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(verifyOTPLoginRequest.Code))
+		return nil, o.loginGuard.fail(ctx, guardKey, verifyOTPLoginRequest.ClientIP, errInvalidOTPCode())
+	}
+
+	if err := o.loginGuard.check(ctx, user.UserName, verifyOTPLoginRequest.ClientIP); err != nil {
+		return nil, err
+	}
+
+	// Only the latest code sent on the channel the user names works
+	otpCode, err := o.otpCodeRepository.GetLatestOTPCode(ctx, user.UUID.String())
+	if err != nil {
+		log.Println("[OTPUsecase][VerifyOTPLogin] Error in GetLatestOTPCode: ", err)
+		return nil, err
+	}
+	if otpCode == nil || otpCode.Channel != channel || otpCode.UsedAt != nil || time.Now().After(otpCode.ExpiresAt) {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(verifyOTPLoginRequest.Code))
+		return nil, o.loginGuard.fail(ctx, user.UserName, verifyOTPLoginRequest.ClientIP, errInvalidOTPCode())
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(otpCode.CodeHash), []byte(strings.TrimSpace(verifyOTPLoginRequest.Code))); err != nil {
+		if err := o.otpCodeRepository.RecordOTPFailure(ctx, otpCode, env.EnvConfig.OTPMaxAttempts); err != nil {
+			log.Println("[OTPUsecase][VerifyOTPLogin] Error in RecordOTPFailure: ", err)
+			return nil, err
+		}
+		return nil, o.loginGuard.fail(ctx, user.UserName, verifyOTPLoginRequest.ClientIP, errInvalidOTPCode())
+	}
+
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	authMethods := []string{models.AuthMethodOTP}
+	if channel == notifier.ChannelSMS {
+		authMethods = append(authMethods, models.AuthMethodSMS)
+	}
+
+	// A user with MFA enabled also enters a code of the authenticator app, the login code stays valid until then
+	factor, err := confirmedMFAFactor(ctx, o.mfaRepository, user.UUID.String())
+	if err != nil {
+		log.Println("[OTPUsecase][VerifyOTPLogin] Error in confirmedMFAFactor: ", err)
+		return nil, err
+	}
+	if factor != nil {
+		if strings.TrimSpace(verifyOTPLoginRequest.MFACode) == "" {
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("MFA is enabled, an authentication code is required", cerr.InvalidRequestErrorCode, nil)
+		}
+		if err := verifyMFACode(ctx, o.mfaRepository, factor, verifyOTPLoginRequest.MFACode); err != nil {
+			log.Println("[OTPUsecase][VerifyOTPLogin] Error in verifyMFACode: ", err)
+			if cerr.GetErrorCode(err) != cerr.InvalidRequestErrorCode {
+				return nil, err
+			}
+			return nil, o.loginGuard.fail(ctx, user.UserName, verifyOTPLoginRequest.ClientIP, err)
+		}
+		authMethods = append(authMethods, models.AuthMethodMFA)
+	}
+
+	// Use the code up, a concurrent request with the same code fails here
+	if err := o.otpCodeRepository.UseOTPCode(ctx, otpCode); err != nil {
+		log.Println("[OTPUsecase][VerifyOTPLogin] Error in UseOTPCode: ", err)
+		return nil, err
+	}
+
+	if err := o.loginGuard.recordSuccess(ctx, user.UserName); err != nil {
+		log.Println("[OTPUsecase][VerifyOTPLogin] Error in recordSuccess: ", err)
+		return nil, err
+	}
+
+	// Record the session for this login
+	session, err := o.tokens.startSession(ctx, user, verifyOTPLoginRequest.UserAgent, verifyOTPLoginRequest.ClientIP, authMethods)
+	if err != nil {
+		log.Println("[OTPUsecase][VerifyOTPLogin] Error in startSession: ", err)
+		return nil, err
+	}
+
+	// Generate the JWT tokens
+	tokens, err := o.tokens.issueTokens(ctx, user, session, &tokenGrant{})
+	if err != nil {
+		log.Println("[OTPUsecase][VerifyOTPLogin] Error in issueTokens: ", err)
+		return nil, err
+	}
+
+	return &domain.LoginUserResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		IDToken:      tokens.IDToken,
+	}, nil
+}
+
+// findOTPUser looks the user up by the email address or phone number the code is sent to, email addresses
+// only match once they are verified
+func (o *otpUsecase) findOTPUser(ctx context.Context, channel string, recipient string) (*models.User, error) {
+	if channel == notifier.ChannelSMS {
+		return o.userRepository.GetUserByPhoneNumber(ctx, recipient)
+	}
+
+	user, err := o.userRepository.GetUserByEmail(ctx, recipient)
+	if err != nil {
+		return nil, err
+	}
+	// An address that was never verified may belong to someone else, it cannot be used to log in
+	if user.EmailVerifiedAt == nil {
+		return nil, errUserNotFound()
+	}
+	return user, nil
+}
+
+// otpRecipient normalizes the email address or phone number of the request, exactly one of them is given
+func otpRecipient(email string, phoneNumber string) (string, string, error) {
+	email, phoneNumber = strings.TrimSpace(email), strings.TrimSpace(phoneNumber)
+	if (email == "") == (phoneNumber == "") {
+		return "", "", cerr.NewCustomErrorWithCodeAndOrigin("Either email or phone_number is required", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	if email != "" {
+		normalized, err := normalizeEmail(email)
+		if err != nil {
+			return "", "", err
+		}
+		return notifier.ChannelEmail, *normalized, nil
+	}
+
+	normalized, err := normalizePhoneNumber(phoneNumber)
+	if err != nil {
+		return "", "", err
+	}
+	return notifier.ChannelSMS, *normalized, nil
+}
+
+// otpGuardKey is the key failed logins with an unknown recipient are counted under. The prefix keeps it apart
+// from the usernames known users are counted under.
+func otpGuardKey(channel string, recipient string) string {
+	return "otp:" + channel + ":" + recipient
+}
+
+// errInvalidOTPCode is returned for a wrong, expired or used code and an unknown recipient alike
+func errInvalidOTPCode() error {
+	return cerr.NewCustomErrorWithCodeAndOrigin("Invalid or expired code", cerr.InvalidRequestErrorCode, nil)
+}
+
+// newOTPCode returns a random numeric code of the given length
+func newOTPCode(length int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(length)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", length, n), nil
+}
+
+// otpLength is OTP_LENGTH kept within the supported bounds
+func otpLength() int {
+	length := env.EnvConfig.OTPLength
+	if length < minOTPLength {
+		return minOTPLength
+	}
+	if length > maxOTPLength {
+		return maxOTPLength
+	}
+	return length
+}
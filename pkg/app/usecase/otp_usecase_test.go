@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/notifier"
+)
+
+func TestFindOTPUserRequiresVerifiedEmail(t *testing.T) {
+	email := "alice@example.com"
+	verifiedAt := time.Now()
+
+	tests := []struct {
+		name            string
+		emailVerifiedAt *time.Time
+		wantFound       bool
+	}{
+		{name: "verified email", emailVerifiedAt: &verifiedAt, wantFound: true},
+		{name: "unverified email", emailVerifiedAt: nil, wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &models.User{UUID: uuid.Must(uuid.NewV4()), UserName: "alice", Email: &email, EmailVerifiedAt: tt.emailVerifiedAt}
+			o := &otpUsecase{userRepository: &fakeUserRepository{user: user}}
+
+			found, err := o.findOTPUser(context.Background(), notifier.ChannelEmail, email)
+			if tt.wantFound && (err != nil || found != user) {
+				t.Errorf("findOTPUser = %v, %v, want the user", found, err)
+			}
+			if !tt.wantFound && err == nil {
+				t.Errorf("findOTPUser = %v, want an error", found)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/notifier"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/password"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/tenant"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type passwordUsecase struct {
+	userRepository         models.UserRepository
+	oneTimeTokenRepository models.OneTimeTokenRepository
+	notifier               notifier.Notifier
+	tokens                 *tokenIssuer
+	loginGuard             *loginGuard
+}
+
+func NewPasswordUsecase(userRepository models.UserRepository, refreshTokenRepository models.RefreshTokenRepository, revocationRepository models.RevocationRepository, sessionRepository models.SessionRepository, loginAttemptRepository models.LoginAttemptRepository, oneTimeTokenRepository models.OneTimeTokenRepository, notifier notifier.Notifier) domain.PasswordUsecase {
+	return &passwordUsecase{
+		userRepository:         userRepository,
+		oneTimeTokenRepository: oneTimeTokenRepository,
+		notifier:               notifier,
+		loginGuard:             &loginGuard{loginAttemptRepository: loginAttemptRepository},
+		tokens: &tokenIssuer{
+			userRepository:         userRepository,
+			refreshTokenRepository: refreshTokenRepository,
+			revocationRepository:   revocationRepository,
+			sessionRepository:      sessionRepository,
+		},
+	}
+}
+
+// ChangePassword sets a new password for the user after checking the current one. Wrong current passwords
+// count towards the login lockout of the username and the client IP, so it cannot be used to guess passwords.
+func (p *passwordUsecase) ChangePassword(ctx context.Context, changePasswordRequest *domain.ChangePasswordRequest) error {
+	// Call the repository
+	user, err := p.userRepository.GetUserByUserID(ctx, changePasswordRequest.UserID)
+	if err != nil {
+		log.Println("[PasswordUsecase][ChangePassword] Error in GetUserByUserID: ", err)
+		return err
+	}
+
+	if err := p.loginGuard.check(ctx, user.UserName, changePasswordRequest.ClientIP); err != nil {
+		log.Println("[PasswordUsecase][ChangePassword] Error in check: ", err)
+		return err
+	}
+
+	// The current password is asked for so a stolen token alone cannot take over the account
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(changePasswordRequest.CurrentPassword)); err != nil {
+		return p.loginGuard.fail(ctx, user.UserName, changePasswordRequest.ClientIP, cerr.NewCustomErrorWithCodeAndOrigin("Current password is incorrect", cerr.InvalidRequestErrorCode, err))
+	}
+
+	if err := p.setPassword(ctx, user, changePasswordRequest.NewPassword); err != nil {
+		log.Println("[PasswordUsecase][ChangePassword] Error in setPassword: ", err)
+		return err
+	}
+
+	// The session the password was changed from stays signed in, every other session is ended
+	if err := p.tokens.endUserSessions(ctx, user.UUID.String(), changePasswordRequest.SessionID, models.RefreshTokenRevokedPasswordChanged); err != nil {
+		log.Println("[PasswordUsecase][ChangePassword] Error in endUserSessions: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// RequestPasswordReset sends a reset token to the email address or phone number of the user. The token is sent
+// in the background, so the response is the same and as fast whether or not the user exists, has an address or
+// the sending fails, and the endpoint cannot be used to find out who has an account.
+func (p *passwordUsecase) RequestPasswordReset(ctx context.Context, passwordResetRequest *domain.PasswordResetRequest) error {
+	// Remove the space from the username
+	userName := html.EscapeString(strings.TrimSpace(passwordResetRequest.UserName))
+
+	// The request context ends with the response, the background work only keeps its tenant
+	go p.sendPasswordReset(tenant.NewContext(context.Background(), tenant.ID(ctx)), userName)
+
+	return nil
+}
+
+// sendPasswordReset sends a new reset token to the user, at most one per PASSWORD_RESET_RESEND_INTERVAL_SECONDS
+func (p *passwordUsecase) sendPasswordReset(ctx context.Context, userName string) {
+	// Call the repository
+	user, err := p.userRepository.GetUserByUserName(ctx, userName)
+	if err != nil {
+		if cerr.GetErrorCode(err) != cerr.InvalidRequestErrorCode {
+			log.Println("[PasswordUsecase][sendPasswordReset] Error in GetUserByUserName: ", err)
+		}
+		return
+	}
+
+	// An address that was never verified may belong to someone else, the phone number is used instead then
+	channel, recipient := notifier.ChannelEmail, utils.StringValue(user.Email)
+	if user.EmailVerifiedAt == nil {
+		recipient = ""
+	}
+	if recipient == "" {
+		channel, recipient = notifier.ChannelSMS, utils.StringValue(user.PhoneNumber)
+	}
+	if recipient == "" {
+		log.Println("[PasswordUsecase][sendPasswordReset] User has no verified email or phone number: ", user.UUID)
+		return
+	}
+
+	latest, err := p.oneTimeTokenRepository.GetLatestOneTimeToken(ctx, models.OneTimeTokenPasswordReset, user.UUID.String())
+	if err != nil {
+		log.Println("[PasswordUsecase][sendPasswordReset] Error in GetLatestOneTimeToken: ", err)
+		return
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < time.Duration(env.EnvConfig.ResetResendIntervalSeconds)*time.Second {
+		log.Println("[PasswordUsecase][sendPasswordReset] Reset requested again too soon: ", user.UUID)
+		return
+	}
+
+	token, err := issueOneTimeToken(ctx, p.oneTimeTokenRepository, user.UUID, models.OneTimeTokenPasswordReset, time.Duration(env.EnvConfig.PasswordResetTokenExpirationTime)*time.Minute)
+	if err != nil {
+		log.Println("[PasswordUsecase][sendPasswordReset] Error in issueOneTimeToken: ", err)
+		return
+	}
+
+	message := &notifier.Message{
+		Channel: channel,
+		To:      recipient,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf("Use %s to reset your password, it expires in %d minutes.", passwordResetLink(token), env.EnvConfig.PasswordResetTokenExpirationTime),
+	}
+	if err := p.notifier.Notify(ctx, message); err != nil {
+		log.Println("[PasswordUsecase][sendPasswordReset] Error in Notify: ", err)
+	}
+}
+
+// ResetPassword redeems a reset token, sets the new password and ends every session of the user. The token is
+// only used up once the new password passed the policy, so a rejected password can be retried with it.
+func (p *passwordUsecase) ResetPassword(ctx context.Context, confirmPasswordResetRequest *domain.ConfirmPasswordResetRequest) error {
+	// Only the hash of a reset token is ever stored
+	tokenHash := utils.HashToken(confirmPasswordResetRequest.Token)
+	token, err := p.oneTimeTokenRepository.GetOneTimeToken(ctx, models.OneTimeTokenPasswordReset, tokenHash)
+	if err != nil {
+		log.Println("[PasswordUsecase][ResetPassword] Error in GetOneTimeToken: ", err)
+		return err
+	}
+
+	user, err := p.userRepository.GetUserByUserID(ctx, token.UserUUID.String())
+	if err != nil {
+		log.Println("[PasswordUsecase][ResetPassword] Error in GetUserByUserID: ", err)
+		return err
+	}
+
+	// Check the password against the password policy
+	if err := password.Validate(confirmPasswordResetRequest.NewPassword, user.UserName); err != nil {
+		return err
+	}
+
+	if _, err := p.oneTimeTokenRepository.UseOneTimeToken(ctx, models.OneTimeTokenPasswordReset, tokenHash); err != nil {
+		log.Println("[PasswordUsecase][ResetPassword] Error in UseOneTimeToken: ", err)
+		return err
+	}
+
+	if err := p.setPassword(ctx, user, confirmPasswordResetRequest.NewPassword); err != nil {
+		log.Println("[PasswordUsecase][ResetPassword] Error in setPassword: ", err)
+		return err
+	}
+
+	// Whoever knew the old password must not stay signed in
+	if err := p.tokens.endUserSessions(ctx, user.UUID.String(), "", models.RefreshTokenRevokedPasswordReset); err != nil {
+		log.Println("[PasswordUsecase][ResetPassword] Error in endUserSessions: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// setPassword checks the password against the password policy, hashes it and stores it for the user
+func (p *passwordUsecase) setPassword(ctx context.Context, user *models.User, newPassword string) error {
+	if err := password.Validate(newPassword, user.UserName); err != nil {
+		return err
+	}
+
+	// Encrypt the password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	// Call the repository
+	return p.userRepository.ChangePassword(ctx, user, string(hashedPassword))
+}
+
+// passwordResetLink is the link to the reset form of PASSWORD_RESET_URL with the token in its query, or the
+// token alone when no form is configured
+func passwordResetLink(token string) string {
+	if env.EnvConfig.PasswordResetURL == "" {
+		return token
+	}
+
+	resetURL, err := url.Parse(env.EnvConfig.PasswordResetURL)
+	if err != nil {
+		return token
+	}
+	query := resetURL.Query()
+	query.Set("token", token)
+	resetURL.RawQuery = query.Encode()
+	return resetURL.String()
+}
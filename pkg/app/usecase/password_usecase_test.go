@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/notifier"
+)
+
+func TestSendPasswordResetResendInterval(t *testing.T) {
+	previous := env.EnvConfig
+	t.Cleanup(func() { env.EnvConfig = previous })
+	env.EnvConfig.ResetResendIntervalSeconds = 60
+	env.EnvConfig.PasswordResetTokenExpirationTime = 30
+
+	email := "alice@example.com"
+	verifiedAt := time.Now()
+	user := &models.User{UUID: uuid.Must(uuid.NewV4()), UserName: "alice", Email: &email, EmailVerifiedAt: &verifiedAt}
+	oneTimeTokenRepository := &fakeOneTimeTokenRepository{}
+	messages := &fakeNotifier{}
+	p := &passwordUsecase{userRepository: &fakeUserRepository{user: user}, oneTimeTokenRepository: oneTimeTokenRepository, notifier: messages}
+
+	p.sendPasswordReset(context.Background(), "alice")
+	if len(messages.messages) != 1 || messages.messages[0].To != email {
+		t.Fatalf("first reset sent %+v, want one message to %s", messages.messages, email)
+	}
+
+	p.sendPasswordReset(context.Background(), "alice")
+	if len(messages.messages) != 1 || len(oneTimeTokenRepository.tokens) != 1 {
+		t.Errorf("reset within the resend interval sent %d messages and issued %d tokens, want 1 and 1", len(messages.messages), len(oneTimeTokenRepository.tokens))
+	}
+
+	// Once the interval passed a new token is sent
+	oneTimeTokenRepository.tokens[0].CreatedAt = time.Now().Add(-61 * time.Second)
+	p.sendPasswordReset(context.Background(), "alice")
+	if len(messages.messages) != 2 {
+		t.Errorf("reset after the resend interval sent %d messages in total, want 2", len(messages.messages))
+	}
+
+	p.sendPasswordReset(context.Background(), "bob")
+	if len(messages.messages) != 2 {
+		t.Errorf("reset of an unknown user sent a message")
+	}
+}
+
+func TestRequestPasswordResetAlwaysSucceeds(t *testing.T) {
+	p := &passwordUsecase{userRepository: &fakeUserRepository{}, oneTimeTokenRepository: &fakeOneTimeTokenRepository{}, notifier: &fakeNotifier{}}
+
+	if err := p.RequestPasswordReset(context.Background(), &domain.PasswordResetRequest{UserName: "nobody"}); err != nil {
+		t.Errorf("RequestPasswordReset of an unknown user = %v, want nil", err)
+	}
+}
+
+func TestSendPasswordResetSkipsUnverifiedEmail(t *testing.T) {
+	previous := env.EnvConfig
+	t.Cleanup(func() { env.EnvConfig = previous })
+	env.EnvConfig.ResetResendIntervalSeconds = 60
+
+	email, phoneNumber := "new@example.com", "+27831234567"
+	tests := []struct {
+		name        string
+		phoneNumber *string
+		wantChannel string
+		wantTo      string
+	}{
+		{name: "falls back to the phone number", phoneNumber: &phoneNumber, wantChannel: notifier.ChannelSMS, wantTo: phoneNumber},
+		{name: "sends nothing without a phone number", phoneNumber: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &models.User{UUID: uuid.Must(uuid.NewV4()), UserName: "alice", Email: &email, PhoneNumber: tt.phoneNumber}
+			messages := &fakeNotifier{}
+			p := &passwordUsecase{userRepository: &fakeUserRepository{user: user}, oneTimeTokenRepository: &fakeOneTimeTokenRepository{}, notifier: messages}
+
+			p.sendPasswordReset(context.Background(), "alice")
+			if tt.wantTo == "" {
+				if len(messages.messages) != 0 {
+					t.Errorf("sendPasswordReset sent %+v, want nothing", messages.messages)
+				}
+				return
+			}
+			if len(messages.messages) != 1 || messages.messages[0].Channel != tt.wantChannel || messages.messages[0].To != tt.wantTo {
+				t.Errorf("sendPasswordReset sent %+v, want one %s message to %s", messages.messages, tt.wantChannel, tt.wantTo)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/utils"
+)
+
+// personalAccessTokenPrefixLength is how much of a token is kept in the clear to tell tokens apart
+const personalAccessTokenPrefixLength = 12
+
+// personalAccessTokenTouchInterval is how often the last use of a token is recorded at most
+const personalAccessTokenTouchInterval = time.Minute
+
+type personalAccessTokenUsecase struct {
+	userRepository                models.UserRepository
+	personalAccessTokenRepository models.PersonalAccessTokenRepository
+	authorizer                    domain.Authorizer
+}
+
+func NewPersonalAccessTokenUsecase(userRepository models.UserRepository, personalAccessTokenRepository models.PersonalAccessTokenRepository, authorizer domain.Authorizer) domain.PersonalAccessTokenUsecase {
+	return &personalAccessTokenUsecase{
+		userRepository:                userRepository,
+		personalAccessTokenRepository: personalAccessTokenRepository,
+		authorizer:                    authorizer,
+	}
+}
+
+// CreatePersonalAccessToken creates a token for the requester limited to the scopes, the requester must hold
+// every scope but profile:write as a permission. Only the hash of the token is stored, it cannot be shown again.
+func (p *personalAccessTokenUsecase) CreatePersonalAccessToken(ctx context.Context, createPersonalAccessTokenRequest *domain.CreatePersonalAccessTokenRequest) (*domain.CreatePersonalAccessTokenResponse, error) {
+	requester := createPersonalAccessTokenRequest.Requester
+
+	// A leaked token must not be able to create tokens outliving it
+	if requester.PersonalAccessTokenID != "" {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Personal access tokens cannot create personal access tokens", cerr.ForbiddenErrorCode, nil)
+	}
+
+	name := strings.TrimSpace(createPersonalAccessTokenRequest.Name)
+	if name == "" {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid token name", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	scopes := uniqueStrings(createPersonalAccessTokenRequest.Scopes)
+	for _, scope := range scopes {
+		// The account of the user is theirs to change, a delegated requester must hold the scope itself
+		if scope == domain.ScopeProfileWrite {
+			if !requester.CanManageSelf() {
+				return nil, cerr.NewCustomErrorWithCodeAndOrigin(fmt.Sprintf("Scope %s is not a scope of the token", scope), cerr.InvalidRequestErrorCode, nil)
+			}
+			continue
+		}
+		allowed, err := p.authorizer.HasPermission(ctx, requester, scope)
+		if err != nil {
+			log.Println("[PersonalAccessTokenUsecase][CreatePersonalAccessToken] Error in HasPermission: ", err)
+			return nil, err
+		}
+		if !allowed {
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin(fmt.Sprintf("Scope %s is not a permission of the user", scope), cerr.InvalidRequestErrorCode, nil)
+		}
+	}
+
+	userID, err := uuid.FromString(requester.UserID)
+	if err != nil {
+		return nil, errUserNotFound()
+	}
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		log.Println("[PersonalAccessTokenUsecase][CreatePersonalAccessToken] Error in GenerateRandomToken: ", err)
+		return nil, err
+	}
+	token := models.PersonalAccessTokenPrefix + secret
+
+	personalAccessToken := &models.PersonalAccessToken{
+		UserUUID:    userID,
+		Name:        name,
+		TokenPrefix: token[:personalAccessTokenPrefixLength],
+		TokenHash:   utils.HashToken(token),
+		Scopes:      strings.Join(scopes, " "),
+	}
+	if days := createPersonalAccessTokenRequest.ExpiresInDays; days > 0 {
+		expiresAt := time.Now().AddDate(0, 0, days)
+		personalAccessToken.ExpiresAt = &expiresAt
+	}
+
+	// Call the repository
+	if err := p.personalAccessTokenRepository.CreatePersonalAccessToken(ctx, personalAccessToken); err != nil {
+		log.Println("[PersonalAccessTokenUsecase][CreatePersonalAccessToken] Error in CreatePersonalAccessToken: ", err)
+		return nil, err
+	}
+
+	return &domain.CreatePersonalAccessTokenResponse{
+		Token:                       token,
+		PersonalAccessTokenResponse: *toPersonalAccessTokenResponse(personalAccessToken),
+	}, nil
+}
+
+// GetPersonalAccessTokens lists the tokens of the user that have not been revoked, expired ones included
+func (p *personalAccessTokenUsecase) GetPersonalAccessTokens(ctx context.Context, getPersonalAccessTokensRequest *domain.GetPersonalAccessTokensRequest) (*domain.GetPersonalAccessTokensResponse, error) {
+	// Call the repository
+	tokens, err := p.personalAccessTokenRepository.GetPersonalAccessTokens(ctx, getPersonalAccessTokensRequest.UserID)
+	if err != nil {
+		log.Println("[PersonalAccessTokenUsecase][GetPersonalAccessTokens] Error in GetPersonalAccessTokens: ", err)
+		return nil, err
+	}
+
+	response := &domain.GetPersonalAccessTokensResponse{Tokens: make([]domain.PersonalAccessTokenResponse, 0, len(tokens))}
+	for i := range tokens {
+		response.Tokens = append(response.Tokens, *toPersonalAccessTokenResponse(&tokens[i]))
+	}
+
+	return response, nil
+}
+
+func (p *personalAccessTokenUsecase) RevokePersonalAccessToken(ctx context.Context, revokePersonalAccessTokenRequest *domain.RevokePersonalAccessTokenRequest) error {
+	tokenID := strings.TrimSpace(revokePersonalAccessTokenRequest.TokenID)
+	if _, err := uuid.FromString(tokenID); err != nil {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Token not found", cerr.NotFoundErrorCode, err)
+	}
+
+	// Call the repository
+	if err := p.personalAccessTokenRepository.RevokePersonalAccessToken(ctx, revokePersonalAccessTokenRequest.UserID, tokenID); err != nil {
+		log.Println("[PersonalAccessTokenUsecase][RevokePersonalAccessToken] Error in RevokePersonalAccessToken: ", err)
+		return err
+	}
+
+	return nil
+}
+
+// AuthenticatePersonalAccessToken identifies the user of a token that is neither revoked nor expired. The user
+// must still be allowed to log in, so suspending a user stops their tokens as well.
+func (p *personalAccessTokenUsecase) AuthenticatePersonalAccessToken(ctx context.Context, token string) (*domain.Principal, error) {
+	// Only the hash of a token is ever stored
+	personalAccessToken, err := p.personalAccessTokenRepository.GetPersonalAccessTokenByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if personalAccessToken.RevokedAt != nil {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Token has been revoked", cerr.InvalidRequestErrorCode, nil)
+	}
+	if personalAccessToken.ExpiresAt != nil && time.Now().After(*personalAccessToken.ExpiresAt) {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Token has expired", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	user, err := p.userRepository.GetUserByUserID(ctx, personalAccessToken.UserUUID.String())
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	// A failure to record the use does not reject the request
+	if err := p.personalAccessTokenRepository.TouchPersonalAccessToken(ctx, personalAccessToken, time.Now().Add(-personalAccessTokenTouchInterval)); err != nil {
+		log.Println("[PersonalAccessTokenUsecase][AuthenticatePersonalAccessToken] Error in TouchPersonalAccessToken: ", err)
+	}
+
+	return &domain.Principal{
+		UserID:                user.UUID.String(),
+		TenantID:              personalAccessToken.TenantID,
+		Scopes:                strings.Fields(personalAccessToken.Scopes),
+		PersonalAccessTokenID: personalAccessToken.UUID.String(),
+	}, nil
+}
+
+func toPersonalAccessTokenResponse(token *models.PersonalAccessToken) *domain.PersonalAccessTokenResponse {
+	response := &domain.PersonalAccessTokenResponse{
+		ID:        token.UUID.String(),
+		Name:      token.Name,
+		Prefix:    token.TokenPrefix,
+		Scopes:    strings.Fields(token.Scopes),
+		CreatedAt: token.CreatedAt.String(),
+	}
+	if token.ExpiresAt != nil {
+		response.ExpiresAt = token.ExpiresAt.String()
+	}
+	if token.LastUsedAt != nil {
+		response.LastUsedAt = token.LastUsedAt.String()
+	}
+
+	return response
+}
@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"context"
+	"html"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+)
+
+// namePattern keeps role and group names usable as path segments and claim values
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+type roleUsecase struct {
+	roleRepository models.RoleRepository
+	userRepository models.UserRepository
+}
+
+func NewRoleUsecase(roleRepository models.RoleRepository, userRepository models.UserRepository) domain.RoleUsecase {
+	return &roleUsecase{
+		roleRepository: roleRepository,
+		userRepository: userRepository,
+	}
+}
+
+func (r *roleUsecase) CreateRole(ctx context.Context, createRoleRequest *domain.CreateRoleRequest) (*domain.RoleResponse, error) {
+	name := strings.ToLower(strings.TrimSpace(createRoleRequest.Name))
+	if !namePattern.MatchString(name) {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid role name", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	role := &models.Role{
+		Name:        name,
+		Description: strings.TrimSpace(createRoleRequest.Description),
+	}
+
+	// Call the repository
+	if err := r.roleRepository.CreateRole(ctx, role, uniqueStrings(createRoleRequest.Permissions)); err != nil {
+		log.Println("[RoleUsecase][CreateRole] Error in CreateRole: ", err)
+		return nil, err
+	}
+
+	return toRoleResponse(role), nil
+}
+
+func (r *roleUsecase) GetRoles(ctx context.Context) (*domain.GetRolesResponse, error) {
+	// Call the repository
+	roles, err := r.roleRepository.GetRoles(ctx)
+	if err != nil {
+		log.Println("[RoleUsecase][GetRoles] Error in GetRoles: ", err)
+		return nil, err
+	}
+
+	response := &domain.GetRolesResponse{Roles: make([]domain.RoleResponse, 0, len(roles))}
+	for i := range roles {
+		response.Roles = append(response.Roles, *toRoleResponse(&roles[i]))
+	}
+
+	return response, nil
+}
+
+func (r *roleUsecase) GrantRole(ctx context.Context, roleMemberRequest *domain.RoleMemberRequest) error {
+	role, user, err := r.roleMember(ctx, roleMemberRequest)
+	if err != nil {
+		log.Println("[RoleUsecase][GrantRole] Error in roleMember: ", err)
+		return err
+	}
+
+	// Call the repository
+	if err := r.roleRepository.GrantRole(ctx, user.UUID, role); err != nil {
+		log.Println("[RoleUsecase][GrantRole] Error in GrantRole: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *roleUsecase) RevokeRole(ctx context.Context, roleMemberRequest *domain.RoleMemberRequest) error {
+	role, user, err := r.roleMember(ctx, roleMemberRequest)
+	if err != nil {
+		log.Println("[RoleUsecase][RevokeRole] Error in roleMember: ", err)
+		return err
+	}
+
+	// Call the repository
+	if err := r.roleRepository.RevokeRole(ctx, user.UUID, role); err != nil {
+		log.Println("[RoleUsecase][RevokeRole] Error in RevokeRole: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *roleUsecase) GetRoleMembers(ctx context.Context, getRoleMembersRequest *domain.GetRoleMembersRequest) (*domain.GetRoleMembersResponse, error) {
+	role, err := r.roleRepository.GetRoleByName(ctx, getRoleMembersRequest.RoleName)
+	if err != nil {
+		log.Println("[RoleUsecase][GetRoleMembers] Error in GetRoleByName: ", err)
+		return nil, err
+	}
+
+	// Call the repository
+	users, err := r.roleRepository.GetRoleMembers(ctx, role)
+	if err != nil {
+		log.Println("[RoleUsecase][GetRoleMembers] Error in GetRoleMembers: ", err)
+		return nil, err
+	}
+
+	response := &domain.GetRoleMembersResponse{Members: make([]domain.RoleMemberResponse, 0, len(users))}
+	for _, user := range users {
+		response.Members = append(response.Members, domain.RoleMemberResponse{
+			ID:       user.UUID.String(),
+			UserName: user.UserName,
+		})
+	}
+
+	return response, nil
+}
+
+// roleMember looks up the role and the user of a grant or revoke request
+func (r *roleUsecase) roleMember(ctx context.Context, roleMemberRequest *domain.RoleMemberRequest) (*models.Role, *models.User, error) {
+	role, err := r.roleRepository.GetRoleByName(ctx, roleMemberRequest.RoleName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Remove the space from the username
+	userName := html.EscapeString(strings.TrimSpace(roleMemberRequest.UserName))
+	user, err := r.userRepository.GetUserByUserName(ctx, userName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return role, user, nil
+}
+
+func toRoleResponse(role *models.Role) *domain.RoleResponse {
+	response := &domain.RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: make([]string, 0, len(role.Permissions)),
+		CreatedAt:   role.CreatedAt.String(),
+	}
+	for _, permission := range role.Permissions {
+		response.Permissions = append(response.Permissions, permission.Name)
+	}
+	return response
+}
+
+// uniqueStrings drops empty and repeated values, keeping the order of the rest
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/jwt"
+)
+
+type signingKeyUsecase struct{}
+
+func NewSigningKeyUsecase() domain.SigningKeyUsecase {
+	return &signingKeyUsecase{}
+}
+
+func (s *signingKeyUsecase) GetSigningKeys(ctx context.Context) (*domain.GetSigningKeysResponse, error) {
+	keys := jwt.GetSigningKeys()
+
+	response := &domain.GetSigningKeysResponse{Keys: make([]domain.SigningKeyResponse, 0, len(keys))}
+	for i := range keys {
+		if keys[i].Status == jwt.KeyStatusActive && response.ActiveKeyID == "" {
+			response.ActiveKeyID = keys[i].ID
+		}
+		response.Keys = append(response.Keys, *toSigningKeyResponse(&keys[i]))
+	}
+
+	return response, nil
+}
+
+func (s *signingKeyUsecase) RotateSigningKey(ctx context.Context, rotateSigningKeyRequest *domain.RotateSigningKeyRequest) (*domain.SigningKeyResponse, error) {
+	grace, err := gracePeriod(rotateSigningKeyRequest.GracePeriod)
+	if err != nil {
+		log.Println("[SigningKeyUsecase][RotateSigningKey] Error in gracePeriod: ", err)
+		return nil, err
+	}
+
+	key, err := jwt.RotateSigningKey(ctx, grace)
+	if err != nil {
+		log.Println("[SigningKeyUsecase][RotateSigningKey] Error in RotateSigningKey: ", err)
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin(err.Error(), cerr.InvalidRequestErrorCode, err)
+	}
+
+	return toSigningKeyResponse(key), nil
+}
+
+func (s *signingKeyUsecase) RetireSigningKey(ctx context.Context, retireSigningKeyRequest *domain.RetireSigningKeyRequest) (*domain.SigningKeyResponse, error) {
+	grace, err := gracePeriod(retireSigningKeyRequest.GracePeriod)
+	if err != nil {
+		log.Println("[SigningKeyUsecase][RetireSigningKey] Error in gracePeriod: ", err)
+		return nil, err
+	}
+
+	key, err := jwt.RetireSigningKey(ctx, retireSigningKeyRequest.KeyID, grace)
+	if err != nil {
+		log.Println("[SigningKeyUsecase][RetireSigningKey] Error in RetireSigningKey: ", err)
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin(err.Error(), cerr.InvalidRequestErrorCode, err)
+	}
+
+	return toSigningKeyResponse(key), nil
+}
+
+// gracePeriod converts a requested grace period in minutes, falling back to the configured one
+func gracePeriod(minutes int) (time.Duration, error) {
+	if minutes > 0 {
+		return time.Duration(minutes) * time.Minute, nil
+	}
+	return jwt.KeyGracePeriod()
+}
+
+func toSigningKeyResponse(key *jwt.SigningKey) *domain.SigningKeyResponse {
+	response := &domain.SigningKeyResponse{
+		ID:        key.ID,
+		Algorithm: key.Algorithm,
+		Status:    key.Status,
+		CreatedAt: key.CreatedAt.String(),
+	}
+	if key.RetiredAt != nil {
+		response.RetiredAt = key.RetiredAt.String()
+	}
+	if key.ExpiresAt != nil {
+		response.ExpiresAt = key.ExpiresAt.String()
+	}
+	return response
+}
@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/jwt"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/utils"
+)
+
+// tokenIssuer starts and ends sessions and issues the tokens for them, it is shared by every flow that
+// authenticates a user so the password login and the OAuth grants hand out the same tokens
+type tokenIssuer struct {
+	userRepository         models.UserRepository
+	refreshTokenRepository models.RefreshTokenRepository
+	revocationRepository   models.RevocationRepository
+	sessionRepository      models.SessionRepository
+	claimsBuilder          domain.ClaimsBuilder
+}
+
+// tokenGrant describes who the tokens are issued to, an empty ClientID stands for the password login
+type tokenGrant struct {
+	ClientID string
+	Scopes   []string
+	Nonce    string
+}
+
+// issuedTokens holds the tokens handed out for a session, IDToken is empty when none was asked for
+type issuedTokens struct {
+	AccessToken  string
+	RefreshToken string
+	IDToken      string
+	Scopes       []string
+}
+
+// startSession records a new session for the user, its id becomes the sid claim and the refresh token family.
+// authMethods are the methods the user authenticated with, they become the amr claim.
+func (t *tokenIssuer) startSession(ctx context.Context, user *models.User, userAgent string, clientIP string, authMethods []string) (*models.Session, error) {
+	session := &models.Session{
+		UserUUID:    user.UUID,
+		UserAgent:   userAgent,
+		ClientIP:    clientIP,
+		AuthMethods: strings.Join(authMethods, " "),
+		LastSeenAt:  time.Now(),
+	}
+	if err := t.sessionRepository.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// issueTokens issues an access token, a refresh token and, unless an OAuth client left out the openid
+// scope, an ID token for the session. Users who may not log in get no tokens.
+func (t *tokenIssuer) issueTokens(ctx context.Context, user *models.User, session *models.Session, grant *tokenGrant) (*issuedTokens, error) {
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshTokenModel, err := newRefreshToken(user.UUID, session.UUID, grant)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.refreshTokenRepository.CreateRefreshToken(ctx, refreshTokenModel); err != nil {
+		return nil, err
+	}
+
+	return t.signTokens(ctx, user, session, grant, refreshToken)
+}
+
+// refreshTokens rotates the refresh token and issues new tokens for its session, clientID must be the client
+// the refresh token was issued to
+func (t *tokenIssuer) refreshTokens(ctx context.Context, refreshToken string, clientID string) (*issuedTokens, error) {
+	// Only the hash of a refresh token is ever stored
+	current, err := t.refreshTokenRepository.GetRefreshTokenByHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	// A refresh token can only be redeemed by the client it was issued to
+	if current.ClientID != clientID {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Refresh token was issued to another client", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	// A rotated token being presented again means it has leaked, so the whole family is revoked
+	if current.RevokedAt != nil {
+		if current.RevokedReason == models.RefreshTokenRevokedRotated {
+			if err := t.endSession(ctx, current.FamilyID.String(), models.RefreshTokenRevokedReuseDetected); err != nil {
+				return nil, err
+			}
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("Refresh token reuse detected", cerr.InvalidRequestErrorCode, nil)
+		}
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Refresh token has been revoked", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Refresh token has expired", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	// The refresh token family is the session, it must not have been ended
+	session, err := t.sessionRepository.GetSessionByID(ctx, current.FamilyID.String())
+	if err != nil {
+		return nil, err
+	}
+	if session.EndedAt != nil {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Session has ended", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	user, err := t.userRepository.GetUserByUserID(ctx, current.UserUUID.String())
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	// The new tokens carry the same client and scopes as the original grant
+	grant := &tokenGrant{ClientID: current.ClientID, Scopes: strings.Fields(current.Scope)}
+	nextRefreshToken, next, err := newRefreshToken(user.UUID, current.FamilyID, grant)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rotate the token, losing the race against a concurrent refresh is treated as reuse
+	if err := t.refreshTokenRepository.RotateRefreshToken(ctx, current, next); err != nil {
+		if cerr.GetErrorCode(err) == cerr.InvalidRequestErrorCode {
+			if err := t.endSession(ctx, current.FamilyID.String(), models.RefreshTokenRevokedReuseDetected); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	if err := t.sessionRepository.TouchSession(ctx, session.UUID.String()); err != nil {
+		return nil, err
+	}
+
+	return t.signTokens(ctx, user, session, grant, nextRefreshToken)
+}
+
+// endSession marks the session as ended, revokes its refresh tokens and puts its sid on the revocation list
+// so that access tokens already issued for it are rejected as well
+func (t *tokenIssuer) endSession(ctx context.Context, sid string, reason string) error {
+	// Any token of the session was issued before now, so it expires within one token lifetime
+	tokenLifetime, err := jwt.TokenLifetime()
+	if err != nil {
+		return err
+	}
+
+	if err := t.revocationRepository.RevokeToken(ctx, models.RevocationKindSID, sid, time.Now().Add(tokenLifetime)); err != nil {
+		return err
+	}
+
+	if err := t.sessionRepository.EndSession(ctx, sid); err != nil {
+		return err
+	}
+
+	familyID, err := uuid.FromString(sid)
+	if err != nil {
+		return err
+	}
+
+	return t.refreshTokenRepository.RevokeRefreshTokenFamily(ctx, familyID, reason)
+}
+
+// endUserSessions ends every active session of the user except keepSessionID, which may be empty
+func (t *tokenIssuer) endUserSessions(ctx context.Context, userID string, keepSessionID string, reason string) error {
+	sessions, err := t.sessionRepository.GetActiveSessionsByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.UUID.String() == keepSessionID {
+			continue
+		}
+		if err := t.endSession(ctx, session.UUID.String(), reason); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// signTokens signs the access token and the ID token for the session
+func (t *tokenIssuer) signTokens(ctx context.Context, user *models.User, session *models.Session, grant *tokenGrant, refreshToken string) (*issuedTokens, error) {
+	claims, err := t.claimsBuilder.Build(ctx, user, grant.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refreshed tokens keep the time and the methods the user actually authenticated with
+	claims["auth_time"] = session.CreatedAt.Unix()
+	if session.AuthMethods != "" {
+		claims["amr"] = strings.Fields(session.AuthMethods)
+	}
+	if grant.ClientID != "" {
+		claims["client_id"] = grant.ClientID
+	}
+
+	accessToken, err := jwt.GenerateToken(user.UUID.String(), session.UUID.String(), claims)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := &issuedTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		Scopes:       grant.Scopes,
+	}
+
+	// The password login always gets an ID token, OAuth clients only when they asked for the openid scope
+	if grant.ClientID != "" && !utils.Contains(grant.Scopes, "openid") {
+		return tokens, nil
+	}
+
+	idClaims, err := t.claimsBuilder.Build(ctx, user, nil)
+	if err != nil {
+		return nil, err
+	}
+	idClaims["auth_time"] = session.CreatedAt.Unix()
+	if session.AuthMethods != "" {
+		idClaims["amr"] = strings.Fields(session.AuthMethods)
+	}
+
+	audience := grant.ClientID
+	if audience == "" {
+		audience = env.EnvConfig.JWTAudience
+	}
+
+	tokens.IDToken, err = jwt.GenerateIDToken(user.UUID.String(), session.UUID.String(), audience, grant.Nonce, idClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// newRefreshToken creates an opaque refresh token and the model holding its hash, the family id is the session id
+func newRefreshToken(userID uuid.UUID, familyID uuid.UUID, grant *tokenGrant) (string, *models.RefreshToken, error) {
+	refreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return refreshToken, &models.RefreshToken{
+		UserUUID:  userID,
+		FamilyID:  familyID,
+		ClientID:  grant.ClientID,
+		Scope:     strings.Join(grant.Scopes, " "),
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(time.Duration(env.EnvConfig.RefreshTokenExpirationTime) * time.Minute),
+	}, nil
+}
@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/utils"
+)
+
+// fakeRefreshTokenRepository serves the refresh tokens by hash and records the revoked families
+type fakeRefreshTokenRepository struct {
+	tokens          map[string]*models.RefreshToken
+	rotateErr       error
+	rotated         int
+	revokedFamilies map[uuid.UUID]string
+}
+
+func (r *fakeRefreshTokenRepository) CreateRefreshToken(ctx context.Context, refreshToken *models.RefreshToken) error {
+	r.tokens[refreshToken.TokenHash] = refreshToken
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid refresh token", cerr.InvalidRequestErrorCode, nil)
+	}
+	return token, nil
+}
+
+func (r *fakeRefreshTokenRepository) RotateRefreshToken(ctx context.Context, current *models.RefreshToken, next *models.RefreshToken) error {
+	if r.rotateErr != nil {
+		return r.rotateErr
+	}
+	r.rotated++
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID, reason string) error {
+	r.revokedFamilies[familyID] = reason
+	return nil
+}
+
+// fakeSessionRepository keeps the sessions in memory
+type fakeSessionRepository struct {
+	models.SessionRepository
+	sessions map[string]*models.Session
+}
+
+func (r *fakeSessionRepository) GetSessionByID(ctx context.Context, sessionID string) (*models.Session, error) {
+	session, ok := r.sessions[sessionID]
+	if !ok {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Session not found", cerr.NotFoundErrorCode, nil)
+	}
+	return session, nil
+}
+
+func (r *fakeSessionRepository) EndSession(ctx context.Context, sessionID string) error {
+	now := time.Now()
+	r.sessions[sessionID].EndedAt = &now
+	return nil
+}
+
+// fakeRevocationRepository records the revoked values by kind
+type fakeRevocationRepository struct {
+	models.RevocationRepository
+	revoked map[string]time.Time
+}
+
+func (r *fakeRevocationRepository) RevokeToken(ctx context.Context, kind string, value string, expiresAt time.Time) error {
+	r.revoked[kind+":"+value] = expiresAt
+	return nil
+}
+
+func TestRefreshTokensReuseDetection(t *testing.T) {
+	env.EnvConfig.JWTExpirationTime = "15"
+	env.EnvConfig.RefreshTokenExpirationTime = 60
+
+	alreadyUsed := cerr.NewCustomErrorWithCodeAndOrigin("Refresh token has already been used", cerr.InvalidRequestErrorCode, nil)
+
+	tests := []struct {
+		name          string
+		revokedReason string
+		clientID      string
+		rotateErr     error
+		wantErr       string
+		wantReuse     bool
+	}{
+		{name: "rotated token presented again", revokedReason: models.RefreshTokenRevokedRotated, wantErr: "Refresh token reuse detected", wantReuse: true},
+		{name: "concurrent refresh loses the rotation", rotateErr: alreadyUsed, wantErr: "Refresh token has already been used", wantReuse: true},
+		{name: "token of an ended session", revokedReason: models.RefreshTokenRevokedSessionEnded, wantErr: "Refresh token has been revoked"},
+		{name: "token of another client", clientID: "other-client", wantErr: "Refresh token was issued to another client"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &models.User{UUID: uuid.Must(uuid.NewV4()), UserName: "alice", Status: models.UserStatusActive}
+			session := &models.Session{UUID: uuid.Must(uuid.NewV4()), UserUUID: user.UUID}
+			sid := session.UUID.String()
+
+			refreshToken, token, err := newRefreshToken(user.UUID, session.UUID, &tokenGrant{})
+			if err != nil {
+				t.Fatalf("newRefreshToken() error = %v", err)
+			}
+			if tt.revokedReason != "" {
+				revokedAt := time.Now()
+				token.RevokedAt = &revokedAt
+				token.RevokedReason = tt.revokedReason
+			}
+
+			refreshTokens := &fakeRefreshTokenRepository{
+				tokens:          map[string]*models.RefreshToken{utils.HashToken(refreshToken): token},
+				rotateErr:       tt.rotateErr,
+				revokedFamilies: map[uuid.UUID]string{},
+			}
+			sessions := &fakeSessionRepository{sessions: map[string]*models.Session{sid: session}}
+			revocations := &fakeRevocationRepository{revoked: map[string]time.Time{}}
+			issuer := &tokenIssuer{
+				userRepository:         &fakeUserRepository{user: user},
+				refreshTokenRepository: refreshTokens,
+				revocationRepository:   revocations,
+				sessionRepository:      sessions,
+			}
+
+			_, err = issuer.refreshTokens(context.Background(), refreshToken, tt.clientID)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("refreshTokens() error = %v, want %q", err, tt.wantErr)
+			}
+			if refreshTokens.rotated != 0 {
+				t.Errorf("refreshTokens() rotated %d tokens, want none", refreshTokens.rotated)
+			}
+
+			// Reuse ends the session, revokes the whole family and puts the sid on the revocation list
+			reason, familyRevoked := refreshTokens.revokedFamilies[session.UUID]
+			_, sidRevoked := revocations.revoked[models.RevocationKindSID+":"+sid]
+			if !tt.wantReuse {
+				if familyRevoked || sidRevoked || session.EndedAt != nil {
+					t.Errorf("refreshTokens() ended the session, want it left alone")
+				}
+				return
+			}
+			if !familyRevoked || reason != models.RefreshTokenRevokedReuseDetected {
+				t.Errorf("family revoked = %v with reason %q, want revoked with %q", familyRevoked, reason, models.RefreshTokenRevokedReuseDetected)
+			}
+			if !sidRevoked {
+				t.Errorf("sid %s is not on the revocation list", sid)
+			}
+			if session.EndedAt == nil {
+				t.Errorf("session %s has not been ended", sid)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+)
+
+// defaultUserPageSize is the page size of user listings asking for none
+const defaultUserPageSize = 50
+
+// userCursor is the position of a listing behind the last user of a page, it records the listing order so a
+// cursor cannot be replayed against a differently ordered listing
+type userCursor struct {
+	SortBy     string     `json:"s"`
+	Descending bool       `json:"d"`
+	ID         uint       `json:"i"`
+	CreatedAt  *time.Time `json:"c,omitempty"`
+	UserName   string     `json:"u,omitempty"`
+}
+
+func (u *userUsecase) ListUsers(ctx context.Context, listUsersRequest *domain.ListUsersRequest) (*domain.ListUsersResponse, error) {
+	filter, err := userFilter(listUsersRequest)
+	if err != nil {
+		log.Println("[UserUsecase][ListUsers] Error in userFilter: ", err)
+		return nil, err
+	}
+	pageSize := filter.Limit
+
+	// One extra user tells whether there is a next page
+	filter.Limit++
+	users, total, err := u.userRepository.ListUsers(ctx, filter)
+	if err != nil {
+		log.Println("[UserUsecase][ListUsers] Error in ListUsers: ", err)
+		return nil, err
+	}
+
+	response := &domain.ListUsersResponse{
+		Users: make([]domain.GetUserByUserNameResponse, 0, pageSize),
+		Meta:  domain.PageMeta{Total: total, Limit: pageSize},
+	}
+	if len(users) > pageSize {
+		users = users[:pageSize]
+		response.Meta.NextCursor = encodeUserCursor(filter, &users[pageSize-1])
+	}
+	for i := range users {
+		response.Users = append(response.Users, *toUserResponse(&users[i]))
+	}
+
+	return response, nil
+}
+
+// userFilter converts the listing request to the filter of the repository
+func userFilter(listUsersRequest *domain.ListUsersRequest) (*models.UserFilter, error) {
+	filter := &models.UserFilter{
+		Status:         listUsersRequest.Status,
+		Role:           listUsersRequest.Role,
+		UserNamePrefix: listUsersRequest.UserNamePrefix,
+		SortBy:         listUsersRequest.Sort,
+		Descending:     listUsersRequest.Order == "desc",
+		Limit:          listUsersRequest.Limit,
+	}
+	if filter.SortBy == "" {
+		filter.SortBy = models.UserSortCreatedAt
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultUserPageSize
+	}
+
+	var err error
+	if filter.CreatedAfter, err = parseTime(listUsersRequest.CreatedAfter); err != nil {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid created_after", cerr.InvalidRequestErrorCode, err)
+	}
+	if filter.CreatedBefore, err = parseTime(listUsersRequest.CreatedBefore); err != nil {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid created_before", cerr.InvalidRequestErrorCode, err)
+	}
+
+	if listUsersRequest.Cursor != "" {
+		cursor, err := decodeUserCursor(listUsersRequest.Cursor)
+		if err != nil || cursor.SortBy != filter.SortBy || cursor.Descending != filter.Descending {
+			return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid cursor", cerr.InvalidRequestErrorCode, err)
+		}
+		filter.After = &models.User{UserName: cursor.UserName}
+		filter.After.ID = cursor.ID
+		if cursor.CreatedAt != nil {
+			filter.After.CreatedAt = *cursor.CreatedAt
+		}
+	}
+
+	return filter, nil
+}
+
+// parseTime parses an optional RFC 3339 timestamp
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// encodeUserCursor returns the opaque cursor continuing the listing behind the user
+func encodeUserCursor(filter *models.UserFilter, user *models.User) string {
+	cursor := userCursor{SortBy: filter.SortBy, Descending: filter.Descending, ID: user.ID}
+	if filter.SortBy == models.UserSortUserName {
+		cursor.UserName = user.UserName
+	} else {
+		cursor.CreatedAt = &user.CreatedAt
+	}
+
+	// Marshalling a struct of plain fields cannot fail
+	encoded, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func decodeUserCursor(value string) (*userCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor userCursor
+	if err := json.Unmarshal(decoded, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
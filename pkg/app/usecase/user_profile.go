@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"html"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	// The time zone database is embedded so timezones validate on hosts without one
+	_ "time/tzdata"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+)
+
+var (
+	// e164Pattern matches a phone number in E.164 format
+	e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+	// localePattern matches a BCP 47 language tag such as en, en-ZA or zh-Hant-TW
+	localePattern = regexp.MustCompile(`^[A-Za-z]{2,8}(-[A-Za-z0-9]{1,8})*$`)
+	// phoneSeparators are stripped from phone numbers before they are validated
+	phoneSeparators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+)
+
+// applyProfile validates the fields set in the profile and copies them onto the user in their normalized form.
+// Fields left out are kept and empty strings clear them.
+func applyProfile(user *models.User, profile *domain.UserProfile) error {
+	if profile.Email != nil {
+		email, err := normalizeEmail(*profile.Email)
+		if err != nil {
+			return err
+		}
+		user.Email = email
+	}
+
+	if profile.PhoneNumber != nil {
+		phoneNumber, err := normalizePhoneNumber(*profile.PhoneNumber)
+		if err != nil {
+			return err
+		}
+		user.PhoneNumber = phoneNumber
+	}
+
+	if profile.FirstName != nil {
+		user.FirstName = html.EscapeString(strings.TrimSpace(*profile.FirstName))
+	}
+
+	if profile.LastName != nil {
+		user.LastName = html.EscapeString(strings.TrimSpace(*profile.LastName))
+	}
+
+	if profile.DisplayName != nil {
+		user.DisplayName = html.EscapeString(strings.TrimSpace(*profile.DisplayName))
+	}
+
+	if profile.Locale != nil {
+		locale := strings.ReplaceAll(strings.TrimSpace(*profile.Locale), "_", "-")
+		if locale != "" && !localePattern.MatchString(locale) {
+			return cerr.NewCustomErrorWithCodeAndOrigin("Invalid locale", cerr.InvalidRequestErrorCode, nil)
+		}
+		user.Locale = locale
+	}
+
+	if profile.Timezone != nil {
+		timezone := strings.TrimSpace(*profile.Timezone)
+		if timezone != "" {
+			// LoadLocation accepts an empty name and Local as UTC and the host zone, neither is a real zone name
+			if _, err := time.LoadLocation(timezone); err != nil || timezone == "Local" {
+				return cerr.NewCustomErrorWithCodeAndOrigin("Invalid timezone", cerr.InvalidRequestErrorCode, err)
+			}
+		}
+		user.Timezone = timezone
+	}
+
+	if profile.AvatarURL != nil {
+		avatarURL := strings.TrimSpace(*profile.AvatarURL)
+		if avatarURL != "" {
+			parsed, err := url.Parse(avatarURL)
+			if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+				return cerr.NewCustomErrorWithCodeAndOrigin("Invalid avatar URL", cerr.InvalidRequestErrorCode, err)
+			}
+		}
+		user.AvatarURL = avatarURL
+	}
+
+	return nil
+}
+
+// normalizeEmail lower cases the address so that it is unique regardless of case, nil clears it
+func normalizeEmail(email string) (*string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil, nil
+	}
+
+	// Only a bare address is accepted, not a display name with the address in angle brackets
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid email", cerr.InvalidRequestErrorCode, err)
+	}
+
+	email = strings.ToLower(email)
+	return &email, nil
+}
+
+// normalizePhoneNumber strips separators from the number and checks that it is in E.164 format, nil clears it
+func normalizePhoneNumber(phoneNumber string) (*string, error) {
+	phoneNumber = phoneSeparators.Replace(strings.TrimSpace(phoneNumber))
+	if phoneNumber == "" {
+		return nil, nil
+	}
+
+	if !e164Pattern.MatchString(phoneNumber) {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid phone number, it must be in E.164 format", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	return &phoneNumber, nil
+}
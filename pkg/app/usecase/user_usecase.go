@@ -10,29 +10,72 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
 
 	"github.com/satyamvatstyagi/UserManagementService/pkg/app/domain"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/app/models"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/common/jwt"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/notifier"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/password"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/common/restclient"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/common/utils"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type userUsecase struct {
-	userRepository models.UserRepository
-	httpClient     restclient.HTTPClient
+	userRepository         models.UserRepository
+	revocationRepository   models.RevocationRepository
+	sessionRepository      models.SessionRepository
+	mfaRepository          models.MFARepository
+	oneTimeTokenRepository models.OneTimeTokenRepository
+	claimsBuilder          domain.ClaimsBuilder
+	tokens                 *tokenIssuer
+	loginGuard             *loginGuard
+	emailVerifier          *emailVerifier
+	httpClient             restclient.HTTPClient
 }
 
-func NewUserUsecase(userRepository models.UserRepository, hc restclient.HTTPClient) domain.UserUsecase {
+func NewUserUsecase(userRepository models.UserRepository, refreshTokenRepository models.RefreshTokenRepository, revocationRepository models.RevocationRepository, sessionRepository models.SessionRepository, loginAttemptRepository models.LoginAttemptRepository, mfaRepository models.MFARepository, oneTimeTokenRepository models.OneTimeTokenRepository, claimsBuilder domain.ClaimsBuilder, notifier notifier.Notifier, emailVerificationKey []byte, hc restclient.HTTPClient) domain.UserUsecase {
 	return &userUsecase{
-		userRepository: userRepository,
-		httpClient:     hc,
+		userRepository:         userRepository,
+		revocationRepository:   revocationRepository,
+		sessionRepository:      sessionRepository,
+		mfaRepository:          mfaRepository,
+		oneTimeTokenRepository: oneTimeTokenRepository,
+		claimsBuilder:          claimsBuilder,
+		tokens: &tokenIssuer{
+			userRepository:         userRepository,
+			refreshTokenRepository: refreshTokenRepository,
+			revocationRepository:   revocationRepository,
+			sessionRepository:      sessionRepository,
+			claimsBuilder:          claimsBuilder,
+		},
+		loginGuard: &loginGuard{loginAttemptRepository: loginAttemptRepository},
+		emailVerifier: &emailVerifier{
+			userRepository: userRepository,
+			notifier:       notifier,
+			key:            emailVerificationKey,
+		},
+		httpClient: hc,
 	}
 }
 
 func (u *userUsecase) RegisterUser(ctx context.Context, registerUserRequest *domain.RegisterUserRequest) (*domain.RegisterUserResponse, error) {
+	// Remove the space from the username
+	registerUserRequest.UserName = html.EscapeString(strings.TrimSpace(registerUserRequest.UserName))
+	if err := checkReservedUserName(registerUserRequest.UserName); err != nil {
+		return nil, err
+	}
+
+	// Check the password against the password policy
+	if err := password.Validate(registerUserRequest.Password, registerUserRequest.UserName); err != nil {
+		return nil, err
+	}
+
 	// Encrypt the password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(registerUserRequest.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -40,16 +83,33 @@ func (u *userUsecase) RegisterUser(ctx context.Context, registerUserRequest *dom
 		return nil, err
 	}
 
-	// Remove the space from the username
-	registerUserRequest.UserName = html.EscapeString(strings.TrimSpace(registerUserRequest.UserName))
+	user := &models.User{
+		UserName: registerUserRequest.UserName,
+		Password: string(hashedPassword),
+	}
+	if err := applyProfile(user, &registerUserRequest.UserProfile); err != nil {
+		log.Println("[UserUsecase][RegisterUser] Error in applyProfile: ", err)
+		return nil, err
+	}
+
+	// A user with an email address can only log in once they confirmed it
+	user.Status = models.UserStatusActive
+	if user.Email != nil {
+		user.Status = models.UserStatusPendingVerification
+	}
 
 	// Call the repository
-	userID, err := u.userRepository.RegisterUser(ctx, registerUserRequest.UserName, string(hashedPassword))
+	userID, err := u.userRepository.RegisterUser(ctx, user)
 	if err != nil {
 		log.Println("[UserUsecase][RegisterUser] Error in RegisterUser: ", err)
 		return nil, err
 	}
 
+	// The user is registered either way, a link that failed to send can be sent again
+	if err := u.emailVerifier.send(ctx, user); err != nil {
+		log.Println("[UserUsecase][RegisterUser] Error in sending verification email: ", err)
+	}
+
 	return &domain.RegisterUserResponse{
 		UserID: userID,
 	}, nil
@@ -59,31 +119,285 @@ func (u *userUsecase) LoginUser(ctx context.Context, loginUserRequest *domain.Lo
 	// Remove the space from the username
 	loginUserRequest.UserName = html.EscapeString(strings.TrimSpace(loginUserRequest.UserName))
 
+	// Check the username and password
+	user, err := authenticatePassword(ctx, u.userRepository, u.loginGuard, loginUserRequest.UserName, loginUserRequest.Password, loginUserRequest.ClientIP)
+	if err != nil {
+		log.Println("[UserUsecase][LoginUser] Error in authenticatePassword: ", err)
+		return nil, err
+	}
+
+	// A user with MFA enabled gets a challenge instead of the tokens, it is answered at /user/login/mfa
+	factor, err := confirmedMFAFactor(ctx, u.mfaRepository, user.UUID.String())
+	if err != nil {
+		log.Println("[UserUsecase][LoginUser] Error in confirmedMFAFactor: ", err)
+		return nil, err
+	}
+	if factor != nil {
+		mfaToken, err := issueOneTimeToken(ctx, u.oneTimeTokenRepository, user.UUID, models.OneTimeTokenMFAChallenge, time.Duration(env.EnvConfig.MFAChallengeExpirationTime)*time.Minute)
+		if err != nil {
+			log.Println("[UserUsecase][LoginUser] Error in issueOneTimeToken: ", err)
+			return nil, err
+		}
+		return &domain.LoginUserResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
+	}
+
+	if err := u.loginGuard.recordSuccess(ctx, user.UserName); err != nil {
+		log.Println("[UserUsecase][LoginUser] Error in recordSuccess: ", err)
+		return nil, err
+	}
+
+	// Record the session for this login
+	session, err := u.tokens.startSession(ctx, user, loginUserRequest.UserAgent, loginUserRequest.ClientIP, []string{models.AuthMethodPassword})
+	if err != nil {
+		log.Println("[UserUsecase][LoginUser] Error in startSession: ", err)
+		return nil, err
+	}
+
+	// Generate the JWT tokens
+	tokens, err := u.tokens.issueTokens(ctx, user, session, &tokenGrant{})
+	if err != nil {
+		log.Println("[UserUsecase][LoginUser] Error in issueTokens: ", err)
+		return nil, err
+	}
+
+	return &domain.LoginUserResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		IDToken:      tokens.IDToken,
+	}, nil
+}
+
+// LoginMFA completes the login of a user with MFA enabled. Wrong codes count towards the lockout of the
+// username like wrong passwords do, and the challenge is only used up once the code was accepted.
+func (u *userUsecase) LoginMFA(ctx context.Context, loginMFARequest *domain.LoginMFARequest) (*domain.LoginUserResponse, error) {
+	challengeHash := utils.HashToken(loginMFARequest.MFAToken)
+
 	// Call the repository
-	user, err := u.userRepository.GetUserByUserName(ctx, loginUserRequest.UserName)
+	challenge, err := u.oneTimeTokenRepository.GetOneTimeToken(ctx, models.OneTimeTokenMFAChallenge, challengeHash)
+	if err != nil {
+		log.Println("[UserUsecase][LoginMFA] Error in GetOneTimeToken: ", err)
+		return nil, err
+	}
+
+	user, err := u.userRepository.GetUserByUserID(ctx, challenge.UserUUID.String())
+	if err != nil {
+		log.Println("[UserUsecase][LoginMFA] Error in GetUserByUserID: ", err)
+		return nil, err
+	}
+
+	if err := u.loginGuard.check(ctx, user.UserName, loginMFARequest.ClientIP); err != nil {
+		return nil, err
+	}
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	factor, err := confirmedMFAFactor(ctx, u.mfaRepository, user.UUID.String())
+	if err != nil {
+		log.Println("[UserUsecase][LoginMFA] Error in confirmedMFAFactor: ", err)
+		return nil, err
+	}
+	// MFA was turned off since the challenge was issued, the challenge is useless then
+	if factor == nil {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid or expired token", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	if err := verifyMFACode(ctx, u.mfaRepository, factor, loginMFARequest.Code); err != nil {
+		log.Println("[UserUsecase][LoginMFA] Error in verifyMFACode: ", err)
+		if cerr.GetErrorCode(err) != cerr.InvalidRequestErrorCode {
+			return nil, err
+		}
+		return nil, u.loginGuard.fail(ctx, user.UserName, loginMFARequest.ClientIP, err)
+	}
+
+	// Use the challenge up, a concurrent request with another valid code fails here
+	if _, err := u.oneTimeTokenRepository.UseOneTimeToken(ctx, models.OneTimeTokenMFAChallenge, challengeHash); err != nil {
+		log.Println("[UserUsecase][LoginMFA] Error in UseOneTimeToken: ", err)
+		return nil, err
+	}
+
+	if err := u.loginGuard.recordSuccess(ctx, user.UserName); err != nil {
+		log.Println("[UserUsecase][LoginMFA] Error in recordSuccess: ", err)
+		return nil, err
+	}
+
+	// Record the session for this login
+	session, err := u.tokens.startSession(ctx, user, loginMFARequest.UserAgent, loginMFARequest.ClientIP, mfaAuthMethods)
 	if err != nil {
-		log.Println("[UserUsecase][LoginUser] Error in GetUserByUserName: ", err)
+		log.Println("[UserUsecase][LoginMFA] Error in startSession: ", err)
 		return nil, err
 	}
 
-	// Compare the password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginUserRequest.Password)); err != nil {
-		log.Println("[UserUsecase][LoginUser] Error in CompareHashAndPassword: ", err)
+	// Generate the JWT tokens
+	tokens, err := u.tokens.issueTokens(ctx, user, session, &tokenGrant{})
+	if err != nil {
+		log.Println("[UserUsecase][LoginMFA] Error in issueTokens: ", err)
 		return nil, err
 	}
 
-	// Generate the JWT token
-	token, err := jwt.GenerateToken(user.UUID.String(), user.CreatedAt)
+	return &domain.LoginUserResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		IDToken:      tokens.IDToken,
+	}, nil
+}
+
+func (u *userUsecase) RefreshToken(ctx context.Context, refreshTokenRequest *domain.RefreshTokenRequest) (*domain.LoginUserResponse, error) {
+	// Only refresh tokens issued by the password login can be redeemed here
+	tokens, err := u.tokens.refreshTokens(ctx, refreshTokenRequest.RefreshToken, "")
 	if err != nil {
-		log.Println("[UserUsecase][LoginUser] Error in GenerateToken : ", err)
+		log.Println("[UserUsecase][RefreshToken] Error in refreshTokens: ", err)
 		return nil, err
 	}
 
 	return &domain.LoginUserResponse{
-		Token: token,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		IDToken:      tokens.IDToken,
 	}, nil
 }
 
+func (u *userUsecase) Logout(ctx context.Context, logoutRequest *domain.LogoutRequest) error {
+	// Only a token that is still valid can be logged out
+	claims, err := jwt.GetClaims(ctx, logoutRequest.Token)
+	if err != nil {
+		log.Println("[UserUsecase][Logout] Error in GetClaims: ", err)
+		return cerr.NewCustomErrorWithCodeAndOrigin("Invalid token", cerr.InvalidRequestErrorCode, err)
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Token has no id", cerr.InvalidRequestErrorCode, nil)
+	}
+
+	// The entry only has to outlive the token itself
+	if err := u.revocationRepository.RevokeToken(ctx, models.RevocationKindJTI, jti, jwt.ExpiresAt(claims)); err != nil {
+		log.Println("[UserUsecase][Logout] Error in RevokeToken: ", err)
+		return err
+	}
+
+	// Logging out also ends the session the token belongs to
+	if sid, _ := claims["sid"].(string); sid != "" {
+		if err := u.tokens.endSession(ctx, sid, models.RefreshTokenRevokedSessionEnded); err != nil {
+			log.Println("[UserUsecase][Logout] Error in endSession: ", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RevokeSession ends a session of the user named in the request, sessions of other users are reported as not found
+func (u *userUsecase) RevokeSession(ctx context.Context, revokeSessionRequest *domain.RevokeSessionRequest) error {
+	sid := strings.TrimSpace(revokeSessionRequest.SessionID)
+	if _, err := uuid.FromString(sid); err != nil {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Session not found", cerr.NotFoundErrorCode, err)
+	}
+
+	// Remove the space from the username
+	userName := html.EscapeString(strings.TrimSpace(revokeSessionRequest.UserName))
+
+	// Call the repository
+	user, err := u.userRepository.GetUserByUserName(ctx, userName)
+	if err != nil {
+		log.Println("[UserUsecase][RevokeSession] Error in GetUserByUserName: ", err)
+		return err
+	}
+	session, err := u.sessionRepository.GetSessionByID(ctx, sid)
+	if err != nil {
+		log.Println("[UserUsecase][RevokeSession] Error in GetSessionByID: ", err)
+		return err
+	}
+	if session.UserUUID != user.UUID {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Session not found", cerr.NotFoundErrorCode, nil)
+	}
+
+	if err := u.tokens.endSession(ctx, sid, models.RefreshTokenRevokedSessionEnded); err != nil {
+		log.Println("[UserUsecase][RevokeSession] Error in endSession: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (u *userUsecase) GetSessions(ctx context.Context, getSessionsRequest *domain.GetSessionsRequest) (*domain.GetSessionsResponse, error) {
+	sessions, err := u.sessionRepository.GetActiveSessionsByUserID(ctx, getSessionsRequest.UserID)
+	if err != nil {
+		log.Println("[UserUsecase][GetSessions] Error in GetActiveSessionsByUserID: ", err)
+		return nil, err
+	}
+
+	response := &domain.GetSessionsResponse{Sessions: make([]domain.SessionResponse, 0, len(sessions))}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, domain.SessionResponse{
+			ID:         session.UUID.String(),
+			UserAgent:  session.UserAgent,
+			ClientIP:   session.ClientIP,
+			Current:    session.UUID.String() == getSessionsRequest.CurrentSessionID,
+			CreatedAt:  session.CreatedAt.String(),
+			LastSeenAt: session.LastSeenAt.String(),
+		})
+	}
+
+	return response, nil
+}
+
+func (u *userUsecase) EndSession(ctx context.Context, endSessionRequest *domain.EndSessionRequest) error {
+	sid := strings.TrimSpace(endSessionRequest.SessionID)
+	if _, err := uuid.FromString(sid); err != nil {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Session not found", cerr.NotFoundErrorCode, err)
+	}
+
+	// Users can only end their own sessions
+	session, err := u.sessionRepository.GetSessionByID(ctx, sid)
+	if err != nil {
+		log.Println("[UserUsecase][EndSession] Error in GetSessionByID: ", err)
+		return err
+	}
+	if session.UserUUID.String() != endSessionRequest.UserID {
+		return cerr.NewCustomErrorWithCodeAndOrigin("Session not found", cerr.NotFoundErrorCode, nil)
+	}
+
+	if err := u.tokens.endSession(ctx, sid, models.RefreshTokenRevokedSessionEnded); err != nil {
+		log.Println("[UserUsecase][EndSession] Error in endSession: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (u *userUsecase) GetUserInfo(ctx context.Context, userInfoRequest *domain.UserInfoRequest) (map[string]interface{}, error) {
+	user, err := u.userRepository.GetUserByUserID(ctx, userInfoRequest.UserID)
+	if err != nil {
+		log.Println("[UserUsecase][GetUserInfo] Error in GetUserByUserID: ", err)
+		return nil, err
+	}
+
+	// The userinfo response carries the same claims as the tokens, built from the current user record
+	userInfo, err := u.claimsBuilder.Build(ctx, user, nil)
+	if err != nil {
+		log.Println("[UserUsecase][GetUserInfo] Error in Build: ", err)
+		return nil, err
+	}
+	userInfo["sub"] = user.UUID.String()
+
+	return userInfo, nil
+}
+
+func (u *userUsecase) GetUser(ctx context.Context, getUserRequest *domain.GetUserRequest) (*domain.GetUserByUserNameResponse, error) {
+	// Call the repository
+	user, err := u.userRepository.GetUserByUserID(ctx, getUserRequest.UserID)
+	if err != nil {
+		log.Println("[UserUsecase][GetUser] Error in GetUserByUserID: ", err)
+		return nil, err
+	}
+
+	return toUserResponse(user), nil
+}
+
 func (u *userUsecase) GetUserByUserName(ctx context.Context, getUserByUserNameRequest *domain.GetUserByUserNameRequest) (*domain.GetUserByUserNameResponse, error) {
 	// Remove the space from the username
 	getUserByUserNameRequest.UserName = html.EscapeString(strings.TrimSpace(getUserByUserNameRequest.UserName))
@@ -95,12 +409,239 @@ func (u *userUsecase) GetUserByUserName(ctx context.Context, getUserByUserNameRe
 		return nil, err
 	}
 
-	return &domain.GetUserByUserNameResponse{
-		ID:        user.UUID.String(),
-		UserName:  user.UserName,
-		CreatedAt: user.CreatedAt.String(),
-		UpdatedAt: user.UpdatedAt.String(),
-	}, nil
+	// Users can only read their own record, other users are reported as not found so their existence is not revealed
+	if !getUserByUserNameRequest.RequesterCanReadAll && user.UUID.String() != getUserByUserNameRequest.RequesterID {
+		return nil, errUserNotFound()
+	}
+
+	return toUserResponse(user), nil
+}
+
+func (u *userUsecase) UpdateUser(ctx context.Context, updateUserRequest *domain.UpdateUserRequest) (*domain.GetUserByUserNameResponse, error) {
+	user, err := u.userForRequester(ctx, updateUserRequest.UserName, updateUserRequest.RequesterID, updateUserRequest.RequesterCanWriteAll, updateUserRequest.RequesterCanWriteSelf)
+	if err != nil {
+		log.Println("[UserUsecase][UpdateUser] Error in userForRequester: ", err)
+		return nil, err
+	}
+
+	previousEmail := utils.StringValue(user.Email)
+	if err := applyProfile(user, &updateUserRequest.UserProfile); err != nil {
+		log.Println("[UserUsecase][UpdateUser] Error in applyProfile: ", err)
+		return nil, err
+	}
+
+	// A new email address is unverified until the user confirms it
+	emailChanged := utils.StringValue(user.Email) != previousEmail
+	if emailChanged {
+		user.EmailVerifiedAt = nil
+	}
+
+	// Call the repository
+	if err := u.userRepository.UpdateUser(ctx, user); err != nil {
+		log.Println("[UserUsecase][UpdateUser] Error in UpdateUser: ", err)
+		return nil, err
+	}
+
+	if emailChanged {
+		if err := u.emailVerifier.send(ctx, user); err != nil {
+			log.Println("[UserUsecase][UpdateUser] Error in sending verification email: ", err)
+		}
+	}
+
+	return toUserResponse(user), nil
+}
+
+func (u *userUsecase) ChangeUserName(ctx context.Context, changeUserNameRequest *domain.ChangeUserNameRequest) (*domain.GetUserByUserNameResponse, error) {
+	user, err := u.userForRequester(ctx, changeUserNameRequest.UserName, changeUserNameRequest.RequesterID, changeUserNameRequest.RequesterCanWriteAll, changeUserNameRequest.RequesterCanWriteSelf)
+	if err != nil {
+		log.Println("[UserUsecase][ChangeUserName] Error in userForRequester: ", err)
+		return nil, err
+	}
+
+	// Remove the space from the username
+	userName := html.EscapeString(strings.TrimSpace(changeUserNameRequest.NewUserName))
+	if userName == "" {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("Invalid username", cerr.InvalidRequestErrorCode, nil)
+	}
+	if err := checkReservedUserName(userName); err != nil {
+		return nil, err
+	}
+	if userName == user.UserName {
+		return toUserResponse(user), nil
+	}
+
+	// Call the repository
+	if err := u.userRepository.ChangeUserName(ctx, user, userName); err != nil {
+		log.Println("[UserUsecase][ChangeUserName] Error in ChangeUserName: ", err)
+		return nil, err
+	}
+	user.UserName = userName
+
+	return toUserResponse(user), nil
+}
+
+// DeleteUser soft deletes the user and ends their sessions so the tokens issued to them stop working
+func (u *userUsecase) DeleteUser(ctx context.Context, deleteUserRequest *domain.DeleteUserRequest) error {
+	user, err := u.userForRequester(ctx, deleteUserRequest.UserName, deleteUserRequest.RequesterID, deleteUserRequest.RequesterCanWriteAll, deleteUserRequest.RequesterCanWriteSelf)
+	if err != nil {
+		log.Println("[UserUsecase][DeleteUser] Error in userForRequester: ", err)
+		return err
+	}
+
+	// Call the repository
+	if err := u.userRepository.DeleteUser(ctx, user); err != nil {
+		log.Println("[UserUsecase][DeleteUser] Error in DeleteUser: ", err)
+		return err
+	}
+
+	if err := u.tokens.endUserSessions(ctx, user.UUID.String(), "", models.RefreshTokenRevokedUserDeleted); err != nil {
+		log.Println("[UserUsecase][DeleteUser] Error in endUserSessions: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (u *userUsecase) RestoreUser(ctx context.Context, restoreUserRequest *domain.RestoreUserRequest) (*domain.GetUserByUserNameResponse, error) {
+	// Remove the space from the username
+	userName := html.EscapeString(strings.TrimSpace(restoreUserRequest.UserName))
+
+	// Call the repository
+	user, err := u.userRepository.RestoreUser(ctx, userName)
+	if err != nil {
+		log.Println("[UserUsecase][RestoreUser] Error in RestoreUser: ", err)
+		return nil, err
+	}
+
+	return toUserResponse(user), nil
+}
+
+// UnlockUser lifts the lockout of the username caused by failed logins and moves a user locked by an admin
+// back to active, so either kind of lock is undone by the one endpoint
+func (u *userUsecase) UnlockUser(ctx context.Context, unlockUserRequest *domain.UnlockUserRequest) error {
+	// Remove the space from the username
+	userName := html.EscapeString(strings.TrimSpace(unlockUserRequest.UserName))
+
+	// Call the repository
+	user, err := u.userRepository.GetUserByUserName(ctx, userName)
+	if err != nil {
+		log.Println("[UserUsecase][UnlockUser] Error in GetUserByUserName: ", err)
+		return err
+	}
+
+	if err := u.loginGuard.unlock(ctx, userName); err != nil {
+		log.Println("[UserUsecase][UnlockUser] Error in unlock: ", err)
+		return err
+	}
+
+	if user.Status == models.UserStatusLocked {
+		user.StatusReason = ""
+		user.StatusChangedBy = unlockUserRequest.RequesterID
+		if err := u.userRepository.ChangeUserStatus(ctx, user, models.UserStatusActive); err != nil {
+			log.Println("[UserUsecase][UnlockUser] Error in ChangeUserStatus: ", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ChangeUserStatus moves the user to the status of the request when the transition is allowed. A user leaving
+// the active status has their sessions ended, so the tokens already issued to them stop working.
+func (u *userUsecase) ChangeUserStatus(ctx context.Context, changeUserStatusRequest *domain.ChangeUserStatusRequest) (*domain.GetUserByUserNameResponse, error) {
+	// Remove the space from the username
+	userName := html.EscapeString(strings.TrimSpace(changeUserStatusRequest.UserName))
+
+	// Call the repository
+	user, err := u.userRepository.GetUserByUserName(ctx, userName)
+	if err != nil {
+		log.Println("[UserUsecase][ChangeUserStatus] Error in GetUserByUserName: ", err)
+		return nil, err
+	}
+
+	if !models.CanTransition(user.Status, changeUserStatusRequest.Status) {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin(fmt.Sprintf("User cannot be changed from %s to %s", user.Status, changeUserStatusRequest.Status), cerr.InvalidRequestErrorCode, nil)
+	}
+
+	user.StatusReason = strings.TrimSpace(changeUserStatusRequest.Reason)
+	user.StatusChangedBy = changeUserStatusRequest.RequesterID
+	if err := u.userRepository.ChangeUserStatus(ctx, user, changeUserStatusRequest.Status); err != nil {
+		log.Println("[UserUsecase][ChangeUserStatus] Error in ChangeUserStatus: ", err)
+		return nil, err
+	}
+
+	if user.Status != models.UserStatusActive {
+		if err := u.tokens.endUserSessions(ctx, user.UUID.String(), "", models.RefreshTokenRevokedStatusChanged); err != nil {
+			log.Println("[UserUsecase][ChangeUserStatus] Error in endUserSessions: ", err)
+			return nil, err
+		}
+	}
+
+	return toUserResponse(user), nil
+}
+
+// userForRequester looks up the user a request is about, users can only act on themselves unless canActOnAll is
+// set. Other users are reported as not found so their existence is not revealed.
+func (u *userUsecase) userForRequester(ctx context.Context, userName string, requesterID string, canActOnAll bool, canActOnSelf bool) (*models.User, error) {
+	// Remove the space from the username
+	userName = html.EscapeString(strings.TrimSpace(userName))
+
+	user, err := u.userRepository.GetUserByUserName(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.UUID.String() != requesterID {
+		if !canActOnAll {
+			return nil, errUserNotFound()
+		}
+	} else if !canActOnSelf {
+		return nil, cerr.NewCustomErrorWithCodeAndOrigin("The token is not allowed to change the account", cerr.ForbiddenErrorCode, nil)
+	}
+
+	return user, nil
+}
+
+// reservedUserNames are path segments of static routes next to /user/:username, a user with such a name could
+// not be reached through the username routes
+var reservedUserNames = map[string]bool{"me": true, "groups": true}
+
+// checkReservedUserName rejects usernames that are reserved, whatever their case
+func checkReservedUserName(userName string) error {
+	if reservedUserNames[strings.ToLower(userName)] {
+		return cerr.NewCustomErrorWithCodeAndOrigin(fmt.Sprintf("Username %s is reserved", userName), cerr.InvalidRequestErrorCode, nil)
+	}
+	return nil
+}
+
+// errUserNotFound is the error of the user repository for unknown users
+func errUserNotFound() error {
+	return cerr.NewCustomErrorWithCodeAndOrigin("User not found", cerr.InvalidRequestErrorCode, nil)
+}
+
+func toUserResponse(user *models.User) *domain.GetUserByUserNameResponse {
+	response := &domain.GetUserByUserNameResponse{
+		ID:            user.UUID.String(),
+		UserName:      user.UserName,
+		Email:         utils.StringValue(user.Email),
+		PhoneNumber:   utils.StringValue(user.PhoneNumber),
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		DisplayName:   user.DisplayName,
+		Locale:        user.Locale,
+		Timezone:      user.Timezone,
+		AvatarURL:     user.AvatarURL,
+		Status:        user.Status,
+		StatusReason:  user.StatusReason,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		CreatedAt:     user.CreatedAt.String(),
+		UpdatedAt:     user.UpdatedAt.String(),
+	}
+	if user.StatusChangedAt != nil {
+		response.StatusChangedAt = user.StatusChangedAt.String()
+	}
+
+	return response
 }
 
 // Function to send request to http client server
@@ -2,16 +2,20 @@ package cerr
 
 // Variables to hold error codes
 var (
-	InternalServerErrorCode = 500
-	InvalidRequestErrorCode = 400
-	NotFoundErrorCode       = 404
-	DuplicateEntryErrorCode = 409
+	InternalServerErrorCode  = 500
+	InvalidRequestErrorCode  = 400
+	ForbiddenErrorCode       = 403
+	NotFoundErrorCode        = 404
+	DuplicateEntryErrorCode  = 409
+	TooManyRequestsErrorCode = 429
 )
 
 type CustomError struct {
 	Origin    error
 	ErrorCode int
 	Message   string
+	// Details describe the error further, such as the rules a value violates
+	Details interface{}
 }
 
 func (ce *CustomError) Error() string {
@@ -68,6 +72,14 @@ func NewCustomErrorWithCodeAndOrigin(s string, code int, err error) *CustomError
 	}
 }
 
+func NewCustomErrorWithDetails(s string, code int, details interface{}) *CustomError {
+	return &CustomError{
+		ErrorCode: code,
+		Message:   s,
+		Details:   details,
+	}
+}
+
 func Wrap(s string, e error) *CustomError {
 	return &CustomError{
 		Origin:    e,
@@ -105,3 +117,10 @@ func GetErrorMessage(e error) string {
 	}
 	return e.Error()
 }
+
+func GetErrorDetails(e error) interface{} {
+	if err, ok := e.(*CustomError); ok {
+		return err.Details
+	}
+	return nil
+}
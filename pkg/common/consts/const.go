@@ -14,6 +14,8 @@ const (
 	AppVersion                   = "1.0.0"
 	LogContext        contextKey = "log:context"
 	ConfigContext     contextKey = "config:context"
+	PrincipalContext  contextKey = "auth:principal"
+	TenantContext     contextKey = "auth:tenant"
 	TraceID                      = "traceID"
 )
 
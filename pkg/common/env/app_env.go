@@ -19,9 +19,57 @@ type envConfigMap struct {
 	ServicePort       string `required:"true" envconfig:"SERVICE_PORT"`
 	BasicAuthUser     string `required:"true" envconfig:"BASIC_AUTH_USER"`
 	BasicAuthPassword string `required:"true" envconfig:"BASIC_AUTH_PASSWORD"`
-	JWTSecretKey      string `required:"true" envconfig:"JWT_SECRET_KEY"`
+	JWTSecretKey      string `envconfig:"JWT_SECRET_KEY"`
 	JWTExpirationTime string `required:"true" envconfig:"JWT_EXPIRATION_TIME"`
 	LogFilePath       string `required:"true" envconfig:"LOG_FILE_PATH"`
+
+	// Optional settings, all durations are in minutes
+	RefreshTokenExpirationTime       int      `envconfig:"REFRESH_TOKEN_EXPIRATION_TIME" default:"43200"`
+	RevocationStore                  string   `envconfig:"REVOCATION_STORE" default:"postgres"`
+	JWTIssuer                        string   `envconfig:"JWT_ISSUER" default:"http://localhost:8080"`
+	JWTAudience                      string   `envconfig:"JWT_AUDIENCE" default:"user-management-service"`
+	JWTClaimNamespace                string   `envconfig:"JWT_CLAIM_NAMESPACE" default:"https://mymtn.com/"`
+	JWTSigningAlgorithm              string   `envconfig:"JWT_SIGNING_ALGORITHM" default:"HS256"`
+	JWTPrivateKeyPath                string   `envconfig:"JWT_PRIVATE_KEY_PATH"`
+	JWTKeyID                         string   `envconfig:"JWT_KEY_ID"`
+	JWTKeyGracePeriod                int      `envconfig:"JWT_KEY_GRACE_PERIOD"`
+	SigningKeyEncryptionKey          string   `envconfig:"SIGNING_KEY_ENCRYPTION_KEY"`
+	OAuthCodeExpirationTime          int      `envconfig:"OAUTH_CODE_EXPIRATION_TIME" default:"1"`
+	PermissionSource                 string   `envconfig:"PERMISSION_SOURCE" default:"token"`
+	AdminUserName                    string   `envconfig:"ADMIN_USER_NAME"`
+	JWTGroupsClaim                   bool     `envconfig:"JWT_GROUPS_CLAIM" default:"false"`
+	DefaultTenantID                  string   `envconfig:"DEFAULT_TENANT_ID" default:"default"`
+	TenantBaseDomain                 string   `envconfig:"TENANT_BASE_DOMAIN"`
+	Notifier                         string   `envconfig:"NOTIFIER" default:"log"`
+	NotifierFilePath                 string   `envconfig:"NOTIFIER_FILE_PATH" default:"notifications.log"`
+	PasswordResetURL                 string   `envconfig:"PASSWORD_RESET_URL"`
+	PasswordResetTokenExpirationTime int      `envconfig:"PASSWORD_RESET_TOKEN_EXPIRATION_TIME" default:"30"`
+	ResetResendIntervalSeconds       int      `envconfig:"PASSWORD_RESET_RESEND_INTERVAL_SECONDS" default:"60"`
+	PasswordMinLength                int      `envconfig:"PASSWORD_MIN_LENGTH" default:"8"`
+	PasswordMaxLength                int      `envconfig:"PASSWORD_MAX_LENGTH" default:"72"`
+	PasswordCharacterClasses         []string `envconfig:"PASSWORD_CHARACTER_CLASSES"`
+	PasswordRejectUserName           bool     `envconfig:"PASSWORD_REJECT_USERNAME" default:"true"`
+	PasswordDenyListPath             string   `envconfig:"PASSWORD_DENY_LIST_PATH"`
+	LoginLockoutThreshold            int      `envconfig:"LOGIN_LOCKOUT_THRESHOLD" default:"5"`
+	LoginIPLockoutThreshold          int      `envconfig:"LOGIN_IP_LOCKOUT_THRESHOLD" default:"50"`
+	LoginLockoutDuration             int      `envconfig:"LOGIN_LOCKOUT_DURATION" default:"15"`
+	LoginBackoffBaseSeconds          int      `envconfig:"LOGIN_BACKOFF_BASE_SECONDS" default:"1"`
+	MFAIssuer                        string   `envconfig:"MFA_ISSUER" default:"UserManagementService"`
+	MFAChallengeExpirationTime       int      `envconfig:"MFA_CHALLENGE_EXPIRATION_TIME" default:"5"`
+	OTPLength                        int      `envconfig:"OTP_LENGTH" default:"6"`
+	OTPExpirationTime                int      `envconfig:"OTP_EXPIRATION_TIME" default:"5"`
+	OTPMaxAttempts                   int      `envconfig:"OTP_MAX_ATTEMPTS" default:"5"`
+	OTPResendIntervalSeconds         int      `envconfig:"OTP_RESEND_INTERVAL_SECONDS" default:"30"`
+	SMTPAddr                         string   `envconfig:"SMTP_ADDR"`
+	SMTPUserName                     string   `envconfig:"SMTP_USERNAME"`
+	SMTPPassword                     string   `envconfig:"SMTP_PASSWORD"`
+	SMTPFrom                         string   `envconfig:"SMTP_FROM"`
+	SMSGatewayURL                    string   `envconfig:"SMS_GATEWAY_URL"`
+	SMSGatewayToken                  string   `envconfig:"SMS_GATEWAY_TOKEN"`
+	EmailVerificationSecret          string   `envconfig:"EMAIL_VERIFICATION_SECRET"`
+	EmailVerificationURL             string   `envconfig:"EMAIL_VERIFICATION_URL"`
+	EmailVerificationExpirationTime  int      `envconfig:"EMAIL_VERIFICATION_EXPIRATION_TIME" default:"1440"`
+	EmailResendIntervalSeconds       int      `envconfig:"EMAIL_VERIFICATION_RESEND_INTERVAL_SECONDS" default:"60"`
 }
 
 func LoadConfig() error {
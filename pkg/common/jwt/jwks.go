@@ -0,0 +1,107 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// JWK is the public part of a signing key as described by RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// GetJWKS returns the public keys tokens may be verified with, HMAC keys are never included
+func GetJWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range keyRing.verificationKeys() {
+		if key.Public == nil {
+			continue
+		}
+
+		jwk, err := publicJWK(key.Public)
+		if err != nil {
+			continue
+		}
+		jwk.Use = "sig"
+		jwk.Alg = key.Algorithm
+		jwk.Kid = key.ID
+		jwks.Keys = append(jwks.Keys, *jwk)
+	}
+
+	return jwks
+}
+
+// Thumbprint computes the RFC 7638 SHA-256 thumbprint of a public key
+func Thumbprint(public interface{}) (string, error) {
+	jwk, err := publicJWK(public)
+	if err != nil {
+		return "", err
+	}
+
+	// The members are required to be in lexicographic order, which encoding/json does for maps
+	members := map[string]string{"kty": jwk.Kty}
+	switch jwk.Kty {
+	case "RSA":
+		members["n"], members["e"] = jwk.N, jwk.E
+	case "EC":
+		members["crv"], members["x"], members["y"] = jwk.Crv, jwk.X, jwk.Y
+	case "OKP":
+		members["crv"], members["x"] = jwk.Crv, jwk.X
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func publicJWK(public interface{}) (*JWK, error) {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		return &JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		crv, err := curveName(key.Curve)
+		if err != nil {
+			return nil, err
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		return &JWK{
+			Kty: "EC",
+			Crv: crv,
+			X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case ed25519.PublicKey:
+		return &JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type: %T", public)
+}
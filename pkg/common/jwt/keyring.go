@@ -0,0 +1,287 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
+)
+
+// KeyStore persists the key ring so rotated keys survive restarts and are shared between instances
+type KeyStore interface {
+	GetSigningKeys(ctx context.Context) ([]*SigningKey, error)
+	SaveSigningKey(ctx context.Context, key *SigningKey) error
+}
+
+// keyReloadInterval limits how often an unknown kid triggers a reload from the store
+const keyReloadInterval = time.Minute
+
+// KeyRing holds the active signing key together with the retired keys that still verify tokens
+type KeyRing struct {
+	mu         sync.RWMutex
+	store      KeyStore
+	configured *SigningKey
+	stored     []*SigningKey
+	keys       map[string]*SigningKey
+	active     *SigningKey
+	loadedAt   time.Time
+}
+
+var keyRing = &KeyRing{keys: make(map[string]*SigningKey)}
+
+// SetKeyStore sets the store the key ring is loaded from and rotated keys are saved to
+func SetKeyStore(store KeyStore) {
+	keyRing.mu.Lock()
+	defer keyRing.mu.Unlock()
+	keyRing.store = store
+}
+
+// ReloadSigningKeys reloads the key ring from the key store
+func ReloadSigningKeys(ctx context.Context) error {
+	return keyRing.reload(ctx)
+}
+
+// RotateSigningKey generates a key with the configured algorithm and makes it the active key.
+// The previous active key is retired and keeps verifying tokens for the grace period.
+func RotateSigningKey(ctx context.Context, grace time.Duration) (*SigningKey, error) {
+	store, previous, err := keyRing.storeAndActive()
+	if err != nil {
+		return nil, err
+	}
+
+	private, err := GeneratePrivateKey(env.EnvConfig.JWTSigningAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := NewSigningKey("", env.EnvConfig.JWTSigningAlgorithm, private)
+	if err != nil {
+		return nil, err
+	}
+
+	// The new key is saved first, if retiring the previous one fails the newest active key still wins
+	if err := store.SaveSigningKey(ctx, key); err != nil {
+		return nil, err
+	}
+
+	retired := retire(previous, grace)
+	if err := store.SaveSigningKey(ctx, retired); err != nil {
+		return nil, err
+	}
+
+	if err := keyRing.reload(ctx); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// RetireSigningKey retires a key that is no longer active, it keeps verifying tokens for the grace period
+func RetireSigningKey(ctx context.Context, kid string, grace time.Duration) (*SigningKey, error) {
+	store, active, err := keyRing.storeAndActive()
+	if err != nil {
+		return nil, err
+	}
+	if active.ID == kid {
+		return nil, fmt.Errorf("the active key cannot be retired, rotate it first")
+	}
+
+	keyRing.mu.RLock()
+	key, ok := keyRing.keys[kid]
+	keyRing.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %s", kid)
+	}
+
+	retired := retire(key, grace)
+	if err := store.SaveSigningKey(ctx, retired); err != nil {
+		return nil, err
+	}
+
+	if err := keyRing.reload(ctx); err != nil {
+		return nil, err
+	}
+	return retired, nil
+}
+
+// GetSigningKeys lists every key of the ring, newest first
+func GetSigningKeys() []SigningKey {
+	keyRing.mu.RLock()
+	defer keyRing.mu.RUnlock()
+
+	keys := make([]SigningKey, 0, len(keyRing.keys))
+	for _, key := range keyRing.keys {
+		keys = append(keys, *key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	return keys
+}
+
+// KeyGracePeriod returns how long a retired key keeps verifying tokens, by default one access token lifetime
+func KeyGracePeriod() (time.Duration, error) {
+	if env.EnvConfig.JWTKeyGracePeriod > 0 {
+		return time.Duration(env.EnvConfig.JWTKeyGracePeriod) * time.Minute, nil
+	}
+	return TokenLifetime()
+}
+
+// retire returns a retired copy of the key expiring after the grace period
+func retire(key *SigningKey, grace time.Duration) *SigningKey {
+	now := time.Now()
+	expiresAt := now.Add(grace)
+
+	retired := *key
+	retired.Status = KeyStatusRetired
+	if retired.RetiredAt == nil {
+		retired.RetiredAt = &now
+	}
+	retired.ExpiresAt = &expiresAt
+	return &retired
+}
+
+func (r *KeyRing) setConfiguredKey(key *SigningKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.configured = key
+	r.rebuild()
+}
+
+func (r *KeyRing) reload(ctx context.Context) error {
+	r.mu.RLock()
+	store := r.store
+	r.mu.RUnlock()
+	if store == nil {
+		return nil
+	}
+
+	stored, err := store.GetSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stored = stored
+	r.loadedAt = time.Now()
+	r.rebuild()
+	return nil
+}
+
+// rebuild merges the configured key with the stored keys, the caller must hold the write lock
+func (r *KeyRing) rebuild() {
+	keys := make(map[string]*SigningKey)
+	if r.configured != nil {
+		keys[r.configured.ID] = r.configured
+	}
+
+	for _, key := range r.stored {
+		// Stored entries without material only track the lifecycle of the configured key
+		if key.Configured {
+			if r.configured != nil && r.configured.ID == key.ID {
+				configured := *r.configured
+				configured.Status, configured.RetiredAt, configured.ExpiresAt = key.Status, key.RetiredAt, key.ExpiresAt
+				keys[key.ID] = &configured
+			}
+			continue
+		}
+		keys[key.ID] = key
+	}
+
+	// A rotated key takes precedence over the configured key, the newest one wins
+	var active *SigningKey
+	for _, key := range keys {
+		if key.Status == KeyStatusActive && !key.Configured && (active == nil || key.CreatedAt.After(active.CreatedAt)) {
+			active = key
+		}
+	}
+	if active == nil && r.configured != nil && keys[r.configured.ID].Status == KeyStatusActive {
+		active = keys[r.configured.ID]
+	}
+
+	r.keys = keys
+	r.active = active
+}
+
+func (r *KeyRing) storeAndActive() (KeyStore, *SigningKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.store == nil {
+		return nil, nil, fmt.Errorf("key store not configured")
+	}
+	if r.active == nil {
+		return nil, nil, fmt.Errorf("signing key not loaded")
+	}
+	return r.store, r.active, nil
+}
+
+func (r *KeyRing) activeKey() (*SigningKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.active == nil {
+		return nil, fmt.Errorf("signing key not loaded")
+	}
+	return r.active, nil
+}
+
+// verificationKey finds the key for a kid, reloading the ring once in a while for keys rotated by other instances
+func (r *KeyRing) verificationKey(ctx context.Context, kid string) (*SigningKey, error) {
+	key, found, stale := r.lookup(kid)
+	if !found && stale {
+		if err := r.reload(ctx); err != nil {
+			return nil, err
+		}
+		key, found, _ = r.lookup(kid)
+	}
+	if !found {
+		return nil, fmt.Errorf("unknown signing key: %v", kid)
+	}
+	if key.Expired(time.Now()) {
+		return nil, fmt.Errorf("signing key has expired: %v", kid)
+	}
+	return key, nil
+}
+
+func (r *KeyRing) lookup(kid string) (*SigningKey, bool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stale := r.store != nil && time.Since(r.loadedAt) > keyReloadInterval
+
+	// Tokens without a kid were signed by the configured key before kid headers were added
+	if kid == "" && r.configured != nil {
+		kid = r.configured.ID
+	}
+	key, ok := r.keys[kid]
+	return key, ok, stale
+}
+
+// verificationKeys returns the keys that still verify tokens
+func (r *KeyRing) verificationKeys() []*SigningKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	keys := make([]*SigningKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		if !key.Expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	return keys
+}
+
+// SigningAlgorithms returns the distinct algorithms of the keys that still verify tokens
+func SigningAlgorithms() []string {
+	seen := make(map[string]bool)
+	algorithms := []string{}
+	for _, key := range keyRing.verificationKeys() {
+		if !seen[key.Algorithm] {
+			seen[key.Algorithm] = true
+			algorithms = append(algorithms, key.Algorithm)
+		}
+	}
+	return algorithms
+}
@@ -0,0 +1,188 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
+)
+
+// Key statuses, only the active key signs while retired keys keep verifying until they expire
+const (
+	KeyStatusActive  = "active"
+	KeyStatusRetired = "retired"
+)
+
+// SigningKey is a key tokens are signed with, Public is nil for HMAC keys since they cannot be published.
+// Configured marks the key loaded from the environment, its material is never persisted.
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	Method     jwt.SigningMethod
+	Private    interface{}
+	Public     interface{}
+	Configured bool
+	Status     string
+	CreatedAt  time.Time
+	RetiredAt  *time.Time
+	ExpiresAt  *time.Time
+}
+
+// Expired reports whether the key may no longer be used to verify tokens
+func (k *SigningKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// LoadSigningKey loads the key configured by JWT_SIGNING_ALGORITHM, JWT_PRIVATE_KEY_PATH and JWT_KEY_ID.
+// HS256 keeps using JWT_SECRET_KEY, every other algorithm reads a PEM encoded private key.
+func LoadSigningKey() error {
+	algorithm := env.EnvConfig.JWTSigningAlgorithm
+
+	var private interface{}
+	if _, ok := jwt.GetSigningMethod(algorithm).(*jwt.SigningMethodHMAC); ok {
+		if env.EnvConfig.JWTSecretKey == "" {
+			return fmt.Errorf("JWT_SECRET_KEY not set")
+		}
+		private = []byte(env.EnvConfig.JWTSecretKey)
+	} else {
+		if env.EnvConfig.JWTPrivateKeyPath == "" {
+			return fmt.Errorf("JWT_PRIVATE_KEY_PATH not set for %s", algorithm)
+		}
+		data, err := os.ReadFile(env.EnvConfig.JWTPrivateKeyPath)
+		if err != nil {
+			return fmt.Errorf("reading signing key: %w", err)
+		}
+		private, err = ParsePrivateKeyPEM(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	key, err := NewSigningKey(env.EnvConfig.JWTKeyID, algorithm, private)
+	if err != nil {
+		return err
+	}
+	key.Configured = true
+
+	keyRing.setConfiguredKey(key)
+	return nil
+}
+
+// NewSigningKey checks that the private key suits the algorithm and derives its public key.
+// When id is empty the RFC 7638 thumbprint of the public key is used, or "default" for HMAC keys.
+func NewSigningKey(id string, algorithm string, private interface{}) (*SigningKey, error) {
+	method := jwt.GetSigningMethod(algorithm)
+	if method == nil {
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+
+	key := &SigningKey{ID: id, Algorithm: algorithm, Method: method, Private: private, Status: KeyStatusActive, CreatedAt: time.Now()}
+	switch m := method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if _, ok := private.([]byte); !ok {
+			return nil, fmt.Errorf("%s requires a secret", algorithm)
+		}
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		rsaKey, ok := private.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%s requires an RSA key", algorithm)
+		}
+		key.Public = &rsaKey.PublicKey
+	case *jwt.SigningMethodECDSA:
+		ecKey, ok := private.(*ecdsa.PrivateKey)
+		if !ok || ecKey.Curve.Params().BitSize != m.CurveBits {
+			return nil, fmt.Errorf("%s requires an ECDSA key on a %d bit curve", algorithm, m.CurveBits)
+		}
+		key.Public = &ecKey.PublicKey
+	case *SigningMethodEd25519:
+		edKey, ok := private.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%s requires an Ed25519 key", algorithm)
+		}
+		key.Public = edKey.Public()
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+
+	if key.ID == "" {
+		key.ID = "default"
+		if key.Public != nil {
+			thumbprint, err := Thumbprint(key.Public)
+			if err != nil {
+				return nil, err
+			}
+			key.ID = thumbprint
+		}
+	}
+
+	return key, nil
+}
+
+// GeneratePrivateKey creates a new private key for an asymmetric algorithm
+func GeneratePrivateKey(algorithm string) (crypto.PrivateKey, error) {
+	switch m := jwt.GetSigningMethod(algorithm).(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case *jwt.SigningMethodECDSA:
+		curves := map[int]elliptic.Curve{256: elliptic.P256(), 384: elliptic.P384(), 521: elliptic.P521()}
+		curve, ok := curves[m.CurveBits]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve size: %d", m.CurveBits)
+		}
+		return ecdsa.GenerateKey(curve, rand.Reader)
+	case *SigningMethodEd25519:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		return private, err
+	}
+	return nil, fmt.Errorf("keys cannot be generated for %s", algorithm)
+}
+
+// MarshalPrivateKeyPEM encodes a private key as a PKCS#8 PEM block
+func MarshalPrivateKeyPEM(private crypto.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// ParsePrivateKeyPEM parses a PKCS#8, PKCS#1 or SEC 1 PEM encoded private key
+func ParsePrivateKeyPEM(data []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in signing key")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("unsupported PEM block type: %s", block.Type)
+}
+
+// curveName returns the JWK name of an elliptic curve
+func curveName(curve elliptic.Curve) (string, error) {
+	switch curve {
+	case elliptic.P256():
+		return "P-256", nil
+	case elliptic.P384():
+		return "P-384", nil
+	case elliptic.P521():
+		return "P-521", nil
+	}
+	return "", fmt.Errorf("unsupported curve: %s", curve.Params().Name)
+}
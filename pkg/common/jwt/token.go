@@ -1,83 +1,104 @@
 package jwt
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/gofrs/uuid"
 	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
 )
 
-// Function to generate jwt token
-func GenerateToken(userID string, createdAt time.Time) (string, error) {
-	// Get the jwt secret from the environment variable
-	jwtSecret := env.EnvConfig.JWTSecretKey
-	if jwtSecret == "" {
-		return "", fmt.Errorf("JWT_SECRET not set")
+// RevocationStore reports whether a token has been revoked, either by its own id or by its session id
+type RevocationStore interface {
+	IsRevoked(ctx context.Context, jti string, sid string) (bool, error)
+}
+
+var revocationStore RevocationStore
+
+// SetRevocationStore sets the store consulted by ValidateToken and GetClaims
+func SetRevocationStore(store RevocationStore) {
+	revocationStore = store
+}
+
+// Token types set in the typ header, only access tokens are accepted by ValidateToken and GetClaims
+const (
+	TokenTypeAccess = "at+jwt"
+	TokenTypeID     = "JWT"
+)
+
+// Function to generate jwt token, the registered claims are set here while customClaims carries
+// everything describing the user. Custom claims cannot override the registered ones except auth_time.
+func GenerateToken(userID string, sessionID string, customClaims map[string]interface{}) (string, error) {
+	// Generate a unique id for the token so it can be revoked on its own
+	tokenID, err := uuid.NewV4()
+	if err != nil {
+		return "", err
 	}
 
-	// Get the jwt expiry from the environment variable
-	jwtExpiry := env.EnvConfig.JWTExpirationTime
-	if jwtExpiry == "" {
-		return "", fmt.Errorf("JWT_EXPIRY not set")
+	claims := make(jwt.MapClaims)
+	for key, value := range customClaims {
+		claims[key] = value
 	}
+	claims["aud"] = env.EnvConfig.JWTAudience // Audience of the token
+	claims["jti"] = tokenID.String()          // Unique ID of the token
 
-	// Convert the jwt expiry to int64
-	jwtExpiryInt, err := strconv.ParseInt(jwtExpiry, 10, 64)
+	return signToken(TokenTypeAccess, userID, sessionID, claims)
+}
+
+// GenerateIDToken generates an OpenID Connect ID token for the client, nonce is echoed when the client sent one
+func GenerateIDToken(userID string, sessionID string, clientID string, nonce string, customClaims map[string]interface{}) (string, error) {
+	claims := make(jwt.MapClaims)
+	for key, value := range customClaims {
+		claims[key] = value
+	}
+	claims["aud"] = clientID // Audience of the token is the client
+	if nonce != "" {
+		claims["nonce"] = nonce // Nonce sent by the client in the authentication request
+	}
+
+	return signToken(TokenTypeID, userID, sessionID, claims)
+}
+
+// signToken sets the claims common to every token and signs it with the active key of the key ring
+func signToken(tokenType string, userID string, sessionID string, claims jwt.MapClaims) (string, error) {
+	// Get the active key of the key ring
+	signingKey, err := keyRing.activeKey()
+	if err != nil {
+		return "", err
+	}
+
+	// Get the jwt expiry from the environment variable
+	tokenLifetime, err := TokenLifetime()
 	if err != nil {
 		return "", err
 	}
 
 	// Set the claims for the token
-	claims := make(jwt.MapClaims)
-	claims["https://mymtn.com/loginCount"] = 6
-	claims["https://mymtn.com/secondaryProfile"] = []interface{}{
-		map[string]interface{}{
-			"user_id":    userID,
-			"provider":   "sms",
-			"connection": "sms",
-			"isSocial":   false,
-		},
-		map[string]interface{}{
-			"profileData": map[string]interface{}{
-				"email":           "Firstname.Lastname@mtn.com",
-				"email_verified":  "true",
-				"nonce_supported": true,
-				"first_name":      "Firstname",
-				"last_name":       "Lastname",
-				"name":            "Firstname Lastname",
-			},
-			"user_id":       userID,
-			"refresh_token": "re1f76a0bdaa3437aac406791e4f56aa3.0.mrtsu.b-I-9K7ssi1sZ5_RQoUfcw",
-			"provider":      "apple",
-			"connection":    "apple",
-			"isSocial":      true,
-		},
-	}
-	claims["https://mymtn.com/phone_number"] = "+26876000000"
-	claims["https://mymtn.com/country"] = "SWZ"
-	claims["https://skillsacademy.mtn.com/connection"] = "sms"
-	claims["https://skillsacademy.mtn.com/email"] = "876060571@skillsacademy.mtn.com"
-	claims["nickname"] = "+26876000000"
-	claims["name"] = "+26876000000"
-	claims["picture"] = "testPicture.png"
-	claims["updated_at"] = "2023-12-21T13:38:13.917Z"
-	claims["iss"] = "localhost"                        // Issuer of the token
-	claims["aud"] = "testValue"                        // Audience of the token
-	claims["iat"] = createdAt                          // Issued at time of the token
-	claims["sub"] = userID                             // Subject of the token
-	claims["auth_time"] = time.Now().Unix()            // Time of authentication
-	claims["sid"] = "fVWiwb4QMmU565YGZw3HQQsT-Dfa_2S7" // Session ID of the token
+	now := time.Now()
+	if _, ok := claims["auth_time"]; !ok {
+		claims["auth_time"] = now.Unix() // Time of authentication
+	}
+	claims["iss"] = env.EnvConfig.JWTIssuer // Issuer of the token
+	claims["iat"] = now.Unix()              // Issued at time of the token
+	claims["sub"] = userID                  // Subject of the token
+	if sessionID != "" {
+		claims["sid"] = sessionID // Session ID of the token
+	}
 
 	// Set the expiration time for the token
-	claims["exp"] = time.Now().Add(time.Duration(jwtExpiryInt) * time.Minute).Unix() // Expiration time of the token
+	claims["exp"] = now.Add(tokenLifetime).Unix() // Expiration time of the token
 
-	// Create the token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// Create the token, the kid header tells verifiers which key to use
+	token := jwt.NewWithClaims(signingKey.Method, claims)
+	token.Header["kid"] = signingKey.ID
+	token.Header["typ"] = tokenType
 
-	// Sign the token with the secret
-	tokenString, err := token.SignedString([]byte(jwtSecret))
+	// Sign the token with the private key
+	tokenString, err := token.SignedString(signingKey.Private)
 	if err != nil {
 		return "", err
 	}
@@ -85,57 +106,76 @@ func GenerateToken(userID string, createdAt time.Time) (string, error) {
 	return tokenString, nil
 }
 
-// Function to validate jwt token
-func ValidateToken(tokenString string) error {
-	// Get the jwt secret from the environment variable
-	jwtSecret := env.EnvConfig.JWTSecretKey
-	if jwtSecret == "" {
-		return fmt.Errorf("jwt secret not set")
-	}
-
-	// Remove the Bearer prefix from the token if it exists
-	if len(tokenString) > 7 && tokenString[:7] == "Bearer " {
-		tokenString = tokenString[7:]
+// TokenLifetime returns the configured lifetime of an access token
+func TokenLifetime() (time.Duration, error) {
+	jwtExpiry := env.EnvConfig.JWTExpirationTime
+	if jwtExpiry == "" {
+		return 0, fmt.Errorf("JWT_EXPIRY not set")
 	}
 
-	// Parse the token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok { // Check the signing method
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(jwtSecret), nil
-	})
+	// Convert the jwt expiry to int64
+	jwtExpiryInt, err := strconv.ParseInt(jwtExpiry, 10, 64)
 	if err != nil {
-		return fmt.Errorf("token error: %v", err)
+		return 0, err
 	}
 
-	// Validate the token
-	if !token.Valid {
-		return fmt.Errorf("invalid token")
+	return time.Duration(jwtExpiryInt) * time.Minute, nil
+}
+
+// ExpiresAt returns the expiry time held in the exp claim
+func ExpiresAt(claims jwt.MapClaims) time.Time {
+	return NumericDate(claims, "exp")
+}
+
+// NumericDate returns the time held in a numeric date claim such as exp or iat, or the zero time
+func NumericDate(claims jwt.MapClaims, name string) time.Time {
+	switch value := claims[name].(type) {
+	case float64:
+		return time.Unix(int64(value), 0)
+	case int64:
+		return time.Unix(value, 0)
+	case json.Number:
+		if v, err := value.Int64(); err == nil {
+			return time.Unix(v, 0)
+		}
 	}
+	return time.Time{}
+}
 
-	return nil
+// Function to validate jwt token
+func ValidateToken(ctx context.Context, tokenString string) error {
+	_, err := GetClaims(ctx, tokenString)
+	return err
 }
 
-// Function to get the claims from the token
-func GetClaims(tokenString string) (jwt.MapClaims, error) {
-	// Get the jwt secret from the environment variable
-	jwtSecret := env.EnvConfig.JWTSecretKey
-	if jwtSecret == "" {
-		return nil, fmt.Errorf("jwt secret not set")
+// BearerToken returns the token of an Authorization header, the Bearer prefix is optional
+func BearerToken(header string) string {
+	if len(header) > 7 && header[:7] == "Bearer " {
+		return header[7:]
 	}
+	return header
+}
 
+// Function to get the claims from the token
+func GetClaims(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
 	// Remove the Bearer prefix from the token if it exists
-	if len(tokenString) > 7 && tokenString[:7] == "Bearer " {
-		tokenString = tokenString[7:]
-	}
+	tokenString = BearerToken(tokenString)
 
 	// Parse the token
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok { // Check the signing method
+		// Pick the verification key by kid, tokens issued before kid headers were added carry none
+		kid, _ := token.Header["kid"].(string)
+		key, err := keyRing.verificationKey(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.Algorithm { // Check the signing method
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(jwtSecret), nil
+		if key.Public == nil {
+			return key.Private, nil
+		}
+		return key.Public, nil
 	})
 	if err != nil {
 		return nil, fmt.Errorf("token error: %v", err)
@@ -146,11 +186,29 @@ func GetClaims(tokenString string) (jwt.MapClaims, error) {
 		return nil, fmt.Errorf("invalid token")
 	}
 
+	// ID tokens are signed with the same keys but must not be used as access tokens
+	if typ, _ := token.Header["typ"].(string); typ != TokenTypeAccess {
+		return nil, fmt.Errorf("not an access token")
+	}
+
 	// Get the claims from the token
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
 		return nil, fmt.Errorf("invalid token claims")
 	}
 
+	// Check the token and its session against the revocation list
+	if revocationStore != nil {
+		jti, _ := claims["jti"].(string)
+		sid, _ := claims["sid"].(string)
+		revoked, err := revocationStore.IsRevoked(ctx, jti, sid)
+		if err != nil {
+			return nil, fmt.Errorf("revocation check failed: %v", err)
+		}
+		if revoked {
+			return nil, fmt.Errorf("token has been revoked")
+		}
+	}
+
 	return claims, nil
 }
@@ -0,0 +1,68 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/env"
+)
+
+// revocationList is a RevocationStore holding the revoked token ids and session ids
+type revocationList struct {
+	jtis map[string]bool
+	sids map[string]bool
+}
+
+func (l *revocationList) IsRevoked(ctx context.Context, jti string, sid string) (bool, error) {
+	return l.jtis[jti] || l.sids[sid], nil
+}
+
+func TestGetClaimsRejectsRevokedTokens(t *testing.T) {
+	env.EnvConfig.JWTSigningAlgorithm = "HS256"
+	env.EnvConfig.JWTSecretKey = "test-secret"
+	env.EnvConfig.JWTExpirationTime = "15"
+	if err := LoadSigningKey(); err != nil {
+		t.Fatalf("LoadSigningKey() error = %v", err)
+	}
+
+	revoked := &revocationList{jtis: map[string]bool{}, sids: map[string]bool{}}
+	SetRevocationStore(revoked)
+	defer SetRevocationStore(nil)
+
+	generate := func(sessionID string) (string, string) {
+		token, err := GenerateToken("user-1", sessionID, nil)
+		if err != nil {
+			t.Fatalf("GenerateToken() error = %v", err)
+		}
+		claims, err := GetClaims(context.Background(), token)
+		if err != nil {
+			t.Fatalf("GetClaims() error = %v", err)
+		}
+		return token, claims["jti"].(string)
+	}
+	first, firstJTI := generate("session-a")
+	second, _ := generate("session-a")
+	other, _ := generate("session-b")
+
+	check := func(name string, token string, wantRevoked bool) {
+		t.Helper()
+		_, err := GetClaims(context.Background(), "Bearer "+token)
+		if wantRevoked && (err == nil || err.Error() != "token has been revoked") {
+			t.Errorf("%s: GetClaims() error = %v, want the token to be revoked", name, err)
+		}
+		if !wantRevoked && err != nil {
+			t.Errorf("%s: GetClaims() error = %v, want the token to be accepted", name, err)
+		}
+	}
+
+	// Revoking a jti rejects that token only
+	revoked.jtis[firstJTI] = true
+	check("revoked jti", first, true)
+	check("other token of the session", second, false)
+	check("token of another session", other, false)
+
+	// Revoking a sid rejects every token of the session
+	revoked.sids["session-a"] = true
+	check("revoked sid", second, true)
+	check("token of another session", other, false)
+}
@@ -0,0 +1,97 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Channels a message can be sent on
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+)
+
+// Message is a notification sent to a user, To is an email address or a phone number depending on the channel
+type Message struct {
+	Channel string `json:"channel"`
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Notifier delivers messages to users. The log and file notifiers are meant for local use, the SMTP and SMS
+// gateway notifiers deliver messages for real.
+type Notifier interface {
+	Notify(ctx context.Context, message *Message) error
+}
+
+type channelNotifier struct {
+	email Notifier
+	sms   Notifier
+}
+
+// NewChannelNotifier returns a notifier that hands SMS messages to sms and every other message to email
+func NewChannelNotifier(email Notifier, sms Notifier) Notifier {
+	return &channelNotifier{
+		email: email,
+		sms:   sms,
+	}
+}
+
+func (n *channelNotifier) Notify(ctx context.Context, message *Message) error {
+	if message.Channel == ChannelSMS {
+		return n.sms.Notify(ctx, message)
+	}
+	return n.email.Notify(ctx, message)
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier returns a notifier that writes messages to the standard logger
+func NewLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) Notify(ctx context.Context, message *Message) error {
+	log.Printf("[Notifier] Channel: %s, To: %s, Subject: %s, Body: %s", message.Channel, message.To, message.Subject, message.Body)
+	return nil
+}
+
+type fileNotifier struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileNotifier returns a notifier that appends messages to the file at path, one JSON object per line
+func NewFileNotifier(path string) Notifier {
+	return &fileNotifier{
+		path: path,
+	}
+}
+
+func (n *fileNotifier) Notify(ctx context.Context, message *Message) error {
+	line, err := json.Marshal(struct {
+		*Message
+		SentAt time.Time `json:"sent_at"`
+	}{message, time.Now()})
+	if err != nil {
+		return err
+	}
+
+	// Messages are written whole, concurrent notifications must not interleave
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,56 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type smsGatewayNotifier struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewSMSGatewayNotifier returns a notifier that sends messages as SMS through an HTTP gateway. Every message is
+// posted to url as a JSON object with the to and body fields, with token as bearer token when one is given.
+func NewSMSGatewayNotifier(url string, token string) Notifier {
+	return &smsGatewayNotifier{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *smsGatewayNotifier) Notify(ctx context.Context, message *Message) error {
+	payload, err := json.Marshal(map[string]string{
+		"to":   message.To,
+		"body": message.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("received %d code from the SMS gateway", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+type smtpNotifier struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPNotifier returns a notifier that sends messages as plain text emails through the SMTP server at addr,
+// a host and port. The server is logged in to when a username is given.
+func NewSMTPNotifier(addr string, userName string, password string, from string) Notifier {
+	notifier := &smtpNotifier{
+		addr: addr,
+		from: from,
+	}
+	if userName != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		notifier.auth = smtp.PlainAuth("", userName, password, host)
+	}
+	return notifier
+}
+
+func (n *smtpNotifier) Notify(ctx context.Context, message *Message) error {
+	// The headers are built from our own values, line breaks are still removed so none can inject a header
+	headerValue := strings.NewReplacer("\r", "", "\n", "")
+
+	var email strings.Builder
+	fmt.Fprintf(&email, "From: %s\r\n", headerValue.Replace(n.from))
+	fmt.Fprintf(&email, "To: %s\r\n", headerValue.Replace(message.To))
+	fmt.Fprintf(&email, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerValue.Replace(message.Subject)))
+	email.WriteString("MIME-Version: 1.0\r\n")
+	email.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	email.WriteString(message.Body)
+	email.WriteString("\r\n")
+
+	return smtp.SendMail(n.addr, n.auth, n.from, []string{message.To}, []byte(email.String()))
+}
@@ -0,0 +1,176 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+)
+
+// Rules a password is checked against, they name the violations reported for a password
+const (
+	RuleMinLength        = "min_length"
+	RuleMaxLength        = "max_length"
+	RuleLowercase        = "lowercase"
+	RuleUppercase        = "uppercase"
+	RuleDigit            = "digit"
+	RuleSymbol           = "symbol"
+	RuleContainsUserName = "contains_username"
+	RuleCommon           = "common_password"
+)
+
+// MaxBytes is the longest password bcrypt hashes, it ignores everything after the first 72 bytes
+const MaxBytes = 72
+
+// minUserNameLength keeps very short usernames from ruling out most passwords
+const minUserNameLength = 3
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// characterClasses are the classes a policy can require, keyed by their rule
+var characterClasses = map[string]func(rune) bool{
+	RuleLowercase: unicode.IsLower,
+	RuleUppercase: unicode.IsUpper,
+	RuleDigit:     unicode.IsDigit,
+	RuleSymbol: func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r)
+	},
+}
+
+var characterClassMessages = map[string]string{
+	RuleLowercase: "Password must contain a lowercase letter",
+	RuleUppercase: "Password must contain an uppercase letter",
+	RuleDigit:     "Password must contain a digit",
+	RuleSymbol:    "Password must contain a symbol",
+}
+
+// Violation is a rule a password breaks
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Policy is the set of rules passwords must follow
+type Policy struct {
+	MinLength int
+	// MaxLength is in bytes and never above MaxBytes
+	MaxLength int
+	// CharacterClasses are the rules of the classes every password must contain
+	CharacterClasses []string
+	RejectUserName   bool
+	denyList         map[string]bool
+}
+
+var policy = defaultPolicy()
+
+// defaultPolicy is used until SetPolicy is called, it only checks the length and the common passwords
+func defaultPolicy() *Policy {
+	p, err := NewPolicy(8, MaxBytes, nil, true, "")
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// NewPolicy returns a policy rejecting the common passwords shipped with the service and those listed in the
+// file at denyListPath, which may be empty
+func NewPolicy(minLength int, maxLength int, characterClasses []string, rejectUserName bool, denyListPath string) (*Policy, error) {
+	for _, class := range characterClasses {
+		if _, ok := characterClassMessages[class]; !ok {
+			return nil, fmt.Errorf("unknown character class %q", class)
+		}
+	}
+	if maxLength <= 0 || maxLength > MaxBytes {
+		maxLength = MaxBytes
+	}
+	if minLength > maxLength {
+		return nil, fmt.Errorf("minimum length %d is above the maximum length %d", minLength, maxLength)
+	}
+
+	denyList := map[string]bool{}
+	if err := readDenyList(strings.NewReader(commonPasswords), denyList); err != nil {
+		return nil, err
+	}
+	if denyListPath != "" {
+		file, err := os.Open(denyListPath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		if err := readDenyList(file, denyList); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Policy{
+		MinLength:        minLength,
+		MaxLength:        maxLength,
+		CharacterClasses: characterClasses,
+		RejectUserName:   rejectUserName,
+		denyList:         denyList,
+	}, nil
+}
+
+// SetPolicy sets the policy Validate checks passwords against
+func SetPolicy(p *Policy) {
+	policy = p
+}
+
+// Validate checks the password against the policy set with SetPolicy, the error lists every rule it violates
+func Validate(password string, userName string) error {
+	violations := policy.Check(password, userName)
+	if len(violations) == 0 {
+		return nil
+	}
+	return cerr.NewCustomErrorWithDetails("Password does not meet the password policy", cerr.InvalidRequestErrorCode, violations)
+}
+
+// Check returns the rules of the policy the password of the user violates
+func (p *Policy) Check(password string, userName string) []Violation {
+	violations := []Violation{}
+
+	if len([]rune(password)) < p.MinLength {
+		violations = append(violations, Violation{Rule: RuleMinLength, Message: fmt.Sprintf("Password must be at least %d characters long", p.MinLength)})
+	}
+	if len(password) > p.MaxLength {
+		violations = append(violations, Violation{Rule: RuleMaxLength, Message: fmt.Sprintf("Password must be at most %d bytes long", p.MaxLength)})
+	}
+
+	for _, class := range p.CharacterClasses {
+		if strings.IndexFunc(password, characterClasses[class]) < 0 {
+			violations = append(violations, Violation{Rule: class, Message: characterClassMessages[class]})
+		}
+	}
+
+	lowered := strings.ToLower(password)
+	userName = strings.ToLower(strings.TrimSpace(userName))
+	if p.RejectUserName && len([]rune(userName)) >= minUserNameLength && strings.Contains(lowered, userName) {
+		violations = append(violations, Violation{Rule: RuleContainsUserName, Message: "Password must not contain the username"})
+	}
+
+	if p.denyList[lowered] {
+		violations = append(violations, Violation{Rule: RuleCommon, Message: "Password is too common"})
+	}
+
+	return violations
+}
+
+// readDenyList adds the passwords listed in r to denyList, blank lines and lines starting with # are skipped
+func readDenyList(r io.Reader, denyList map[string]bool) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		denyList[strings.ToLower(line)] = true
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,161 @@
+package password
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/cerr"
+)
+
+func TestCheck(t *testing.T) {
+	strict, err := NewPolicy(10, 20, []string{RuleLowercase, RuleUppercase, RuleDigit, RuleSymbol}, true, "")
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		userName string
+		rule     string
+		violates bool
+	}{
+		{name: "min length met", password: "Abcdefg1!x", rule: RuleMinLength, violates: false},
+		{name: "min length missed", password: "Abcdef1!x", rule: RuleMinLength, violates: true},
+		{name: "min length counts characters", password: "Äbcdefg1!ü", rule: RuleMinLength, violates: false},
+		{name: "max length met", password: "Abcdefg1!" + strings.Repeat("x", 11), rule: RuleMaxLength, violates: false},
+		{name: "max length exceeded", password: "Abcdefg1!" + strings.Repeat("x", 12), rule: RuleMaxLength, violates: true},
+		{name: "max length counts bytes", password: "Abcdefg1!" + strings.Repeat("ü", 6), rule: RuleMaxLength, violates: true},
+		{name: "lowercase present", password: "ABCDEFg1!X", rule: RuleLowercase, violates: false},
+		{name: "lowercase missing", password: "ABCDEFG1!X", rule: RuleLowercase, violates: true},
+		{name: "uppercase present", password: "abcdefG1!x", rule: RuleUppercase, violates: false},
+		{name: "uppercase missing", password: "abcdefg1!x", rule: RuleUppercase, violates: true},
+		{name: "digit present", password: "Abcdefg1!x", rule: RuleDigit, violates: false},
+		{name: "digit missing", password: "Abcdefgh!x", rule: RuleDigit, violates: true},
+		{name: "symbol present", password: "Abcdefg1 x", rule: RuleSymbol, violates: false},
+		{name: "symbol missing", password: "Abcdefg12x", rule: RuleSymbol, violates: true},
+		{name: "username absent", password: "Abcdefg1!x", userName: "alice", rule: RuleContainsUserName, violates: false},
+		{name: "username contained", password: "xAlice123!", userName: "alice", rule: RuleContainsUserName, violates: true},
+		{name: "short username ignored", password: "Abcdefg1!x", userName: "ab", rule: RuleContainsUserName, violates: false},
+		{name: "uncommon password", password: "Tr0ub4dor&3", rule: RuleCommon, violates: false},
+		{name: "common password", password: "Qwerty123", rule: RuleCommon, violates: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := strict.Check(tt.password, tt.userName)
+			if got := hasRule(violations, tt.rule); got != tt.violates {
+				t.Errorf("Check(%q, %q) violates %s = %v, want %v, violations %v", tt.password, tt.userName, tt.rule, got, tt.violates, violations)
+			}
+		})
+	}
+}
+
+func TestCheckWithoutUserNameRule(t *testing.T) {
+	p, err := NewPolicy(8, MaxBytes, nil, false, "")
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+
+	if violations := p.Check("alice-secret-1", "alice"); hasRule(violations, RuleContainsUserName) {
+		t.Errorf("Check reported %s with the rule turned off", RuleContainsUserName)
+	}
+}
+
+func TestCheckDenyListFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deny.txt")
+	if err := os.WriteFile(path, []byte("# company words\n\nAcmeRocks2024\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	p, err := NewPolicy(8, MaxBytes, nil, false, path)
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+
+	tests := []struct {
+		password string
+		violates bool
+	}{
+		{password: "acmerocks2024", violates: true},
+		{password: "password", violates: true},
+		{password: "# company words", violates: false},
+		{password: "AcmeRocks2025", violates: false},
+	}
+
+	for _, tt := range tests {
+		if got := hasRule(p.Check(tt.password, ""), RuleCommon); got != tt.violates {
+			t.Errorf("Check(%q) violates %s = %v, want %v", tt.password, RuleCommon, got, tt.violates)
+		}
+	}
+}
+
+func TestNewPolicy(t *testing.T) {
+	tests := []struct {
+		name             string
+		minLength        int
+		maxLength        int
+		characterClasses []string
+		denyListPath     string
+		wantMaxLength    int
+		wantErr          bool
+	}{
+		{name: "valid", minLength: 8, maxLength: 64, characterClasses: []string{RuleDigit}, wantMaxLength: 64},
+		{name: "max length capped at bcrypt limit", minLength: 8, maxLength: 100, wantMaxLength: MaxBytes},
+		{name: "unset max length", minLength: 8, maxLength: 0, wantMaxLength: MaxBytes},
+		{name: "unknown character class", minLength: 8, maxLength: 64, characterClasses: []string{"emoji"}, wantErr: true},
+		{name: "min length above max length", minLength: 65, maxLength: 64, wantErr: true},
+		{name: "missing deny list", minLength: 8, maxLength: 64, denyListPath: "does-not-exist.txt", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPolicy(tt.minLength, tt.maxLength, tt.characterClasses, true, tt.denyListPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewPolicy error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && p.MaxLength != tt.wantMaxLength {
+				t.Errorf("NewPolicy MaxLength = %d, want %d", p.MaxLength, tt.wantMaxLength)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	p, err := NewPolicy(10, MaxBytes, []string{RuleDigit}, true, "")
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+	previous := policy
+	SetPolicy(p)
+	t.Cleanup(func() { SetPolicy(previous) })
+
+	if err := Validate("correct horse 9", "alice"); err != nil {
+		t.Errorf("Validate of a valid password = %v, want nil", err)
+	}
+
+	err = Validate("alice", "alice")
+	if err == nil {
+		t.Fatal("Validate of an invalid password = nil, want an error")
+	}
+	if code := cerr.GetErrorCode(err); code != cerr.InvalidRequestErrorCode {
+		t.Errorf("Validate error code = %d, want %d", code, cerr.InvalidRequestErrorCode)
+	}
+	violations, _ := cerr.GetErrorDetails(err).([]Violation)
+	for _, rule := range []string{RuleMinLength, RuleDigit, RuleContainsUserName} {
+		if !hasRule(violations, rule) {
+			t.Errorf("Validate violations %v miss %s", violations, rule)
+		}
+	}
+}
+
+// hasRule reports whether the violations include the rule
+func hasRule(violations []Violation, rule string) bool {
+	for _, violation := range violations {
+		if violation.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package tenant
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/satyamvatstyagi/UserManagementService/pkg/common/consts"
+)
+
+// Header is the request header naming the tenant
+const Header = "X-Tenant-ID"
+
+// idPattern keeps tenant ids usable as a subdomain and as a path segment
+var idPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+var defaultID = "default"
+
+// SetDefault sets the tenant used when a request names none
+func SetDefault(id string) {
+	defaultID = id
+}
+
+// Default returns the tenant used when a request names none
+func Default() string {
+	return defaultID
+}
+
+// Valid reports whether id is a well formed tenant id
+func Valid(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// NewContext returns a copy of ctx carrying the tenant id
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, consts.TenantContext, id)
+}
+
+// FromContext returns the tenant id stored in ctx, if any
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(consts.TenantContext).(string)
+	return id, ok
+}
+
+// ID returns the tenant id stored in ctx, falling back to the default tenant for work not started by a request
+func ID(ctx context.Context) string {
+	if id, ok := FromContext(ctx); ok {
+		return id
+	}
+	return defaultID
+}
@@ -0,0 +1,90 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors, "12345678901234567890" base32 encoded
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateRFC6238Vectors(t *testing.T) {
+	// The RFC lists 8 digit codes, the 6 digit codes are their last 6 digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+		{unix: 20000000000, code: "353130"},
+	}
+
+	for _, tt := range tests {
+		now := time.Unix(tt.unix, 0)
+		step, ok := Validate(rfcSecret, tt.code, now)
+		if !ok {
+			t.Errorf("Validate(%q) at %d = false, want true", tt.code, tt.unix)
+			continue
+		}
+		if want := tt.unix / 30; step != want {
+			t.Errorf("Validate(%q) at %d step = %d, want %d", tt.code, tt.unix, step, want)
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	// 081804 is the code of step 37037036, valid from 1111111080 to 1111111109
+	tests := []struct {
+		name string
+		unix int64
+		ok   bool
+	}{
+		{name: "two steps early", unix: 1111111049, ok: false},
+		{name: "one step early", unix: 1111111050, ok: true},
+		{name: "current step", unix: 1111111095, ok: true},
+		{name: "one step late", unix: 1111111139, ok: true},
+		{name: "two steps late", unix: 1111111140, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, "081804", time.Unix(tt.unix, 0))
+			if ok != tt.ok {
+				t.Fatalf("Validate at %d = %v, want %v", tt.unix, ok, tt.ok)
+			}
+			// Replays are caught by the step, so it must be the step of the code and not of the time
+			if ok && step != 37037036 {
+				t.Errorf("Validate at %d step = %d, want 37037036", tt.unix, step)
+			}
+		})
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	now := time.Unix(59, 0)
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		ok     bool
+	}{
+		{name: "valid code", secret: rfcSecret, code: "287082", ok: true},
+		{name: "spaces are ignored", secret: rfcSecret, code: "287 082", ok: true},
+		{name: "lower case secret", secret: "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", code: "287082", ok: true},
+		{name: "wrong code", secret: rfcSecret, code: "287083", ok: false},
+		{name: "too short", secret: rfcSecret, code: "28708", ok: false},
+		{name: "too long", secret: rfcSecret, code: "2870820", ok: false},
+		{name: "invalid secret", secret: "not base32!", code: "287082", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := Validate(tt.secret, tt.code, now); ok != tt.ok {
+				t.Errorf("Validate(%q, %q) = %v, want %v", tt.secret, tt.code, ok, tt.ok)
+			}
+		})
+	}
+}
@@ -6,3 +6,21 @@ func Fibonacci(n int) int {
 	}
 	return Fibonacci(n-1) + Fibonacci(n-2)
 }
+
+// Contains reports whether value is one of values
+func Contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// StringValue returns the string the pointer points to, or an empty string for nil
+func StringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}